@@ -0,0 +1,139 @@
+package rsmt2d
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// tuneKey identifies the squares sharing an auto-tuned parallelism.
+type tuneKey struct {
+	codec     string
+	width     uint
+	chunkSize uint
+}
+
+// tuning is the parallelism picked for a tuneKey.
+type tuning struct {
+	coding  int
+	hashing int
+}
+
+// tuneRuns is the number of times each candidate parallelism is timed. The
+// fastest run is kept, which filters out runs disturbed by other work.
+const tuneRuns = 3
+
+// tunings caches the parallelism picked by autoParallelism for the lifetime
+// of the process.
+var tunings = struct {
+	sync.Mutex
+	m        map[tuneKey]tuning
+	inflight map[tuneKey]*tuneCall
+}{m: make(map[tuneKey]tuning), inflight: make(map[tuneKey]*tuneCall)}
+
+// tuneCall is an in-flight benchmark of a tuneKey.
+type tuneCall struct {
+	done chan struct{}
+	t    tuning
+	err  error
+}
+
+// WithAutoParallelism picks the coding and hashing parallelism by timing the
+// codec and tree on a square of the same width and chunk size, the first
+// time such a square is extended. The result is cached for the lifetime of
+// the process. Parallelism set explicitly with WithCodingParallelism or
+// WithHashingParallelism takes precedence. The codec must be safe for
+// concurrent use.
+func WithAutoParallelism() ExtendOption {
+	return func(cfg *extendConfig) {
+		cfg.autoParallelism = true
+	}
+}
+
+// autoParallelism returns the cached parallelism for squares of original
+// width k and the given chunk size, benchmarking them first if needed.
+// Concurrent callers for the same key wait for a single benchmark.
+func autoParallelism(codec Codec, k uint, chunkSize uint, treeCreatorFn TreeConstructorFn) (tuning, error) {
+	key := tuneKey{codec: fmt.Sprintf("%T", codec), width: k, chunkSize: chunkSize}
+
+	tunings.Lock()
+	if t, ok := tunings.m[key]; ok {
+		tunings.Unlock()
+		return t, nil
+	}
+	if call, ok := tunings.inflight[key]; ok {
+		tunings.Unlock()
+		<-call.done
+		return call.t, call.err
+	}
+	call := &tuneCall{done: make(chan struct{})}
+	tunings.inflight[key] = call
+	tunings.Unlock()
+
+	call.t, call.err = benchmarkParallelism(codec, k, chunkSize, treeCreatorFn)
+
+	tunings.Lock()
+	delete(tunings.inflight, key)
+	if call.err == nil {
+		tunings.m[key] = call.t
+	}
+	tunings.Unlock()
+	close(call.done)
+
+	return call.t, call.err
+}
+
+// benchmarkParallelism times extending and hashing a random square of
+// original width k for each candidate parallelism, keeping the fastest run of
+// each, and returns the fastest candidates.
+func benchmarkParallelism(codec Codec, k uint, chunkSize uint, treeCreatorFn TreeConstructorFn) (tuning, error) {
+	rnd := rand.New(rand.NewSource(int64(k)))
+	data := make([][]byte, k*k)
+	for i := range data {
+		data[i] = make([]byte, chunkSize)
+		rnd.Read(data[i])
+	}
+
+	best := tuning{coding: 1, hashing: 1}
+	var bestCoding, bestHashing time.Duration
+	for _, n := range parallelismCandidates(runtime.GOMAXPROCS(0)) {
+		var coding, hashing time.Duration
+		for run := 0; run < tuneRuns; run++ {
+			start := time.Now()
+			eds, err := ComputeExtendedDataSquare(data, codec, treeCreatorFn, WithCodingParallelism(n))
+			if err != nil {
+				return tuning{}, err
+			}
+			if d := time.Since(start); run == 0 || d < coding {
+				coding = d
+			}
+
+			eds.hashingParallelism = n
+			start = time.Now()
+			eds.computeRoots()
+			if d := time.Since(start); run == 0 || d < hashing {
+				hashing = d
+			}
+		}
+
+		if bestCoding == 0 || coding < bestCoding {
+			best.coding, bestCoding = n, coding
+		}
+		if bestHashing == 0 || hashing < bestHashing {
+			best.hashing, bestHashing = n, hashing
+		}
+	}
+
+	return best, nil
+}
+
+// parallelismCandidates returns the powers of two below max, followed by max.
+func parallelismCandidates(max int) []int {
+	var candidates []int
+	for n := 1; n < max; n *= 2 {
+		candidates = append(candidates, n)
+	}
+	return append(candidates, max)
+}
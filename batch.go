@@ -0,0 +1,62 @@
+package rsmt2d
+
+import (
+	"runtime"
+	"sync"
+)
+
+// RepairJob holds the inputs of a single RepairExtendedDataSquare call.
+// Jobs of a batch may share codec instances.
+type RepairJob struct {
+	RowRoots      [][]byte
+	ColRoots      [][]byte
+	Data          [][]byte
+	Codec         Codec
+	TreeCreatorFn TreeConstructorFn
+	Options       []RepairOption
+}
+
+// RepairResult holds the outcome of a RepairJob.
+type RepairResult struct {
+	EDS *ExtendedDataSquare
+	Err error
+}
+
+// RepairBatch repairs many squares on a shared pool of parallelism workers,
+// for example when catching up over many blocks. Results are returned in the
+// order of the jobs. A non-positive parallelism uses GOMAXPROCS workers.
+func RepairBatch(jobs []RepairJob, parallelism int) []RepairResult {
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]RepairResult, len(jobs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				job := jobs[i]
+				eds, err := RepairExtendedDataSquare(
+					job.RowRoots,
+					job.ColRoots,
+					job.Data,
+					job.Codec,
+					job.TreeCreatorFn,
+					job.Options...,
+				)
+				results[i] = RepairResult{EDS: eds, Err: err}
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
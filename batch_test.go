@@ -0,0 +1,46 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairBatch(t *testing.T) {
+	codec := NewRSGF8Codec()
+
+	var originals []*ExtendedDataSquare
+	var jobs []RepairJob
+	for i := 0; i < 8; i++ {
+		eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+		if err != nil {
+			panic(err)
+		}
+		flattened := eds.flattened()
+		for j := i % 3; j < len(flattened); j += 3 {
+			flattened[j] = nil
+		}
+		originals = append(originals, eds)
+		jobs = append(jobs, RepairJob{
+			RowRoots:      eds.getRowRoots(),
+			ColRoots:      eds.getColRoots(),
+			Data:          flattened,
+			Codec:         codec,
+			TreeCreatorFn: NewDefaultTree,
+		})
+	}
+	// An unrepairable job does not affect the others.
+	jobs[3].Data = make([][]byte, 64)
+
+	results := RepairBatch(jobs, 3)
+	assert.Len(t, results, len(jobs))
+	for i, res := range results {
+		if i == 3 {
+			assert.ErrorIs(t, res.Err, ErrUnrepairableDataSquare)
+			continue
+		}
+		if assert.NoError(t, res.Err) {
+			assert.Equal(t, originals[i].flattened(), res.EDS.flattened())
+		}
+	}
+}
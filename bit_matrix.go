@@ -29,6 +29,17 @@ func (bm *bitMatrix) Set(row, col int) {
 	bm.mask[idx/64] |= uint64(1) << uint(idx%64)
 }
 
+func (bm *bitMatrix) Clear(row, col int) {
+	assertValidIndices(row, col, bm.squareSize)
+	idx := row*bm.squareSize + col
+	bm.mask[idx/64] &^= uint64(1) << uint(idx%64)
+}
+
+// copy returns an independent copy of the matrix.
+func (bm bitMatrix) copy() bitMatrix {
+	return bitMatrix{mask: append([]uint64(nil), bm.mask...), squareSize: bm.squareSize}
+}
+
 func (bm bitMatrix) ColIsOne(c int) bool {
 	for r := 0; r < bm.squareSize; r++ {
 		if !bm.Get(r, c) {
@@ -0,0 +1,96 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Builder produces and repairs squares with a codec and tree constructor
+// that were checked to work together, so that squares are never produced
+// with one pair and repaired with another.
+type Builder struct {
+	codec         Codec
+	treeCreatorFn TreeConstructorFn
+	opts          []ExtendOption
+}
+
+// NewBuilder returns a Builder extending squares with codec and the trees
+// returned by treeCreatorFn, passing opts to ComputeExtendedDataSquare. It
+// checks that the tree constructor returns trees with deterministic roots,
+// and that a square extended with the pair can be repaired with it, after
+// the health check of the codec.
+func NewBuilder(codec Codec, treeCreatorFn TreeConstructorFn, opts ...ExtendOption) (*Builder, error) {
+	if codec == nil {
+		return nil, errors.New("builder needs a codec")
+	}
+	if treeCreatorFn == nil {
+		return nil, errors.New("builder needs a tree constructor")
+	}
+	if err := codec.HealthCheck(); err != nil {
+		return nil, err
+	}
+	b := &Builder{codec: codec, treeCreatorFn: treeCreatorFn, opts: opts}
+	if err := b.selfTest(); err != nil {
+		return nil, fmt.Errorf("codec %T and tree constructor are incompatible: %w", codec, err)
+	}
+	return b, nil
+}
+
+// selfTest extends a 2x2 square and repairs it from a quarter of its shares.
+func (b *Builder) selfTest() error {
+	chunkSize := 64
+	if limits, ok := b.codec.(ChunkSizeLimits); ok && limits.ChunkSizeMultiple() > chunkSize {
+		chunkSize = limits.ChunkSizeMultiple()
+	}
+	data := make([][]byte, 4)
+	for i := range data {
+		data[i] = bytes.Repeat([]byte{byte(i + 1)}, chunkSize)
+	}
+	eds, err := ComputeExtendedDataSquare(data, b.codec, b.treeCreatorFn)
+	if err != nil {
+		return err
+	}
+	rowRoots, colRoots := eds.getRowRoots(), eds.getColRoots()
+	for i := uint(0); i < eds.width; i++ {
+		if !bytes.Equal(rowRoots[i], eds.computeRowRoot(i)) {
+			return errors.New("tree roots are not deterministic")
+		}
+	}
+
+	shares := make([][]byte, eds.width*eds.width)
+	for r := uint(0); r < 2; r++ {
+		for c := uint(0); c < 2; c++ {
+			shares[r*eds.width+c] = eds.getCell(r, c)
+		}
+	}
+	repaired, err := RepairExtendedDataSquare(rowRoots, colRoots, shares, b.codec, b.treeCreatorFn)
+	if err != nil {
+		return err
+	}
+	if !equalChunks(repaired.flattened(), eds.flattened()) {
+		return errors.New("repaired square differs from the extended one")
+	}
+	return nil
+}
+
+// Codec returns the codec of the builder.
+func (b *Builder) Codec() Codec {
+	return b.codec
+}
+
+// ImportODS extends an original data square, like ComputeExtendedDataSquare.
+func (b *Builder) ImportODS(data [][]byte) (*ExtendedDataSquare, error) {
+	return ComputeExtendedDataSquare(data, b.codec, b.treeCreatorFn, b.opts...)
+}
+
+// ImportEDS imports an extended data square, like ImportExtendedDataSquare.
+func (b *Builder) ImportEDS(data [][]byte, opts ...ImportOption) (*ExtendedDataSquare, error) {
+	return ImportExtendedDataSquare(data, b.codec, b.treeCreatorFn, opts...)
+}
+
+// Repair repairs an incomplete extended data square, like
+// RepairExtendedDataSquare.
+func (b *Builder) Repair(rowRoots [][]byte, colRoots [][]byte, data [][]byte, opts ...RepairOption) (*ExtendedDataSquare, error) {
+	return RepairExtendedDataSquare(rowRoots, colRoots, data, b.codec, b.treeCreatorFn, opts...)
+}
@@ -0,0 +1,44 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// randomRootTree returns a different root for every tree.
+type randomRootTree struct {
+	Tree
+}
+
+func (t randomRootTree) Root() []byte {
+	root := make([]byte, 32)
+	testRand.Read(root)
+	return root
+}
+
+func TestBuilder(t *testing.T) {
+	b, err := NewBuilder(NewRSGF8Codec(), NewDefaultTree)
+	if !assert.NoError(t, err) {
+		return
+	}
+	original, err := b.ImportODS(genRandDS(2))
+	assert.NoError(t, err)
+
+	imported, err := b.ImportEDS(original.flattened())
+	assert.NoError(t, err)
+	assert.Equal(t, original.RowRoots(), imported.RowRoots())
+
+	data := original.flattened()
+	data[0], data[5] = nil, nil
+	repaired, err := b.Repair(original.RowRoots(), original.ColRoots(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, original.flattened(), repaired.flattened())
+
+	_, err = NewBuilder(NewRSGF8Codec(), func() Tree { return randomRootTree{NewDefaultTree()} })
+	assert.Error(t, err)
+	_, err = NewBuilder(nil, NewDefaultTree)
+	assert.Error(t, err)
+	_, err = NewBuilder(NewRSGF8Codec(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,504 @@
+package rsmt2d
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CBOR encodings of squares, roots and proofs, in the canonical form required
+// by dag-cbor: integers and lengths use the shortest encoding, and maps have
+// text keys sorted by length and then bytewise. Structures are encoded as maps
+// keyed by the JSON names of their fields, and axes as integers, 0 for rows
+// and 1 for columns. Proofs carry their format version, like their JSON
+// encoding. Decoding rejects any input that is not in canonical form.
+
+// CBOR major types.
+const (
+	cborUint  byte = 0
+	cborBytes byte = 2
+	cborText  byte = 3
+	cborArray byte = 4
+	cborMap   byte = 5
+)
+
+// appendCBORHead appends the head of a data item of the given major type.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xff:
+		return append(b, major|24, byte(n))
+	case n <= 0xffff:
+		return append(b, major|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		b = append(b, major|26)
+		return append(b, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		b = append(b, major|27)
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], n)
+		return append(b, buf[:]...)
+	}
+}
+
+func cborUintValue(n uint64) []byte {
+	return appendCBORHead(nil, cborUint, n)
+}
+
+func cborBytesValue(data []byte) []byte {
+	return append(appendCBORHead(nil, cborBytes, uint64(len(data))), data...)
+}
+
+func cborTextValue(s string) []byte {
+	return append(appendCBORHead(nil, cborText, uint64(len(s))), s...)
+}
+
+func cborBytesArray(items [][]byte) []byte {
+	b := appendCBORHead(nil, cborArray, uint64(len(items)))
+	for _, item := range items {
+		b = append(b, cborBytesValue(item)...)
+	}
+	return b
+}
+
+// cborEntry is a key and encoded value of a CBOR map.
+type cborEntry struct {
+	key   string
+	value []byte
+}
+
+// cborKeyLess reports whether key a sorts before key b in canonical order.
+func cborKeyLess(a string, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// cborMapValue encodes a map with text keys in canonical order.
+func cborMapValue(entries ...cborEntry) []byte {
+	sort.Slice(entries, func(i, j int) bool {
+		return cborKeyLess(entries[i].key, entries[j].key)
+	})
+	b := appendCBORHead(nil, cborMap, uint64(len(entries)))
+	for _, e := range entries {
+		b = append(b, cborTextValue(e.key)...)
+		b = append(b, e.value...)
+	}
+	return b
+}
+
+// cborDecoder reads canonical CBOR. The first failure is recorded in err and
+// all subsequent reads return zero values.
+type cborDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *cborDecoder) fail() {
+	if d.err == nil {
+		d.err = errMalformedEncoding
+	}
+}
+
+// head reads the head of a data item of the given major type, rejecting
+// non-minimal and indefinite length encodings.
+func (d *cborDecoder) head(major byte) uint64 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) == 0 || d.buf[0]>>5 != major {
+		d.fail()
+		return 0
+	}
+	info := d.buf[0] & 0x1f
+	d.buf = d.buf[1:]
+	if info < 24 {
+		return uint64(info)
+	}
+	if info > 27 {
+		d.fail()
+		return 0
+	}
+	size := 1 << (info - 24)
+	if len(d.buf) < size {
+		d.fail()
+		return 0
+	}
+	var n uint64
+	for _, c := range d.buf[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.buf = d.buf[size:]
+	if (size == 1 && n < 24) || (size > 1 && n>>(4*size) == 0) {
+		d.fail()
+		return 0
+	}
+	return n
+}
+
+// length reads the head of a collection or string, rejecting lengths that
+// cannot possibly fit in the remaining input.
+func (d *cborDecoder) length(major byte) int {
+	n := d.head(major)
+	if n > uint64(len(d.buf)) {
+		d.fail()
+		return 0
+	}
+	return int(n)
+}
+
+func (d *cborDecoder) uint() uint64 {
+	return d.head(cborUint)
+}
+
+func (d *cborDecoder) bytes() []byte {
+	n := d.length(cborBytes)
+	if d.err != nil {
+		return nil
+	}
+	data := make([]byte, n)
+	copy(data, d.buf[:n])
+	d.buf = d.buf[n:]
+	return data
+}
+
+func (d *cborDecoder) text() string {
+	n := d.length(cborText)
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+func (d *cborDecoder) bytesArray() [][]byte {
+	items := make([][]byte, d.length(cborArray))
+	for i := range items {
+		items[i] = d.bytes()
+	}
+	return items
+}
+
+func (d *cborDecoder) axis() Axis {
+	switch v := d.uint(); v {
+	case uint64(RowAxis), uint64(ColAxis):
+		return Axis(v)
+	default:
+		d.fail()
+		return RowAxis
+	}
+}
+
+// fields reads a map, calling field with each key, in canonical order, to
+// decode its value. field returns false for unknown keys. Every key in
+// required must be present.
+func (d *cborDecoder) fields(required []string, field func(key string) bool) {
+	n := d.length(cborMap)
+	prev := ""
+	seen := make(map[string]bool, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		key := d.text()
+		if d.err != nil {
+			return
+		}
+		if i > 0 && !cborKeyLess(prev, key) {
+			d.err = fmt.Errorf("%w: map keys out of canonical order", errMalformedEncoding)
+			return
+		}
+		if !field(key) {
+			d.err = fmt.Errorf("%w: unknown key %q", errMalformedEncoding, key)
+			return
+		}
+		prev = key
+		seen[key] = true
+	}
+	for _, key := range required {
+		if d.err == nil && !seen[key] {
+			d.err = fmt.Errorf("%w: missing key %q", errMalformedEncoding, key)
+		}
+	}
+}
+
+// finish returns the first decoding error, or an error if input remains.
+func (d *cborDecoder) finish() error {
+	if d.err == nil && len(d.buf) != 0 {
+		d.err = errMalformedEncoding
+	}
+	return d.err
+}
+
+func cborProof(p Proof) []byte {
+	return cborMapValue(
+		cborEntry{"set", cborBytesArray(p.Set)},
+		cborEntry{"index", cborUintValue(p.Index)},
+		cborEntry{"num_leaves", cborUintValue(p.NumLeaves)},
+	)
+}
+
+func (d *cborDecoder) proof() Proof {
+	var p Proof
+	d.fields([]string{"set", "index", "num_leaves"}, func(key string) bool {
+		switch key {
+		case "set":
+			p.Set = d.bytesArray()
+		case "index":
+			p.Index = d.uint()
+		case "num_leaves":
+			p.NumLeaves = d.uint()
+		default:
+			return false
+		}
+		return true
+	})
+	return p
+}
+
+func (d *cborDecoder) coordinate() Coordinate {
+	var c Coordinate
+	d.fields([]string{"row", "col"}, func(key string) bool {
+		switch key {
+		case "row":
+			c.Row = uint(d.uint())
+		case "col":
+			c.Col = uint(d.uint())
+		default:
+			return false
+		}
+		return true
+	})
+	return c
+}
+
+// version reads a proof format version, failing if it is not supported.
+func (d *cborDecoder) version() {
+	v := d.uint()
+	if d.err == nil && !FormatProof.IsCompatible(uint(v)) {
+		d.err = fmt.Errorf("%w: %d", ErrUnsupportedProofVersion, v)
+	}
+}
+
+func cborCellProof(p *CellProof) []byte {
+	return cborMapValue(
+		cborEntry{"version", cborUintValue(ProofFormatVersion)},
+		cborEntry{"coord", cborMapValue(
+			cborEntry{"row", cborUintValue(uint64(p.Coord.Row))},
+			cborEntry{"col", cborUintValue(uint64(p.Coord.Col))},
+		)},
+		cborEntry{"axis", cborUintValue(uint64(p.Axis))},
+		cborEntry{"proof", cborProof(p.Proof)},
+	)
+}
+
+func (d *cborDecoder) cellProof() CellProof {
+	var p CellProof
+	d.fields([]string{"version", "coord", "axis", "proof"}, func(key string) bool {
+		switch key {
+		case "version":
+			d.version()
+		case "coord":
+			p.Coord = d.coordinate()
+		case "axis":
+			p.Axis = d.axis()
+		case "proof":
+			p.Proof = d.proof()
+		default:
+			return false
+		}
+		return true
+	})
+	return p
+}
+
+// MarshalCBOR encodes the proof in canonical CBOR.
+func (p *CellProof) MarshalCBOR() ([]byte, error) {
+	return cborCellProof(p), nil
+}
+
+// UnmarshalCBOR decodes a proof encoded with MarshalCBOR.
+func (p *CellProof) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{buf: data}
+	proof := d.cellProof()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalCBOR encodes the proof in canonical CBOR.
+func (p *AxisRootProof) MarshalCBOR() ([]byte, error) {
+	return cborMapValue(
+		cborEntry{"version", cborUintValue(ProofFormatVersion)},
+		cborEntry{"axis", cborUintValue(uint64(p.Axis))},
+		cborEntry{"index", cborUintValue(uint64(p.Index))},
+		cborEntry{"root", cborBytesValue(p.Root)},
+		cborEntry{"proof", cborProof(p.Proof)},
+	), nil
+}
+
+// UnmarshalCBOR decodes a proof encoded with MarshalCBOR.
+func (p *AxisRootProof) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{buf: data}
+	var proof AxisRootProof
+	d.fields([]string{"version", "axis", "index", "root", "proof"}, func(key string) bool {
+		switch key {
+		case "version":
+			d.version()
+		case "axis":
+			proof.Axis = d.axis()
+		case "index":
+			proof.Index = uint(d.uint())
+		case "root":
+			proof.Root = d.bytes()
+		case "proof":
+			proof.Proof = d.proof()
+		default:
+			return false
+		}
+		return true
+	})
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalCBOR encodes the proof in canonical CBOR.
+func (p *BadEncodingProof) MarshalCBOR() ([]byte, error) {
+	shares := appendCBORHead(nil, cborArray, uint64(len(p.Shares)))
+	for i := range p.Shares {
+		shares = append(shares, cborCellProof(&p.Shares[i])...)
+	}
+	return cborMapValue(
+		cborEntry{"version", cborUintValue(ProofFormatVersion)},
+		cborEntry{"axis", cborUintValue(uint64(p.Axis))},
+		cborEntry{"index", cborUintValue(uint64(p.Index))},
+		cborEntry{"shares", shares},
+	), nil
+}
+
+// UnmarshalCBOR decodes a proof encoded with MarshalCBOR.
+func (p *BadEncodingProof) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{buf: data}
+	var proof BadEncodingProof
+	d.fields([]string{"version", "axis", "index", "shares"}, func(key string) bool {
+		switch key {
+		case "version":
+			d.version()
+		case "axis":
+			proof.Axis = d.axis()
+		case "index":
+			proof.Index = uint(d.uint())
+		case "shares":
+			proof.Shares = make([]CellProof, d.length(cborArray))
+			for i := range proof.Shares {
+				proof.Shares[i] = d.cellProof()
+			}
+		default:
+			return false
+		}
+		return true
+	})
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalCBOR encodes the roots in canonical CBOR.
+func (r *SquareRoots) MarshalCBOR() ([]byte, error) {
+	return cborMapValue(
+		cborEntry{"width", cborUintValue(uint64(r.Width))},
+		cborEntry{"codec", cborTextValue(r.Codec)},
+		cborEntry{"row_roots", cborBytesArray(r.RowRoots)},
+		cborEntry{"col_roots", cborBytesArray(r.ColRoots)},
+		cborEntry{"data_root", cborBytesValue(r.DataRoot)},
+	), nil
+}
+
+// UnmarshalCBOR decodes roots encoded with MarshalCBOR.
+func (r *SquareRoots) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{buf: data}
+	var roots SquareRoots
+	d.fields([]string{"width", "codec", "row_roots", "col_roots", "data_root"}, func(key string) bool {
+		switch key {
+		case "width":
+			roots.Width = uint(d.uint())
+		case "codec":
+			roots.Codec = d.text()
+		case "row_roots":
+			roots.RowRoots = d.bytesArray()
+		case "col_roots":
+			roots.ColRoots = d.bytesArray()
+		case "data_root":
+			roots.DataRoot = d.bytes()
+		default:
+			return false
+		}
+		return true
+	})
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*r = roots
+	return nil
+}
+
+// MarshalCBOR encodes the square in canonical CBOR, as a map holding the
+// name of its codec, its width and its shares in row-major order.
+func (eds *ExtendedDataSquare) MarshalCBOR() ([]byte, error) {
+	if eds.codec == nil {
+		return nil, errors.New("square has no codec")
+	}
+	name, err := codecName(eds.codec)
+	if err != nil {
+		return nil, err
+	}
+	return cborMapValue(
+		cborEntry{"codec", cborTextValue(name)},
+		cborEntry{"width", cborUintValue(uint64(eds.width))},
+		cborEntry{"shares", cborBytesArray(eds.flattened())},
+	), nil
+}
+
+// UnmarshalCBORSquare decodes a square encoded with MarshalCBOR, importing it
+// with the codec it names.
+func UnmarshalCBORSquare(data []byte, treeCreatorFn TreeConstructorFn) (*ExtendedDataSquare, error) {
+	d := &cborDecoder{buf: data}
+	var (
+		name   string
+		width  uint64
+		shares [][]byte
+	)
+	d.fields([]string{"codec", "width", "shares"}, func(key string) bool {
+		switch key {
+		case "codec":
+			name = d.text()
+		case "width":
+			width = d.uint()
+		case "shares":
+			shares = d.bytesArray()
+		default:
+			return false
+		}
+		return true
+	})
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	if uint64(len(shares)) != width*width {
+		return nil, fmt.Errorf("square of width %d has %d shares", width, len(shares))
+	}
+	codec, err := CodecByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return ImportExtendedDataSquare(shares, codec, treeCreatorFn)
+}
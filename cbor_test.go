@@ -0,0 +1,103 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cborCodec interface {
+	MarshalCBOR() ([]byte, error)
+	UnmarshalCBOR(data []byte) error
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	cellProof, err := eds.ProveCellOnAxis(Coordinate{Row: 2, Col: 5}, ColAxis)
+	assert.NoError(t, err)
+	rootProof, err := eds.ProveAxisRoot(ColAxis, 3)
+	assert.NoError(t, err)
+	bep, err := NewBadEncodingProof(eds, RowAxis, 1, []uint{0, 2, 4, 6})
+	assert.NoError(t, err)
+	roots, err := eds.Roots()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		value   cborCodec
+		decoded cborCodec
+	}{
+		{"cell", &cellProof, &CellProof{}},
+		{"axis root", &rootProof, &AxisRootProof{}},
+		{"bad encoding", bep, &BadEncodingProof{}},
+		{"roots", roots, &SquareRoots{}},
+	}
+	for _, tt := range tests {
+		data, err := tt.value.MarshalCBOR()
+		assert.NoError(t, err)
+		assert.NoError(t, tt.decoded.UnmarshalCBOR(data), tt.name)
+		assert.Equal(t, tt.value, tt.decoded, tt.name)
+		assert.Error(t, tt.decoded.UnmarshalCBOR(data[:len(data)-1]), tt.name)
+		assert.Error(t, tt.decoded.UnmarshalCBOR(append(data, 0)), tt.name)
+	}
+
+	data, err := eds.MarshalCBOR()
+	assert.NoError(t, err)
+	decoded, err := UnmarshalCBORSquare(data, NewDefaultTree)
+	if assert.NoError(t, err) {
+		assert.Equal(t, eds.flattened(), decoded.flattened())
+		assert.Equal(t, eds.RowRoots(), decoded.RowRoots())
+	}
+}
+
+func TestCBORCanonical(t *testing.T) {
+	proof := CellProof{
+		Coord: Coordinate{Row: 1, Col: 300},
+		Axis:  ColAxis,
+		Proof: Proof{Set: [][]byte{{0xab}}, Index: 1, NumLeaves: 4},
+	}
+	data, err := proof.MarshalCBOR()
+	assert.NoError(t, err)
+	want := []byte{
+		0xa4, // map(4)
+		0x64, 'a', 'x', 'i', 's', 0x01,
+		0x65, 'c', 'o', 'o', 'r', 'd', 0xa2,
+		0x63, 'c', 'o', 'l', 0x19, 0x01, 0x2c,
+		0x63, 'r', 'o', 'w', 0x01,
+		0x65, 'p', 'r', 'o', 'o', 'f', 0xa3,
+		0x63, 's', 'e', 't', 0x81, 0x41, 0xab,
+		0x65, 'i', 'n', 'd', 'e', 'x', 0x01,
+		0x6a, 'n', 'u', 'm', '_', 'l', 'e', 'a', 'v', 'e', 's', 0x04,
+		0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x01,
+	}
+	assert.Equal(t, want, data)
+
+	// The axis as a non-minimal integer.
+	nonMinimal := append([]byte{0xa4, 0x64, 'a', 'x', 'i', 's', 0x18, 0x01}, want[7:]...)
+	assert.Error(t, new(CellProof).UnmarshalCBOR(nonMinimal))
+
+	// The coordinate keys swapped.
+	swapped := append([]byte(nil), want...)
+	copy(swapped[14:26], []byte{0x63, 'r', 'o', 'w', 0x01, 0x63, 'c', 'o', 'l', 0x19, 0x01, 0x2c})
+	assert.Error(t, new(CellProof).UnmarshalCBOR(swapped))
+
+	unsupported := append([]byte(nil), want...)
+	unsupported[len(unsupported)-1] = ProofFormatVersion + 1
+	assert.True(t, errors.Is(new(CellProof).UnmarshalCBOR(unsupported), ErrUnsupportedProofVersion))
+
+	// The version missing.
+	unversioned := append([]byte{0xa3}, want[1:len(want)-9]...)
+	assert.True(t, errors.Is(new(CellProof).UnmarshalCBOR(unversioned), errMalformedEncoding))
+	// The row of the coordinate missing.
+	rowless := append([]byte(nil), want[:13]...)
+	rowless = append(append(rowless, 0xa1), want[14:21]...)
+	rowless = append(rowless, want[26:]...)
+	assert.True(t, errors.Is(new(CellProof).UnmarshalCBOR(rowless), errMalformedEncoding))
+	assert.Error(t, new(SquareRoots).UnmarshalCBOR([]byte{0xa0}))
+	_, err = UnmarshalCBORSquare([]byte{0xa0}, NewDefaultTree)
+	assert.Error(t, err)
+}
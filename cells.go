@@ -0,0 +1,85 @@
+package rsmt2d
+
+// CellIterator yields the coordinates of a set of cells one at a time, in
+// row-major order, without materializing them, so that samplers and
+// exporters can walk large squares in constant memory.
+type CellIterator struct {
+	// Rectangle [r0, r1) x [c0, c1) being walked.
+	r0, c0, r1, c1 uint
+	row, col       uint
+	// skip, if set, filters out cells.
+	skip func(r uint, c uint) bool
+}
+
+func newCellIterator(r0, c0, r1, c1 uint, skip func(r uint, c uint) bool) *CellIterator {
+	it := &CellIterator{r0: r0, c0: c0, r1: r1, c1: c1, row: r0, col: c0, skip: skip}
+	if c0 >= c1 {
+		it.row = r1
+	}
+	return it
+}
+
+// Next returns the next cell, or false once all cells have been returned.
+func (it *CellIterator) Next() (Coordinate, bool) {
+	for it.row < it.r1 {
+		r, c := it.row, it.col
+		if it.col++; it.col == it.c1 {
+			it.col = it.c0
+			it.row++
+		}
+		if it.skip == nil || !it.skip(r, c) {
+			return Coordinate{Row: r, Col: c}, true
+		}
+	}
+	return Coordinate{}, false
+}
+
+// Reset rewinds the iterator to the first cell.
+func (it *CellIterator) Reset() {
+	it.row, it.col = it.r0, it.c0
+	if it.c0 >= it.c1 {
+		it.row = it.r1
+	}
+}
+
+// CellsInQuadrant iterates over the cells of quadrant q, numbered as by
+// QuadrantOf, of an extended square of the given width.
+func CellsInQuadrant(width uint, q int) *CellIterator {
+	if q < 0 || q > 3 {
+		return newCellIterator(0, 0, 0, 0, nil)
+	}
+	origin := QuadrantOrigin(width, q)
+	half := width / 2
+	return newCellIterator(origin.Row, origin.Col, origin.Row+half, origin.Col+half, nil)
+}
+
+// CellsInRow iterates over the cells of row i of a square of the given width.
+func CellsInRow(width uint, i uint) *CellIterator {
+	if i >= width {
+		return newCellIterator(0, 0, 0, 0, nil)
+	}
+	return newCellIterator(i, 0, i+1, width, nil)
+}
+
+// CellsInCol iterates over the cells of column i of a square of the given
+// width.
+func CellsInCol(width uint, i uint) *CellIterator {
+	if i >= width {
+		return newCellIterator(0, 0, 0, 0, nil)
+	}
+	return newCellIterator(0, i, width, i+1, nil)
+}
+
+// MissingCells iterates over the nil cells of data, the shares of a square in
+// row-major order as passed to RepairExtendedDataSquare. Cells filled in
+// while iterating are skipped if not reached yet.
+func MissingCells(data [][]byte) (*CellIterator, error) {
+	n, err := squareWidth(len(data))
+	if err != nil {
+		return nil, err
+	}
+	width := uint(n)
+	return newCellIterator(0, 0, width, width, func(r uint, c uint) bool {
+		return data[r*width+c] != nil
+	}), nil
+}
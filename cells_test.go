@@ -0,0 +1,41 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectCells(it *CellIterator) []Coordinate {
+	var cells []Coordinate
+	for coord, ok := it.Next(); ok; coord, ok = it.Next() {
+		cells = append(cells, coord)
+	}
+	return cells
+}
+
+func TestCellIterators(t *testing.T) {
+	assert.Equal(t, []Coordinate{{2, 0}, {2, 1}, {3, 0}, {3, 1}}, collectCells(CellsInQuadrant(4, 2)))
+	assert.Equal(t, []Coordinate{{0, 2}, {0, 3}, {1, 2}, {1, 3}}, collectCells(CellsInQuadrant(4, 1)))
+	assert.Equal(t, []Coordinate{{1, 0}, {1, 1}, {1, 2}, {1, 3}}, collectCells(CellsInRow(4, 1)))
+	assert.Equal(t, []Coordinate{{0, 3}, {1, 3}, {2, 3}, {3, 3}}, collectCells(CellsInCol(4, 3)))
+	assert.Empty(t, collectCells(CellsInQuadrant(4, 4)))
+	assert.Empty(t, collectCells(CellsInRow(4, 4)))
+
+	it := CellsInRow(2, 0)
+	assert.Len(t, collectCells(it), 2)
+	it.Reset()
+	assert.Len(t, collectCells(it), 2)
+}
+
+func TestMissingCells(t *testing.T) {
+	data := genRandDS(4)
+	data[1], data[6], data[15] = nil, nil, nil
+	it, err := MissingCells(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Coordinate{{0, 1}, {1, 2}, {3, 3}}, collectCells(it))
+	}
+
+	_, err = MissingCells(data[:15])
+	assert.Error(t, err)
+}
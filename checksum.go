@@ -0,0 +1,65 @@
+package rsmt2d
+
+import (
+	"fmt"
+	"hash/crc32"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ShareChecksum returns the CRC-32C checksum of a share. Checksums are a
+// cheap way to detect storage corruption of individual shares before
+// verifying roots or parity, not a commitment: they are trivial to forge.
+func ShareChecksum(share []byte) uint32 {
+	return crc32.Checksum(share, castagnoli)
+}
+
+// ShareChecksums returns the checksums of the shares of the square in
+// row-major order.
+func (eds *ExtendedDataSquare) ShareChecksums() []uint32 {
+	sums := make([]uint32, 0, eds.width*eds.width)
+	for r := uint(0); r < eds.width; r++ {
+		for _, share := range eds.rowSlice(r, 0, eds.width) {
+			sums = append(sums, ShareChecksum(share))
+		}
+	}
+	return sums
+}
+
+// ErrChecksumMismatch is returned when shares do not match their checksums.
+type ErrChecksumMismatch struct {
+	Cells []Coordinate
+}
+
+func (e *ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("share checksum mismatch at %v", e.Cells)
+}
+
+// ImportChecksums makes ImportExtendedDataSquare check the shares against
+// sums, listed in the same ordering as the shares, and fail with
+// ErrChecksumMismatch listing every corrupted cell.
+func ImportChecksums(sums []uint32) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.checksums = sums
+	}
+}
+
+// verifyChecksums checks the shares of a square of the given width, in
+// row-major order, against checksums laid out in ordering.
+func verifyChecksums(data [][]byte, sums []uint32, width uint, ordering Ordering) error {
+	if len(sums) != len(data) {
+		return fmt.Errorf("got %d checksums for %d shares", len(sums), len(data))
+	}
+	var mismatched []Coordinate
+	for r := uint(0); r < width; r++ {
+		for c := uint(0); c < width; c++ {
+			if ShareChecksum(data[r*width+c]) != sums[ordering.CellIndex(width, r, c)] {
+				mismatched = append(mismatched, Coordinate{Row: r, Col: c})
+			}
+		}
+	}
+	if mismatched != nil {
+		return &ErrChecksumMismatch{Cells: mismatched}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportChecksums(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	sums := eds.ShareChecksums()
+	assert.Len(t, sums, 16)
+
+	_, err = ImportExtendedDataSquare(eds.flattened(), codec, NewDefaultTree, ImportChecksums(sums))
+	assert.NoError(t, err)
+
+	// Checksums follow the ordering of the shares.
+	columnMajor, err := ReorderShares(eds.flattened(), RowMajor, ColumnMajor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columnSums := make([]uint32, len(columnMajor))
+	for i, share := range columnMajor {
+		columnSums[i] = ShareChecksum(share)
+	}
+	columnSums[2] ^= 1 // cell (2, 0)
+	_, err = ImportExtendedDataSquare(columnMajor, codec, NewDefaultTree,
+		ImportOrdering(ColumnMajor), ImportChecksums(columnSums))
+	var mismatch *ErrChecksumMismatch
+	if assert.True(t, errors.As(err, &mismatch), "expected ErrChecksumMismatch, got %v", err) {
+		assert.Equal(t, []Coordinate{{Row: 2, Col: 0}}, mismatch.Cells)
+	}
+
+	_, err = ImportExtendedDataSquare(eds.flattened(), codec, NewDefaultTree, ImportChecksums(sums[1:]))
+	assert.Error(t, err)
+}
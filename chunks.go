@@ -0,0 +1,152 @@
+package rsmt2d
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidChunkFraming is returned by JoinChunks for chunks that were not
+// produced by SplitIntoChunks: a missing or oversized length prefix, or
+// non-zero padding.
+var ErrInvalidChunkFraming = errors.New("invalid chunk framing")
+
+// SplitIntoChunks splits data into chunks of chunkSize bytes, to be laid out
+// in a square. The data is prefixed with its length, as a uvarint, and the
+// last chunk is padded with zeros, so that JoinChunks recovers data exactly,
+// including trailing zeros. The number of chunks is not rounded up to a
+// square number. It fails with ErrInvalidChunkSize if chunkSize is not
+// positive.
+func SplitIntoChunks(data []byte, chunkSize int) ([][]byte, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("%w: chunk size must be positive, got %d", ErrInvalidChunkSize, chunkSize)
+	}
+	framed := appendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(data)), uint64(len(data)))
+	framed = append(framed, data...)
+
+	n := (len(framed) + chunkSize - 1) / chunkSize
+	chunks := make([][]byte, n)
+	for i := range chunks {
+		chunks[i] = make([]byte, chunkSize)
+		copy(chunks[i], framed[i*chunkSize:])
+	}
+	return chunks, nil
+}
+
+// JoinChunks returns the data split into chunks by SplitIntoChunks. Chunks
+// following the ones holding the data, such as those padding a square, must
+// be zero. It fails with ErrInvalidChunkFraming if the framing is invalid and
+// ErrInvalidChunkSize if the chunks differ in size.
+func JoinChunks(chunks [][]byte) ([]byte, error) {
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%w: no chunks", ErrInvalidChunkFraming)
+	}
+	for i, chunk := range chunks {
+		if len(chunk) == 0 || len(chunk) != len(chunks[0]) {
+			return nil, fmt.Errorf("%w: chunk %d has %d bytes, expected %d",
+				ErrInvalidChunkSize, i, len(chunk), len(chunks[0]))
+		}
+	}
+
+	framed := flattenChunks(chunks)
+	length, n := binary.Uvarint(framed)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: invalid length prefix", ErrInvalidChunkFraming)
+	}
+	framed = framed[n:]
+	if length > uint64(len(framed)) {
+		return nil, fmt.Errorf("%w: length %d exceeds the %d bytes of the chunks", ErrInvalidChunkFraming, length, len(framed))
+	}
+	if err := checkZeroPadding(framed[length:]); err != nil {
+		return nil, err
+	}
+	return framed[:length], nil
+}
+
+// PackPayloads lays out payloads in the chunks of an original data square,
+// to be extended with ComputeExtendedDataSquare and read back with
+// UnpackPayloads, so that a square can hold several blobs.
+//
+// The chunks are filled in row-major order. Each payload starts at the
+// beginning of a chunk, so that its cells can be proven independently of the
+// other payloads, and is prefixed with its length plus one, as a uvarint. The
+// last chunk of each payload is padded with zeros, and zero chunks are added
+// after the last payload to fill a square, the zero prefix marking the end of
+// the payloads.
+func PackPayloads(payloads [][]byte, chunkSize int) ([][]byte, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("%w: chunk size must be positive, got %d", ErrInvalidChunkSize, chunkSize)
+	}
+	var chunks [][]byte
+	for _, payload := range payloads {
+		framed := appendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(payload)), uint64(len(payload))+1)
+		framed = append(framed, payload...)
+		for i := 0; i < len(framed); i += chunkSize {
+			chunk := make([]byte, chunkSize)
+			copy(chunk, framed[i:])
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	width := intSqrt(len(chunks))
+	if width*width < len(chunks) || width == 0 {
+		width++
+	}
+	for len(chunks) < width*width {
+		chunks = append(chunks, make([]byte, chunkSize))
+	}
+	return chunks, nil
+}
+
+// UnpackPayloads returns the payloads laid out in the original data of eds
+// by PackPayloads. It fails with ErrInvalidChunkFraming if the framing is
+// invalid and ErrCellMissing if the square has not been fully repaired.
+func UnpackPayloads(eds *ExtendedDataSquare) ([][]byte, error) {
+	k := eds.originalDataWidth
+	stream := make([]byte, 0, k*k*eds.chunkSize)
+	for r := uint(0); r < k; r++ {
+		for c, chunk := range eds.Row(r)[:k] {
+			if chunk == nil {
+				return nil, &ErrCellMissing{Coord: Coordinate{Row: r, Col: uint(c)}}
+			}
+			stream = append(stream, chunk...)
+		}
+	}
+
+	chunkSize := int(eds.chunkSize)
+	payloads := [][]byte{}
+	for offset := 0; offset < len(stream); {
+		prefix, n := binary.Uvarint(stream[offset:])
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: invalid length prefix at byte %d", ErrInvalidChunkFraming, offset)
+		}
+		if prefix == 0 {
+			// Padding after the last payload.
+			if err := checkZeroPadding(stream[offset:]); err != nil {
+				return nil, err
+			}
+			break
+		}
+		start := offset + n
+		if prefix-1 > uint64(len(stream)-start) {
+			return nil, fmt.Errorf("%w: payload length %d at byte %d exceeds the square", ErrInvalidChunkFraming, prefix-1, offset)
+		}
+		end := start + int(prefix-1)
+		next := (end + chunkSize - 1) / chunkSize * chunkSize
+		if err := checkZeroPadding(stream[end:next]); err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, stream[start:end:end])
+		offset = next
+	}
+	return payloads, nil
+}
+
+func checkZeroPadding(padding []byte) error {
+	for _, b := range padding {
+		if b != 0 {
+			return fmt.Errorf("%w: non-zero padding", ErrInvalidChunkFraming)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitIntoChunks(t *testing.T) {
+	for _, tc := range []struct {
+		dataLen   int
+		chunkSize int
+		chunks    int
+	}{
+		{0, 1, 1},
+		{0, 64, 1},
+		{63, 64, 1},
+		{64, 64, 2},
+		{127, 1, 128},
+		{128, 1, 130},
+		{1000, 100, 11},
+	} {
+		data := make([]byte, tc.dataLen)
+		testRand.Read(data)
+		chunks, err := SplitIntoChunks(data, tc.chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != tc.chunks {
+			t.Errorf("%d bytes in chunks of %d: got %d chunks, expected %d", tc.dataLen, tc.chunkSize, len(chunks), tc.chunks)
+		}
+		for _, chunk := range chunks {
+			if len(chunk) != tc.chunkSize {
+				t.Fatalf("got a chunk of %d bytes, expected %d", len(chunk), tc.chunkSize)
+			}
+		}
+		// Zero chunks padding a square are ignored.
+		joined, err := JoinChunks(append(chunks, make([]byte, tc.chunkSize)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(joined, data) {
+			t.Errorf("%d bytes in chunks of %d: joined chunks differ from the data", tc.dataLen, tc.chunkSize)
+		}
+	}
+
+	// Trailing zeros of the data are kept.
+	chunks, err := SplitIntoChunks([]byte{1, 0, 0}, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined, err := JoinChunks(chunks)
+	if err != nil || !bytes.Equal(joined, []byte{1, 0, 0}) {
+		t.Errorf("got %v, %v, expected [1 0 0]", joined, err)
+	}
+
+	if _, err := SplitIntoChunks([]byte("data"), 0); !errors.Is(err, ErrInvalidChunkSize) {
+		t.Errorf("got %v, expected %v", err, ErrInvalidChunkSize)
+	}
+}
+
+func TestJoinChunksInvalid(t *testing.T) {
+	chunks, err := SplitIntoChunks([]byte("data"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := [][]byte{chunks[0], append([]byte(nil), chunks[1]...)}
+	padded[1][3] = 1
+	tooLong := [][]byte{{200, 1, 0, 0}}
+
+	for name, tc := range map[string]struct {
+		chunks [][]byte
+		err    error
+	}{
+		"no chunks":        {nil, ErrInvalidChunkFraming},
+		"size mismatch":    {[][]byte{chunks[0], {0}}, ErrInvalidChunkSize},
+		"non-zero padding": {padded, ErrInvalidChunkFraming},
+		"length too large": {tooLong, ErrInvalidChunkFraming},
+		"truncated prefix": {[][]byte{{0x80}}, ErrInvalidChunkFraming},
+	} {
+		if _, err := JoinChunks(tc.chunks); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, expected %v", name, err, tc.err)
+		}
+	}
+}
+
+func TestPackPayloads(t *testing.T) {
+	for _, payloads := range [][][]byte{
+		{},
+		{{}},
+		{[]byte("a")},
+		{[]byte("first"), {}, bytes.Repeat([]byte{0}, 64), bytes.Repeat([]byte{7}, 200), []byte("last")},
+	} {
+		chunks, err := PackPayloads(payloads, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := squareWidth(len(chunks)); err != nil {
+			t.Fatalf("packed %d payloads into %d chunks: %v", len(payloads), len(chunks), err)
+		}
+		eds, err := ComputeExtendedDataSquare(chunks, NewRSGF8Codec(), NewDefaultTree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unpacked, err := UnpackPayloads(eds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unpacked) != len(payloads) {
+			t.Fatalf("unpacked %d payloads, expected %d", len(unpacked), len(payloads))
+		}
+		for i := range payloads {
+			if !bytes.Equal(unpacked[i], payloads[i]) {
+				t.Errorf("payload %d differs", i)
+			}
+		}
+	}
+
+	// Payloads start at chunk boundaries: the second payload starts at the
+	// third chunk.
+	chunks, err := PackPayloads([][]byte{make([]byte, 40), []byte("b")}, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 || !bytes.Equal(chunks[2][:2], []byte{2, 'b'}) {
+		t.Errorf("unexpected layout %v", chunks)
+	}
+
+	if _, err := PackPayloads(nil, 0); !errors.Is(err, ErrInvalidChunkSize) {
+		t.Errorf("got %v, expected ErrInvalidChunkSize", err)
+	}
+}
+
+func TestUnpackPayloadsInvalid(t *testing.T) {
+	for name, chunks := range map[string][][]byte{
+		"length too large": {{5, 1}},
+		"non-zero padding": {{2, 'a', 1, 0}},
+		"non-zero trailer": {{2, 'a'}, {0, 1}, {0, 0}, {0, 0}},
+		"truncated prefix": {{0x80}},
+	} {
+		eds, err := ComputeExtendedDataSquare(chunks, NewRSGF8Codec(), NewDefaultTree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := UnpackPayloads(eds); !errors.Is(err, ErrInvalidChunkFraming) {
+			t.Errorf("%s: got %v, expected ErrInvalidChunkFraming", name, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+)
+
+const (
+	cidVersion    = 1
+	rawCodec      = 0x55
+	sha256Code    = 0x12
+	base32Prefix  = 'b'
+	cidPrefixSize = 4
+)
+
+var cidEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// ErrInvalidCID is returned when a CID cannot be parsed or does not match a block.
+var ErrInvalidCID = errors.New("invalid cell CID")
+
+// CID is a CIDv1 (raw codec, sha2-256 multihash) addressing a cell block.
+type CID []byte
+
+// String returns the base32 multibase representation of the CID.
+func (c CID) String() string {
+	return string(base32Prefix) + cidEncoding.EncodeToString(c)
+}
+
+// ParseCID parses the base32 multibase representation of a cell CID.
+func ParseCID(s string) (CID, error) {
+	if len(s) == 0 || s[0] != base32Prefix {
+		return nil, ErrInvalidCID
+	}
+	c, err := cidEncoding.DecodeString(s[1:])
+	if err != nil || len(c) != cidPrefixSize+sha256.Size ||
+		!bytes.Equal(c[:cidPrefixSize], []byte{cidVersion, rawCodec, sha256Code, sha256.Size}) {
+		return nil, ErrInvalidCID
+	}
+	return c, nil
+}
+
+// NewCellBlock returns the content-addressed block of a cell. The block binds
+// the share to its coordinates and to the root of the row it belongs to, so
+// that identical shares at different positions have distinct CIDs.
+func NewCellBlock(root []byte, coord Coordinate, share []byte) []byte {
+	b := appendBytes(nil, root)
+	b = appendUvarint(b, uint64(coord.Row))
+	b = appendUvarint(b, uint64(coord.Col))
+	return append(b, share...)
+}
+
+// DecodeCellBlock splits a block created by NewCellBlock into its parts.
+func DecodeCellBlock(block []byte) (root []byte, coord Coordinate, share []byte, err error) {
+	d := decoder{buf: block}
+	root = d.bytes()
+	coord = Coordinate{Row: uint(d.uvarint()), Col: uint(d.uvarint())}
+	if d.err != nil {
+		return nil, Coordinate{}, nil, d.err
+	}
+	return root, coord, d.buf, nil
+}
+
+// BlockCID returns the CID of a block.
+func BlockCID(block []byte) CID {
+	digest := sha256.Sum256(block)
+	return append(CID{cidVersion, rawCodec, sha256Code, sha256.Size}, digest[:]...)
+}
+
+// VerifyBlockCID checks that block is addressed by c.
+func VerifyBlockCID(c CID, block []byte) error {
+	if !bytes.Equal(c, BlockCID(block)) {
+		return ErrInvalidCID
+	}
+	return nil
+}
+
+// CellCID returns the CID of the cell at coord.
+func (eds *ExtendedDataSquare) CellCID(coord Coordinate) (CID, error) {
+	if coord.Row >= eds.width || coord.Col >= eds.width {
+		return nil, fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, eds.width)
+	}
+	return BlockCID(eds.CellBlock(coord)), nil
+}
+
+// CellBlock returns the content-addressed block of the cell at coord.
+func (eds *ExtendedDataSquare) CellBlock(coord Coordinate) []byte {
+	return NewCellBlock(eds.getRowRoot(coord.Row), coord, eds.squareRow[coord.Row][coord.Col])
+}
+
+// CIDIndex maps the string form of every cell's CID to its coordinate, for
+// resolving incoming block requests.
+func (eds *ExtendedDataSquare) CIDIndex() map[string]Coordinate {
+	rowRoots := eds.getRowRoots()
+	index := make(map[string]Coordinate, eds.width*eds.width)
+	for r := uint(0); r < eds.width; r++ {
+		for c := uint(0); c < eds.width; c++ {
+			coord := Coordinate{Row: r, Col: c}
+			block := NewCellBlock(rowRoots[r], coord, eds.squareRow[r][c])
+			index[BlockCID(block).String()] = coord
+		}
+	}
+	return index
+}
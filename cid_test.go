@@ -0,0 +1,49 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCellCID(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare([][]byte{
+		{1}, {1},
+		{1}, {1},
+	}, NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	index := eds.CIDIndex()
+	assert.Len(t, index, int(eds.Width()*eds.Width()))
+
+	coord := Coordinate{Row: 1, Col: 0}
+	c, err := eds.CellCID(coord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseCID(c.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, c, parsed)
+	assert.Equal(t, coord, index[c.String()])
+
+	block := eds.CellBlock(coord)
+	assert.NoError(t, VerifyBlockCID(parsed, block))
+	root, decodedCoord, share, err := DecodeCellBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, eds.RowRoots()[1], root)
+	assert.Equal(t, coord, decodedCoord)
+	assert.True(t, bytes.Equal([]byte{1}, share))
+
+	block[len(block)-1]++
+	assert.Equal(t, ErrInvalidCID, VerifyBlockCID(parsed, block))
+
+	_, err = ParseCID("z" + c.String()[1:])
+	assert.Equal(t, ErrInvalidCID, err)
+}
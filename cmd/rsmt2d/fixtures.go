@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lazyledger/rsmt2d"
+)
+
+// errFixturesFailed is returned when at least one fixture does not match
+// this implementation, after the report has been printed.
+var errFixturesFailed = errors.New("fixtures do not match")
+
+// runFixtures implements the fixtures command. It checks JSON fixture files,
+// in the format of the test vectors, produced by other implementations, and
+// prints one line per fixture.
+func runFixtures(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("fixtures", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintln(out, "usage: rsmt2d fixtures <file>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing fixture file")
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		fixtures, err := loadFixtureFile(path)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		for i := range fixtures {
+			f := &fixtures[i]
+			status := "ok"
+			if err := f.Check(nil); err != nil {
+				status = "FAILED: " + err.Error()
+				failed = true
+			}
+			fmt.Fprintf(out, "%s[%d] %s, original width %d: %s\n", path, i, f.Codec, f.OriginalWidth, status)
+		}
+	}
+	if failed {
+		return errFixturesFailed
+	}
+	return nil
+}
+
+func loadFixtureFile(path string) ([]rsmt2d.Fixture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return rsmt2d.LoadFixtures(file)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtures(t *testing.T) {
+	vectors := filepath.Join("..", "..", "testdata", "vectors", "RSGF8.json")
+
+	var out bytes.Buffer
+	assert.NoError(t, runFixtures([]string{vectors}, &out))
+	assert.Equal(t, strings.Count(out.String(), "\n"), strings.Count(out.String(), ": ok\n"))
+
+	dir, err := ioutil.TempDir("", "rsmt2d-fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bad := filepath.Join(dir, "bad.json")
+	fixture := `{"codec": "RSGF8", "tree": "DefaultTree", "original_width": 1, "chunk_size": 1,
+		"original": ["01"], "extended": ["01", "01", "01", "02"]}`
+	if err := ioutil.WriteFile(bad, []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	assert.Equal(t, errFixturesFailed, runFixtures([]string{vectors, bad}, &out))
+	assert.Contains(t, out.String(), "bad.json[0] RSGF8, original width 1: FAILED")
+
+	assert.Error(t, runFixtures(nil, &out))
+}
@@ -0,0 +1,57 @@
+// Command rsmt2d provides offline tooling for extended data squares.
+//
+// Usage:
+//
+//	rsmt2d <command> [arguments]
+//
+// The commands are:
+//
+//	verify    check stored shares against a data availability header
+//	fixtures  check squares produced by other implementations
+//	stress    repeatedly extend, erase and repair random squares
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name  string
+	short string
+	run   func(args []string, out io.Writer) error
+}
+
+var commands = []command{
+	{"verify", "check stored shares against a data availability header", runVerify},
+	{"fixtures", "check squares produced by other implementations", runFixtures},
+	{"stress", "repeatedly extend, erase and repair random squares", runStress},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: rsmt2d <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s%s\n", c.name, c.short)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "rsmt2d %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage(os.Stderr)
+	os.Exit(2)
+}
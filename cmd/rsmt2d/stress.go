@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/lazyledger/rsmt2d"
+)
+
+// runStress implements the stress command. It extends random squares of
+// random sizes, erases random shares while keeping them repairable, repairs
+// them and checks the result, in a loop, to qualify codec backends and
+// hardware. It prints the throughput of extension and repair and the memory
+// used.
+func runStress(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("stress", flag.ContinueOnError)
+	fs.SetOutput(out)
+	codecName := fs.String("codec", "RSGF8", "codec to extend and repair squares with")
+	iterations := fs.Int("iterations", 100, "number of squares to extend and repair, 0 for no limit")
+	duration := fs.Duration("duration", 0, "time to run for, 0 for no limit")
+	maxWidth := fs.Int("max-width", 16, "largest original width of the squares")
+	maxChunk := fs.Int("max-chunk", 512, "largest chunk size in bytes")
+	seed := fs.Int64("seed", 0, "seed of the random parameters and data, 0 for a random seed")
+	verbose := fs.Bool("v", false, "print every iteration")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "usage: rsmt2d stress [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *iterations == 0 && *duration == 0 {
+		return errors.New("either -iterations or -duration must be set")
+	}
+	if *maxWidth < 1 || *maxChunk < 1 {
+		return errors.New("-max-width and -max-chunk must be positive")
+	}
+
+	codec, err := rsmt2d.CodecByName(*codecName)
+	if err != nil {
+		return err
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(*seed))
+	fmt.Fprintf(out, "codec %s, seed %d\n", *codecName, *seed)
+
+	var stats stressStats
+	start := time.Now()
+	for i := 0; *iterations == 0 || i < *iterations; i++ {
+		if *duration > 0 && time.Since(start) >= *duration {
+			break
+		}
+		it, err := stressIteration(rnd, codec, *maxWidth, *maxChunk)
+		if err != nil {
+			return fmt.Errorf("iteration %d: %v", i, err)
+		}
+		stats.add(it)
+		if *verbose {
+			fmt.Fprintf(out, "iteration %d: original width %d, chunk size %d, %d shares erased, extend %v, repair %v\n",
+				i, it.k, it.chunkSize, it.erased, it.extend, it.repair)
+		}
+	}
+	stats.print(out, time.Since(start))
+	return nil
+}
+
+// stressResult is the outcome of one extend, erase and repair cycle.
+type stressResult struct {
+	k         int
+	chunkSize int
+	erased    int
+	extend    time.Duration
+	repair    time.Duration
+}
+
+// stressIteration extends a random square, erases random shares, keeping it
+// repairable, and checks that repairing it restores its data root.
+func stressIteration(rnd *rand.Rand, codec rsmt2d.Codec, maxWidth int, maxChunk int) (stressResult, error) {
+	it := stressResult{k: 1 + rnd.Intn(maxWidth), chunkSize: stressChunkSize(rnd, codec, maxChunk)}
+	original := make([][]byte, it.k*it.k)
+	for i := range original {
+		original[i] = make([]byte, it.chunkSize)
+		rnd.Read(original[i])
+	}
+
+	start := time.Now()
+	eds, err := rsmt2d.ComputeExtendedDataSquare(original, codec, rsmt2d.NewDefaultTree)
+	if err != nil {
+		return it, fmt.Errorf("extending a square of original width %d with %d byte chunks: %v", it.k, it.chunkSize, err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+	it.extend = time.Since(start)
+
+	// Keep a random k by k grid of shares, which is enough to decode its rows
+	// and then every column, and a random half of the others.
+	width := 2 * it.k
+	rows, cols := rnd.Perm(width)[:it.k], rnd.Perm(width)[:it.k]
+	keep := make([]bool, width*width)
+	for _, r := range rows {
+		for _, c := range cols {
+			keep[r*width+c] = true
+		}
+	}
+	shares := make([][]byte, width*width)
+	for i := range shares {
+		if keep[i] || rnd.Intn(2) == 0 {
+			shares[i], _ = eds.GetCell(uint(i/width), uint(i%width))
+		} else {
+			it.erased++
+		}
+	}
+
+	start = time.Now()
+	repaired, err := rsmt2d.RepairExtendedDataSquare(rowRoots, colRoots, shares, codec, rsmt2d.NewDefaultTree)
+	it.repair = time.Since(start)
+	if err != nil {
+		return it, fmt.Errorf("repairing a square of original width %d with %d shares erased: %v", it.k, it.erased, err)
+	}
+	if !bytes.Equal(repaired.DataRoot(), eds.DataRoot()) {
+		return it, fmt.Errorf("repaired square of original width %d has a different data root", it.k)
+	}
+	return it, nil
+}
+
+// stressChunkSize returns a random chunk size of up to max bytes supported by
+// codec.
+func stressChunkSize(rnd *rand.Rand, codec rsmt2d.Codec, max int) int {
+	multiple := 1
+	if limits, ok := codec.(rsmt2d.ChunkSizeLimits); ok {
+		if limits.MaxChunkSize() > 0 && limits.MaxChunkSize() < max {
+			max = limits.MaxChunkSize()
+		}
+		if limits.ChunkSizeMultiple() > 1 {
+			multiple = limits.ChunkSizeMultiple()
+		}
+	}
+	if max < multiple {
+		return multiple
+	}
+	return multiple * (1 + rnd.Intn(max/multiple))
+}
+
+// stressStats accumulates the outcome of the iterations of the stress
+// command.
+type stressStats struct {
+	iterations    int
+	originalBytes int64
+	extendedBytes int64
+	extend        time.Duration
+	repair        time.Duration
+	peakHeap      uint64
+}
+
+func (s *stressStats) add(it stressResult) {
+	s.iterations++
+	s.originalBytes += int64(it.k * it.k * it.chunkSize)
+	s.extendedBytes += int64(4 * it.k * it.k * it.chunkSize)
+	s.extend += it.extend
+	s.repair += it.repair
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	if mem.HeapInuse > s.peakHeap {
+		s.peakHeap = mem.HeapInuse
+	}
+}
+
+func (s *stressStats) print(out io.Writer, elapsed time.Duration) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(out, "%d iterations in %v\n", s.iterations, elapsed.Round(time.Millisecond))
+	fmt.Fprintf(out, "extend: %d original bytes in %v, %.1f MB/s\n", s.originalBytes, s.extend, throughput(s.originalBytes, s.extend))
+	fmt.Fprintf(out, "repair: %d extended bytes in %v, %.1f MB/s\n", s.extendedBytes, s.repair, throughput(s.extendedBytes, s.repair))
+	fmt.Fprintf(out, "memory: peak heap %.1f MiB, %.1f MiB allocated, %d GC cycles\n",
+		float64(s.peakHeap)/(1<<20), float64(mem.TotalAlloc)/(1<<20), mem.NumGC)
+}
+
+// throughput returns n bytes per d in megabytes per second.
+func throughput(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / 1e6 / d.Seconds()
+}
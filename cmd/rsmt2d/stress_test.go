@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStress(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, runStress([]string{"-iterations", "5", "-max-width", "4", "-max-chunk", "64", "-seed", "1", "-v"}, &out))
+	assert.Contains(t, out.String(), "codec RSGF8, seed 1\n")
+	assert.Contains(t, out.String(), "iteration 4: original width")
+	assert.Contains(t, out.String(), "5 iterations in")
+	assert.Contains(t, out.String(), "MB/s")
+
+	assert.Error(t, runStress([]string{"-codec", "unknown"}, &out))
+	assert.Error(t, runStress([]string{"-iterations", "0"}, &out))
+	assert.Error(t, runStress([]string{"extra"}, &out))
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lazyledger/rsmt2d"
+)
+
+// errVerifyFailed is returned when at least one axis does not match the
+// header, after the report has been printed.
+var errVerifyFailed = errors.New("square does not match the header")
+
+// header is the JSON representation of a data availability header read by
+// verify: the hex encoded row and column roots.
+type header struct {
+	RowRoots []string `json:"row_roots"`
+	ColRoots []string `json:"col_roots"`
+}
+
+// runVerify implements the verify command. It reads the shares of an
+// extended square from a directory holding one file per share, named
+// "<row>-<col>", rebuilds the roots, repairing missing shares if possible,
+// and prints a per-axis report of the comparison with the header. Reading
+// shares from CAR archives is not supported.
+func runVerify(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(out)
+	headerPath := fs.String("header", "", "path of the JSON data availability header")
+	codecName := fs.String("codec", "RSGF8", "codec used to extend the square")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "usage: rsmt2d verify -header <file> [-codec <name>] <share directory>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *headerPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("missing header or share directory")
+	}
+
+	codec, err := rsmt2d.CodecByName(*codecName)
+	if err != nil {
+		return err
+	}
+	rowRoots, colRoots, err := readHeader(*headerPath)
+	if err != nil {
+		return err
+	}
+	shares, err := readShareDir(fs.Arg(0), len(rowRoots))
+	if err != nil {
+		return err
+	}
+
+	missing := 0
+	for _, s := range shares {
+		if s == nil {
+			missing++
+		}
+	}
+	fmt.Fprintf(out, "width %d, %d of %d shares missing\n", len(rowRoots), missing, len(shares))
+
+	var eds *rsmt2d.ExtendedDataSquare
+	if missing == 0 {
+		eds, err = rsmt2d.ImportExtendedDataSquare(shares, codec, rsmt2d.NewDefaultTree)
+	} else {
+		eds, err = rsmt2d.RepairExtendedDataSquare(rowRoots, colRoots, shares, codec, rsmt2d.NewDefaultTree)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "square cannot be rebuilt: %v\n", err)
+		return errVerifyFailed
+	}
+
+	failed := false
+	report := func(axis string, expected [][]byte, computed [][]byte) {
+		mismatched := make(map[uint]bool)
+		for _, i := range rsmt2d.DiffRoots(expected, computed) {
+			mismatched[i] = true
+			failed = true
+		}
+		for i := range expected {
+			status := "ok"
+			if mismatched[uint(i)] {
+				status = "ROOT MISMATCH"
+			}
+			fmt.Fprintf(out, "%s %d: %s\n", axis, i, status)
+		}
+	}
+	report("row", rowRoots, eds.RowRoots())
+	report("column", colRoots, eds.ColRoots())
+	if failed {
+		return errVerifyFailed
+	}
+	return nil
+}
+
+func readHeader(path string) ([][]byte, [][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var h header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, nil, fmt.Errorf("parsing header: %v", err)
+	}
+	if len(h.RowRoots) == 0 || len(h.RowRoots) != len(h.ColRoots) {
+		return nil, nil, errors.New("header must hold the same, non-zero number of row and column roots")
+	}
+	rowRoots, err := decodeHexAll(h.RowRoots)
+	if err != nil {
+		return nil, nil, err
+	}
+	colRoots, err := decodeHexAll(h.ColRoots)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rowRoots, colRoots, nil
+}
+
+func decodeHexAll(s []string) ([][]byte, error) {
+	decoded := make([][]byte, len(s))
+	for i := range s {
+		var err error
+		if decoded[i], err = hex.DecodeString(s[i]); err != nil {
+			return nil, fmt.Errorf("parsing header: %v", err)
+		}
+	}
+	return decoded, nil
+}
+
+// readShareDir reads the shares of a square of the given width from dir in
+// row-major order. Shares without a file are nil.
+func readShareDir(dir string, width int) ([][]byte, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([][]byte, width*width)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		parts := strings.Split(e.Name(), "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected file %s", e.Name())
+		}
+		row, errRow := strconv.Atoi(parts[0])
+		col, errCol := strconv.Atoi(parts[1])
+		if errRow != nil || errCol != nil || row < 0 || row >= width || col < 0 || col >= width {
+			return nil, fmt.Errorf("unexpected file %s", e.Name())
+		}
+		share, err := ioutil.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		shares[row*width+col] = share
+	}
+	return shares, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lazyledger/rsmt2d"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSquare(t *testing.T, dir string, eds *rsmt2d.ExtendedDataSquare, skip map[string]bool) string {
+	shareDir := filepath.Join(dir, "shares")
+	if err := os.Mkdir(shareDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for r := uint(0); r < eds.Width(); r++ {
+		for c, share := range eds.Row(r) {
+			name := fmt.Sprintf("%d-%d", r, c)
+			if skip[name] {
+				continue
+			}
+			if err := ioutil.WriteFile(filepath.Join(shareDir, name), share, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	hexAll := func(b [][]byte) []string {
+		s := make([]string, len(b))
+		for i := range b {
+			s[i] = hex.EncodeToString(b[i])
+		}
+		return s
+	}
+	data, err := json.Marshal(header{RowRoots: hexAll(eds.RowRoots()), ColRoots: hexAll(eds.ColRoots())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerPath := filepath.Join(dir, "header.json")
+	if err := ioutil.WriteFile(headerPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return headerPath
+}
+
+func TestVerify(t *testing.T) {
+	ods := make([][]byte, 4)
+	for i := range ods {
+		ods[i] = make([]byte, 64)
+		rand.Read(ods[i])
+	}
+	eds, err := rsmt2d.ComputeExtendedDataSquare(ods, rsmt2d.NewRSGF8Codec(), rsmt2d.NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	dir, err := ioutil.TempDir("", "rsmt2d-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	headerPath := writeSquare(t, dir, eds, map[string]bool{"0-0": true, "2-3": true})
+
+	var out bytes.Buffer
+	err = runVerify([]string{"-header", headerPath, filepath.Join(dir, "shares")}, &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "2 of 16 shares missing")
+	assert.Equal(t, 8, strings.Count(out.String(), ": ok"))
+
+	// Corrupt a share so that its row and column cannot be rebuilt.
+	if err := ioutil.WriteFile(filepath.Join(dir, "shares", "1-1"), make([]byte, 64), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	err = runVerify([]string{"-header", headerPath, filepath.Join(dir, "shares")}, &out)
+	assert.Equal(t, errVerifyFailed, err)
+
+	assert.Error(t, runVerify([]string{filepath.Join(dir, "shares")}, &out))
+}
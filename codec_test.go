@@ -1,8 +1,10 @@
 package rsmt2d
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -34,7 +36,7 @@ func generateRandData(count int) [][]byte {
 	out := make([][]byte, count)
 	for i := 0; i < count; i++ {
 		randData := make([]byte, count)
-		_, err := rand.Read(randData)
+		_, err := testRand.Read(randData)
 		if err != nil {
 			panic(err)
 		}
@@ -72,7 +74,7 @@ func generateMissingData(count int, codec Codec) [][]byte {
 
 	// remove half of the shares randomly
 	for i := 0; i < (count / 2); {
-		ind := rand.Intn(count)
+		ind := testRand.Intn(count)
 		if len(output[ind]) == 0 {
 			continue
 		}
@@ -82,3 +84,402 @@ func generateMissingData(count int, codec Codec) [][]byte {
 
 	return output
 }
+
+func TestValidWidths(t *testing.T) {
+	for codecName, codec := range codecs {
+		widths := ValidWidths(codec)
+		if len(widths) == 0 || widths[0] != 1 {
+			t.Fatalf("unexpected valid widths for %s: %v", codecName, widths)
+		}
+		maxWidth := widths[len(widths)-1]
+		if !IsValidWidth(maxWidth, codec) || IsValidWidth(maxWidth+1, codec) || IsValidWidth(0, codec) {
+			t.Errorf("IsValidWidth inconsistent with ValidWidths for %s", codecName)
+		}
+		if MaxSquareWidth(codec) != maxWidth {
+			t.Errorf("MaxSquareWidth(%s) = %d, expected %d", codecName, MaxSquareWidth(codec), maxWidth)
+		}
+
+		// Extending every width is slow, so check all small widths and a
+		// selection of larger ones.
+		checked := widths[:16:16]
+		for _, width := range []int{63, 100, 127, 128} {
+			if IsValidWidth(width, codec) {
+				checked = append(checked, width)
+			}
+		}
+		for _, width := range checked {
+			data := make([][]byte, width*width)
+			for i := range data {
+				data[i] = bytes.Repeat([]byte{byte(i)}, 64)
+			}
+			if _, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree); err != nil {
+				t.Errorf("extending width %d failed for %s: %v", width, codecName, err)
+			}
+		}
+
+		if maxWidth > 128 {
+			continue
+		}
+		data := make([][]byte, (maxWidth+1)*(maxWidth+1))
+		for i := range data {
+			data[i] = bytes.Repeat([]byte{byte(i)}, 64)
+		}
+		var tooMany *ErrTooManyChunks
+		if _, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree); !errors.As(err, &tooMany) {
+			t.Errorf("extending width %d for %s: got %v, expected ErrTooManyChunks", maxWidth+1, codecName, err)
+		}
+	}
+}
+
+func TestFitsCodec(t *testing.T) {
+	codec := NewRSGF8Codec()
+	maxWidth := MaxSquareWidth(codec)
+	if maxWidth != 128 {
+		t.Fatalf("MaxSquareWidth = %d, expected 128", maxWidth)
+	}
+	for _, n := range []int{1, 4, 9, 100, maxWidth * maxWidth} {
+		if err := FitsCodec(n, codec); err != nil {
+			t.Errorf("FitsCodec(%d) failed: %v", n, err)
+		}
+	}
+	if err := FitsCodec(0, codec); err != ErrEmptySquare {
+		t.Errorf("FitsCodec(0): got %v, expected ErrEmptySquare", err)
+	}
+	var invalidLen *ErrInvalidSquareLength
+	if err := FitsCodec(5, codec); !errors.As(err, &invalidLen) {
+		t.Errorf("FitsCodec(5): got %v, expected ErrInvalidSquareLength", err)
+	}
+	var tooMany *ErrTooManyChunks
+	n := (maxWidth + 1) * (maxWidth + 1)
+	if err := FitsCodec(n, codec); !errors.As(err, &tooMany) || tooMany.Max != maxWidth*maxWidth {
+		t.Errorf("FitsCodec(%d): got %v, expected ErrTooManyChunks", n, err)
+	}
+}
+
+func TestRepairNonPowerOfTwoWidths(t *testing.T) {
+	for codecName, codec := range codecs {
+		for _, width := range []int{3, 5, 6, 7, 12} {
+			eds, err := ComputeExtendedDataSquare(genRandDS(width), codec, NewDefaultTree)
+			if err != nil {
+				t.Fatalf("extending width %d failed for %s: %v", width, codecName, err)
+			}
+
+			flattened := eds.flattened()
+			for i := 0; i < len(flattened); i += 2 {
+				flattened[i] = nil
+			}
+			repaired, err := RepairExtendedDataSquare(eds.RowRoots(), eds.ColRoots(), flattened, codec, NewDefaultTree)
+			if err != nil {
+				t.Errorf("repairing width %d failed for %s: %v", width, codecName, err)
+				continue
+			}
+			if !reflect.DeepEqual(repaired.flattened(), eds.flattened()) {
+				t.Errorf("repairing width %d produced a different square for %s", width, codecName)
+			}
+		}
+	}
+}
+
+func TestBufferedCodec(t *testing.T) {
+	for codecName, codec := range codecs {
+		buffered, ok := codec.(BufferedCodec)
+		if !ok {
+			continue
+		}
+
+		data := generateRandData(16)
+		parity, err := codec.Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := make([][]byte, len(data))
+		for i := range dst {
+			dst[i] = make([]byte, len(data[0]))
+		}
+		if err := buffered.EncodeInto(dst, data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parity, dst) {
+			t.Errorf("EncodeInto and Encode differ for %s", codecName)
+		}
+
+		shares := append(append([][]byte(nil), data...), parity...)
+		for i := 0; i < len(shares); i += 2 {
+			shares[i] = nil
+		}
+		dst = dst[:len(data)]
+		if err := buffered.DecodeInto(dst, shares); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(data, dst) {
+			t.Errorf("DecodeInto did not rebuild the data for %s", codecName)
+		}
+
+		if err := buffered.EncodeInto(dst[:1], data); err == nil {
+			t.Errorf("EncodeInto accepted a destination of the wrong length for %s", codecName)
+		}
+	}
+}
+
+func TestStripedCodec(t *testing.T) {
+	for name, codec := range codecs {
+		striped, err := NewStripedCodec(codec, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := codecName(striped); err != nil || got != name {
+			t.Errorf("striped %s codec is named %q, err %v", name, got, err)
+		}
+
+		// genRandDS uses 256-byte chunks, so shares span four stripes.
+		data := genRandDS(4)[:8]
+		want, err := codec.Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parity, err := striped.Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want[len(want)-len(data):], parity) {
+			t.Errorf("striped %s codec encoded different parity", name)
+		}
+
+		shares := append(append([][]byte(nil), data...), parity...)
+		for i := 0; i < len(shares); i += 2 {
+			shares[i] = nil
+		}
+		original, err := striped.Decode(shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(data, original[:len(data)]) {
+			t.Errorf("striped %s codec did not rebuild the data", name)
+		}
+	}
+
+	if _, err := NewStripedCodec(NewRSGF8Codec(), 0); err == nil {
+		t.Errorf("NewStripedCodec accepted a zero stripe size")
+	}
+}
+
+// limitedCodec restricts chunks to multiples of 16 bytes, up to 256 bytes.
+type limitedCodec struct {
+	Codec
+}
+
+func (limitedCodec) MaxChunkSize() int      { return 256 }
+func (limitedCodec) ChunkSizeMultiple() int { return 16 }
+
+func TestChunkSizeLimits(t *testing.T) {
+	codec := limitedCodec{NewRSGF8Codec()}
+	data := make([][]byte, 4)
+	for _, tt := range []struct {
+		size  int
+		valid bool
+	}{
+		{16, true},
+		{256, true},
+		{24, false},
+		{272, false},
+	} {
+		for i := range data {
+			data[i] = make([]byte, tt.size)
+		}
+		_, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+		if tt.valid && err != nil {
+			t.Errorf("chunk size %d rejected: %v", tt.size, err)
+		}
+		var unsupported *ErrUnsupportedChunkSize
+		if !tt.valid && (!errors.As(err, &unsupported) || !errors.Is(err, ErrInvalidChunkSize)) {
+			t.Errorf("chunk size %d: expected ErrUnsupportedChunkSize, got %v", tt.size, err)
+		}
+	}
+
+	if _, err := NewStripedCodec(codec, 24); !errors.Is(err, ErrInvalidChunkSize) {
+		t.Errorf("striped codec accepted a stripe size violating the codec limits: %v", err)
+	}
+	striped, err := NewStripedCodec(codec, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range data {
+		data[i] = make([]byte, 512)
+	}
+	if _, err := ComputeExtendedDataSquare(data, striped, NewDefaultTree); err != nil {
+		t.Errorf("striped codec rejected chunks above the limit of the wrapped codec: %v", err)
+	}
+}
+
+// racyCodec encodes into a buffer shared by all calls.
+type racyCodec struct {
+	*rsGF8Codec
+	shared [][]byte
+}
+
+func (c *racyCodec) Encode(data [][]byte) ([][]byte, error) {
+	if c.shared == nil {
+		c.shared = make([][]byte, len(data))
+		for i := range c.shared {
+			c.shared[i] = make([]byte, len(data[0]))
+		}
+	}
+	if err := c.EncodeInto(c.shared, data); err != nil {
+		return nil, err
+	}
+	return c.shared, nil
+}
+
+func TestCheckCodec(t *testing.T) {
+	for name, codec := range codecs {
+		for _, k := range []int{1, 3, 8} {
+			if err := CheckCodec(codec, k, 64); err != nil {
+				t.Errorf("%s with k=%d: %v", name, k, err)
+			}
+		}
+	}
+
+	if err := CheckCodec(&racyCodec{rsGF8Codec: NewRSGF8Codec()}, 4, 64); err == nil {
+		t.Error("expected a codec sharing its output buffer to fail")
+	}
+	if err := CheckCodec(NewRSGF8Codec(), 1000, 64); err == nil {
+		t.Error("expected an unsupported width to fail")
+	}
+}
+
+func TestErrorCorrectingCodec(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := generateRandData(4)
+	parity, err := codec.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := append(cloneChunks(data), parity...)
+
+	// Two errors among eight shares are correctable, also with a share
+	// missing when only one is corrupted.
+	shares[1] = make([]byte, len(shares[1]))
+	shares[6] = bytes.Repeat([]byte{0xff}, len(shares[6]))
+	corrupted := cloneChunks(shares)
+	corrected, err := codec.Correct(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(corrected, data) {
+		t.Errorf("corrected shares differ from the original data")
+	}
+	if !reflect.DeepEqual(shares, corrupted) {
+		t.Errorf("Correct modified its input")
+	}
+
+	shares[6] = nil
+	corrected, err = codec.Correct(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(corrected, data) {
+		t.Errorf("corrected shares differ from the original data with a missing share")
+	}
+}
+
+func TestCodecDecodeErrors(t *testing.T) {
+	striped, err := NewStripedCodec(NewRSGF8Codec(), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := map[string]Codec{"striped": striped}
+	for name, codec := range codecs {
+		all[name] = codec
+	}
+
+	for name, codec := range all {
+		data := genRandDS(2)
+		parity, err := codec.Encode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares := append(cloneChunks(data), parity...)
+
+		tooFew := make([][]byte, len(shares))
+		copy(tooFew[5:], shares[5:])
+		if _, err := codec.Decode(tooFew); !errors.Is(err, ErrTooFewShards) {
+			t.Errorf("%s: decoding 3 of 8 shares returned %v, expected ErrTooFewShards", name, err)
+		}
+
+		mismatched := cloneChunks(shares)
+		mismatched[2] = mismatched[2][:len(mismatched[2])-64]
+		if _, err := codec.Decode(mismatched); !errors.Is(err, ErrShardSizeMismatch) {
+			t.Errorf("%s: decoding shares of different sizes returned %v, expected ErrShardSizeMismatch", name, err)
+		}
+	}
+
+	cause := errors.New("broken")
+	var failure error = &ErrBackendFailure{Cause: cause}
+	if !errors.Is(failure, cause) {
+		t.Errorf("ErrBackendFailure does not unwrap to its cause")
+	}
+}
+
+// corruptingCodec flips a bit of the first decoded share.
+type corruptingCodec struct {
+	Codec
+}
+
+func (c corruptingCodec) Decode(data [][]byte) ([][]byte, error) {
+	decoded, err := c.Codec.Decode(data)
+	if err == nil {
+		decoded[0][0] ^= 1
+	}
+	return decoded, err
+}
+
+func TestHealthCheck(t *testing.T) {
+	striped, err := NewStripedCodec(NewRSGF8Codec(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := striped.HealthCheck(); err != nil {
+		t.Errorf("striped codec: %v", err)
+	}
+	for name, codec := range codecs {
+		if err := codec.HealthCheck(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	var backend *ErrBackendFailure
+	if err := healthCheck(corruptingCodec{NewRSGF8Codec()}); !errors.As(err, &backend) {
+		t.Errorf("health check of a corrupting codec returned %v, expected ErrBackendFailure", err)
+	}
+}
+
+func TestCodecKernel(t *testing.T) {
+	tests := []struct {
+		name      string
+		features  cpuFeatureSet
+		available []string
+		want      string
+	}{
+		{"avx2 cpu", cpuFeatureSet{ssse3: true, avx2: true}, []string{KernelAVX2, KernelSSSE3}, KernelAVX2},
+		{"ssse3 cpu", cpuFeatureSet{ssse3: true}, []string{KernelAVX2, KernelSSSE3}, KernelSSSE3},
+		{"neon cpu", cpuFeatureSet{neon: true}, []string{KernelAVX2, KernelNEON}, KernelNEON},
+		{"no simd", cpuFeatureSet{}, []string{KernelAVX2, KernelNEON}, KernelGeneric},
+		{"no kernels", cpuFeatureSet{avx2: true}, nil, KernelGeneric},
+	}
+	for _, tt := range tests {
+		if got := bestKernel(tt.features, tt.available...); got != tt.want {
+			t.Errorf("%s: got kernel %s, expected %s", tt.name, got, tt.want)
+		}
+	}
+
+	striped, err := NewStripedCodec(NewRSGF8Codec(), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := CodecKernel(striped); got != rsGF8Kernel {
+		t.Errorf("striped codec reports kernel %s, expected %s", got, rsGF8Kernel)
+	}
+	if got := CodecKernel(corruptingCodec{NewRSGF8Codec()}); got != KernelGeneric {
+		t.Errorf("codec without kernel metadata reports kernel %s", got)
+	}
+}
@@ -1,6 +1,10 @@
 package rsmt2d
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
 
 const (
 	LeopardFF16 = "LeopardFF16"
@@ -8,13 +12,146 @@ const (
 	RSGF8       = "RSFG8"
 )
 
+// Codec is an erasure code extending k data shares with k parity shares.
+// Encode and Decode must be safe for concurrent use, as squares are extended
+// and repaired from several goroutines; CheckCodec tests this. Decode fails
+// with ErrTooFewShards when fewer than k shares are present, with an error
+// matching ErrShardSizeMismatch when shares differ in size, and with
+// ErrBackendFailure when the coding library itself fails.
 type Codec interface {
 	Encode(data [][]byte) ([][]byte, error)
 	Decode(data [][]byte) ([][]byte, error)
+	// HealthCheck encodes and decodes a tiny square, so that a broken
+	// backend, such as a missing native library or an unsupported CPU, is
+	// detected at startup rather than on the first block.
+	HealthCheck() error
 	// maxChunks returns the max. number of chunks each code supports in a 2D square.
 	maxChunks() int
 }
 
+// ErrTooFewShards is returned by Decode when fewer than half of the shares
+// are present. It is expected while repairing, until more shares arrive or
+// are recovered through other axes.
+var ErrTooFewShards = errors.New("too few shares to decode")
+
+// ErrShardSizeMismatch is matched by the errors returned by codecs for
+// shares of different sizes.
+var ErrShardSizeMismatch = errors.New("shares differ in size")
+
+// ErrBackendFailure is returned by codecs when the library implementing the
+// code fails, for reasons other than the shares given to it. Unlike
+// ErrTooFewShards, retrying with other shares does not help.
+type ErrBackendFailure struct {
+	Cause error
+}
+
+func (e *ErrBackendFailure) Error() string {
+	return fmt.Sprintf("codec backend failure: %v", e.Cause)
+}
+
+func (e *ErrBackendFailure) Unwrap() error {
+	return e.Cause
+}
+
+// healthCheck extends two chunks with codec and decodes them back from the
+// parity alone.
+func healthCheck(codec Codec) error {
+	chunkSize := 64
+	if limits, ok := codec.(ChunkSizeLimits); ok && limits.ChunkSizeMultiple() > chunkSize {
+		chunkSize = limits.ChunkSizeMultiple()
+	}
+	data := [][]byte{make([]byte, chunkSize), make([]byte, chunkSize)}
+	for i := range data[0] {
+		data[0][i] = byte(i)
+		data[1][i] = byte(255 - i)
+	}
+	parity, err := codec.Encode(data)
+	if err != nil {
+		return fmt.Errorf("health check encode: %w", err)
+	}
+	if len(parity) != len(data) {
+		return &ErrBackendFailure{Cause: fmt.Errorf("health check encode returned %d parity chunks, expected %d", len(parity), len(data))}
+	}
+	decoded, err := codec.Decode([][]byte{nil, nil, parity[0], parity[1]})
+	if err != nil {
+		return fmt.Errorf("health check decode: %w", err)
+	}
+	if len(decoded) < len(data) || !equalChunks(decoded[:len(data)], data) {
+		return &ErrBackendFailure{Cause: errors.New("health check decoded data differs from the encoded data")}
+	}
+	return nil
+}
+
+// BufferedCodec is implemented by codecs that can write their output into
+// caller-provided buffers, avoiding per-call allocations.
+type BufferedCodec interface {
+	Codec
+	// EncodeInto writes the len(data) parity shares of data into dst.
+	EncodeInto(dst [][]byte, data [][]byte) error
+	// DecodeInto writes the len(data)/2 original shares rebuilt from data
+	// into dst.
+	DecodeInto(dst [][]byte, data [][]byte) error
+}
+
+// ErrorCorrectingCodec is implemented by codecs that can correct corrupted
+// shares, not only rebuild missing ones.
+type ErrorCorrectingCodec interface {
+	Codec
+	// Correct rebuilds the len(data)/2 original shares from the present
+	// (non-nil) shares of data, of which up to (present-len(data)/2)/2 may be
+	// corrupted. data is not modified. Too many corrupted shares may go
+	// unnoticed, so the result must be checked against a commitment.
+	Correct(data [][]byte) ([][]byte, error)
+}
+
+// ChunkSizeLimits is implemented by codecs that restrict the size of the
+// chunks they code. Codecs not implementing it accept chunks of any non-zero
+// size.
+type ChunkSizeLimits interface {
+	// MaxChunkSize returns the largest supported chunk size in bytes, or 0
+	// if chunks may be arbitrarily large.
+	MaxChunkSize() int
+	// ChunkSizeMultiple returns the number of bytes chunk sizes must be a
+	// multiple of.
+	ChunkSizeMultiple() int
+}
+
+// ErrUnsupportedChunkSize is returned when a chunk size violates the
+// ChunkSizeLimits of a codec. It matches ErrInvalidChunkSize with errors.Is.
+type ErrUnsupportedChunkSize struct {
+	Size     int
+	Max      int // 0 if unbounded
+	Multiple int
+}
+
+func (e *ErrUnsupportedChunkSize) Error() string {
+	if e.Max > 0 {
+		return fmt.Sprintf(
+			"%v: codec requires a multiple of %d bytes of at most %d bytes, got %d",
+			ErrInvalidChunkSize, e.Multiple, e.Max, e.Size,
+		)
+	}
+	return fmt.Sprintf("%v: codec requires a multiple of %d bytes, got %d", ErrInvalidChunkSize, e.Multiple, e.Size)
+}
+
+// Is reports whether target is ErrInvalidChunkSize.
+func (e *ErrUnsupportedChunkSize) Is(target error) bool {
+	return target == ErrInvalidChunkSize
+}
+
+// checkChunkSize checks size against the ChunkSizeLimits of codec, if any.
+func checkChunkSize(codec Codec, size uint) error {
+	limits, ok := codec.(ChunkSizeLimits)
+	if !ok {
+		return nil
+	}
+	max, multiple := limits.MaxChunkSize(), limits.ChunkSizeMultiple()
+	if (max > 0 && size > uint(max)) || (multiple > 1 && size%uint(multiple) != 0) {
+		return &ErrUnsupportedChunkSize{Size: int(size), Max: max, Multiple: multiple}
+	}
+	return nil
+}
+
 // codecs is a global map used for keeping track of which codecs are included during testing
 var codecs = make(map[string]Codec)
 
@@ -25,6 +162,53 @@ func registerCodec(ct string, codec Codec) {
 	codecs[ct] = codec
 }
 
+// codecName returns the name under which the type of codec is registered.
+// Wrapping codecs are named after the codec they wrap.
+func codecName(codec Codec) (string, error) {
+	if w, ok := codec.(interface{ Unwrap() Codec }); ok {
+		return codecName(w.Unwrap())
+	}
+	for name, c := range codecs {
+		if reflect.TypeOf(c) == reflect.TypeOf(codec) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("codec %T is not registered", codec)
+}
+
+// codecPromotions maps codecs to the codec squares are promoted to when they
+// outgrow the widths the codec supports. Leopard FF16 codes the same way as
+// FF8 but supports far larger squares.
+var codecPromotions = map[string]string{
+	LeopardFF8: LeopardFF16,
+}
+
+// promoteCodec returns a registered codec supporting original squares of
+// width n, replacing codec if it is too small and has a promotion.
+func promoteCodec(codec Codec, n int) (Codec, error) {
+	for !IsValidWidth(n, codec) {
+		name, err := codecName(codec)
+		if err != nil {
+			return nil, fmt.Errorf("width %d is not supported by the codec", n)
+		}
+		promoted, ok := codecs[codecPromotions[name]]
+		if !ok {
+			return nil, fmt.Errorf("width %d is not supported by codec %s", n, name)
+		}
+		codec = promoted
+	}
+	return codec, nil
+}
+
+// CodecByName returns the codec registered under name, such as "RSGF8" or,
+// when built with the leopard tag, LeopardFF8 and LeopardFF16.
+func CodecByName(name string) (Codec, error) {
+	if codec, ok := codecs[name]; ok {
+		return codec, nil
+	}
+	return nil, fmt.Errorf("unknown codec %q", name)
+}
+
 func NewLeoRSFF16Codec() Codec {
 	if codec, has := codecs[LeopardFF16]; has {
 		return codec
@@ -38,3 +222,51 @@ func NewLeoRSFF8Codec() Codec {
 	}
 	panic("cannot use codec LeopardFF8 without the 'leopard' build tag")
 }
+
+// ErrTooManyChunks is returned for squares with more chunks than their codec
+// supports.
+type ErrTooManyChunks struct {
+	Len int
+	Max int
+}
+
+func (e *ErrTooManyChunks) Error() string {
+	return fmt.Sprintf("number of chunks %d exceeds the maximum of %d", e.Len, e.Max)
+}
+
+// MaxSquareWidth returns the width of the largest original data squares
+// codec can extend.
+func MaxSquareWidth(codec Codec) int {
+	return intSqrt(codec.maxChunks())
+}
+
+// FitsCodec checks that codec can extend an original data square of
+// numOriginalChunks chunks, so that block sizes can be validated before data
+// is chunked. It fails with ErrEmptySquare, ErrInvalidSquareLength or
+// ErrTooManyChunks.
+func FitsCodec(numOriginalChunks int, codec Codec) error {
+	if numOriginalChunks <= 0 {
+		return ErrEmptySquare
+	}
+	if numOriginalChunks > codec.maxChunks() {
+		return &ErrTooManyChunks{Len: numOriginalChunks, Max: codec.maxChunks()}
+	}
+	_, err := squareWidth(numOriginalChunks)
+	return err
+}
+
+// IsValidWidth reports whether codec can extend original data squares of
+// width n. Widths need not be powers of two.
+func IsValidWidth(n int, codec Codec) bool {
+	return n > 0 && n*n <= codec.maxChunks()
+}
+
+// ValidWidths returns all original data square widths supported by codec, in
+// increasing order.
+func ValidWidths(codec Codec) []int {
+	var widths []int
+	for n := 1; IsValidWidth(n, codec); n++ {
+		widths = append(widths, n)
+	}
+	return widths
+}
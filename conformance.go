@@ -0,0 +1,147 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// conformanceGoroutines is the number of goroutines CheckCodec uses the codec
+// from at once.
+const conformanceGoroutines = 8
+
+// CheckCodec checks that codec satisfies the contract of the Codec interface
+// for k data shares of chunkSize bytes: encoding is deterministic and leaves
+// its input unchanged, any k of the 2k shares decode to the data, and Encode
+// and Decode return the same results when called from several goroutines at
+// once. HealthCheck must succeed too. It is meant for the tests of codec
+// implementations; data races that do not corrupt results are only detected
+// when run with the race detector.
+func CheckCodec(codec Codec, k int, chunkSize int) error {
+	if !IsValidWidth(k, codec) {
+		return fmt.Errorf("width %d is not supported by the codec", k)
+	}
+	if err := codec.HealthCheck(); err != nil {
+		return err
+	}
+	rnd := rand.New(rand.NewSource(int64(k)))
+	inputs := make([][][]byte, conformanceGoroutines)
+	parities := make([][][]byte, conformanceGoroutines)
+	copies := make([][][]byte, conformanceGoroutines)
+	for i := range inputs {
+		inputs[i] = make([][]byte, k)
+		for j := range inputs[i] {
+			inputs[i][j] = make([]byte, chunkSize)
+			rnd.Read(inputs[i][j])
+		}
+		parity, err := checkEncode(codec, inputs[i])
+		if err != nil {
+			return err
+		}
+		again, err := checkEncode(codec, inputs[i])
+		if err != nil {
+			return err
+		}
+		if !equalChunks(parity, again) {
+			return errors.New("encoding the same data twice gave different parity")
+		}
+		if err := checkDecode(codec, inputs[i], parity, rnd.Perm(2 * k)[:k]); err != nil {
+			return err
+		}
+		parities[i] = parity
+		copies[i] = cloneChunks(parity)
+	}
+	// Results belong to the caller, so later calls must not overwrite them.
+	for i := range parities {
+		if !equalChunks(parities[i], copies[i]) {
+			return errors.New("codec is not safe for concurrent use: Encode overwrote an earlier result")
+		}
+	}
+
+	errs := make([]error, conformanceGoroutines)
+	var wg sync.WaitGroup
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for round := 0; round < 4 && errs[i] == nil; round++ {
+				parity, err := checkEncode(codec, inputs[i])
+				if err != nil {
+					errs[i] = err
+				} else if !equalChunks(parity, parities[i]) {
+					errs[i] = errors.New("concurrent Encode gave different parity")
+				} else {
+					erased := make([]int, k)
+					for j := range erased {
+						erased[j] = (i + round + j) % (2 * k)
+					}
+					errs[i] = checkDecode(codec, inputs[i], parities[i], erased)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("codec is not safe for concurrent use: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkEncode encodes data, checking that it is left unchanged, and returns
+// the parity shares.
+func checkEncode(codec Codec, data [][]byte) ([][]byte, error) {
+	input := cloneChunks(data)
+	shares, err := codec.Encode(input)
+	if err != nil {
+		return nil, err
+	}
+	if !equalChunks(input, data) {
+		return nil, errors.New("Encode modified its input")
+	}
+	// Codecs may return the data followed by the parity, or the parity only.
+	if len(shares) != len(data) && len(shares) != 2*len(data) {
+		return nil, fmt.Errorf("Encode returned %d shares for %d data shares", len(shares), len(data))
+	}
+	return shares[len(shares)-len(data):], nil
+}
+
+// checkDecode erases the shares at the given indices and checks that the rest
+// decode to data.
+func checkDecode(codec Codec, data [][]byte, parity [][]byte, erased []int) error {
+	shares := cloneChunks(append(append([][]byte(nil), data...), parity...))
+	for _, i := range erased {
+		shares[i] = nil
+	}
+	decoded, err := codec.Decode(shares)
+	if err != nil {
+		return err
+	}
+	if len(decoded) < len(data) || !equalChunks(decoded[:len(data)], data) {
+		return fmt.Errorf("shares with %v erased did not decode to the data", erased)
+	}
+	return nil
+}
+
+func equalChunks(a [][]byte, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneChunks(chunks [][]byte) [][]byte {
+	cp := make([][]byte, len(chunks))
+	for i := range chunks {
+		cp[i] = append([]byte(nil), chunks[i]...)
+	}
+	return cp
+}
@@ -0,0 +1,76 @@
+package rsmt2d
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/cpu"
+)
+
+// Kernel names reported by KernelReporter.
+const (
+	KernelGeneric = "generic"
+	KernelSSSE3   = "ssse3"
+	KernelAVX2    = "avx2"
+	KernelNEON    = "neon"
+)
+
+// KernelReporter is implemented by codecs that report the coding kernel their
+// backend dispatches to on this CPU, for observability: a node silently
+// running the generic kernel is far slower than its peers. This is reporting
+// only; rsmt2d does not select kernels itself.
+type KernelReporter interface {
+	// Kernel returns the name of the kernel selected at startup, one of
+	// the Kernel constants.
+	Kernel() string
+}
+
+// cpuFeatures are the SIMD extensions relevant to coding kernels, detected at
+// startup.
+var cpuFeatures = detectCPUFeatures()
+
+type cpuFeatureSet struct {
+	ssse3 bool
+	avx2  bool
+	neon  bool
+}
+
+func detectCPUFeatures() cpuFeatureSet {
+	return cpuFeatureSet{
+		ssse3: cpu.X86.HasSSSE3,
+		avx2:  cpu.X86.HasAVX2,
+		neon:  runtime.GOARCH == "arm64" && cpu.ARM64.HasASIMD,
+	}
+}
+
+// bestKernel returns the fastest of the kernels a codec backend implements
+// that the CPU supports, mirroring the backend's own dispatch.
+func bestKernel(features cpuFeatureSet, available ...string) string {
+	supported := map[string]bool{
+		KernelAVX2:  features.avx2,
+		KernelSSSE3: features.ssse3,
+		KernelNEON:  features.neon,
+	}
+	for _, kernel := range []string{KernelAVX2, KernelSSSE3, KernelNEON} {
+		if !supported[kernel] {
+			continue
+		}
+		for _, a := range available {
+			if a == kernel {
+				return kernel
+			}
+		}
+	}
+	return KernelGeneric
+}
+
+// CodecKernel returns the kernel codec dispatches to on this CPU, or
+// KernelGeneric if the codec does not report it.
+func CodecKernel(codec Codec) string {
+	if r, ok := codec.(KernelReporter); ok {
+		return r.Kernel()
+	}
+	if w, ok := codec.(interface{ Unwrap() Codec }); ok {
+		return CodecKernel(w.Unwrap())
+	}
+	return KernelGeneric
+}
@@ -0,0 +1,65 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/lazyledger/merkletree"
+)
+
+// ErrDataRootMismatch is returned when a square does not match the expected
+// data root.
+var ErrDataRootMismatch = errors.New("data root mismatch")
+
+// DataRoot returns the root committing to a whole square: the Merkle root,
+// computed with the default tree hasher, of the row roots followed by the
+// column roots.
+func DataRoot(rowRoots [][]byte, colRoots [][]byte) []byte {
+	tree := merkletree.New(sha256.New())
+	for _, r := range rowRoots {
+		tree.Push(r)
+	}
+	for _, c := range colRoots {
+		tree.Push(c)
+	}
+	return tree.Root()
+}
+
+// DataRoot returns the data root of the square.
+func (eds *ExtendedDataSquare) DataRoot() []byte {
+	return DataRoot(eds.getRowRoots(), eds.getColRoots())
+}
+
+// VerifyODS extends an original data square and checks that the resulting
+// square has the expected data root, returning ErrDataRootMismatch if not.
+// It lets stores that persist only original data check its integrity on read.
+func VerifyODS(ods [][]byte, dataRoot []byte, codec Codec, treeCreatorFn TreeConstructorFn) error {
+	eds, err := ComputeExtendedDataSquare(ods, codec, treeCreatorFn)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(eds.DataRoot(), dataRoot) {
+		return ErrDataRootMismatch
+	}
+	return nil
+}
+
+// proveAxisRoot builds an inclusion proof of a row or column root against
+// the data root computed from rowRoots and colRoots.
+func proveAxisRoot(rowRoots [][]byte, colRoots [][]byte, axis Axis, index uint) (Proof, error) {
+	if index >= uint(len(rowRoots)) {
+		return Proof{}, fmt.Errorf("%v %d out of range for width %d", axis, index, len(rowRoots))
+	}
+	leaf := index
+	if axis == ColAxis {
+		leaf += uint(len(rowRoots))
+	}
+
+	tree := NewDefaultTree().(ProvableTree)
+	for i, r := range append(append([][]byte(nil), rowRoots...), colRoots...) {
+		tree.Push(r, SquareIndex{Cell: uint(i)})
+	}
+	return tree.Prove(leaf)
+}
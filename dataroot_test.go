@@ -0,0 +1,25 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyODS(t *testing.T) {
+	codec := NewRSGF8Codec()
+	ods := genRandDS(4)
+	eds, err := ComputeExtendedDataSquare(ods, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	dataRoot := eds.DataRoot()
+
+	assert.NoError(t, VerifyODS(ods, dataRoot, codec, NewDefaultTree))
+
+	corrupted := append([][]byte(nil), ods...)
+	corrupted[5] = make([]byte, len(ods[5]))
+	assert.Equal(t, ErrDataRootMismatch, VerifyODS(corrupted, dataRoot, codec, NewDefaultTree))
+
+	assert.Error(t, VerifyODS(ods[:3], dataRoot, codec, NewDefaultTree))
+}
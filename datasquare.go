@@ -1,10 +1,50 @@
 package rsmt2d
 
 import (
+	"context"
 	"errors"
-	"math"
+	"fmt"
+	"sync"
 )
 
+// ErrInvalidChunkSize is returned when a chunk does not have the chunk size of
+// the square it is added to.
+var ErrInvalidChunkSize = errors.New("invalid chunk size")
+
+// ErrEmptySquare is returned when a square is created from zero chunks.
+var ErrEmptySquare = errors.New("square must contain at least one chunk")
+
+// ErrInvalidSquareLength is returned when the number of chunks given for a
+// square is not a perfect square.
+type ErrInvalidSquareLength struct {
+	Len int
+}
+
+func (e *ErrInvalidSquareLength) Error() string {
+	return fmt.Sprintf("number of chunks must be a square number, got %d", e.Len)
+}
+
+// squareWidth returns the width of a square of n chunks, computed exactly
+// with integer arithmetic, or ErrInvalidSquareLength if n is not a perfect
+// square.
+func squareWidth(n int) (int, error) {
+	x := intSqrt(n)
+	if x*x != n {
+		return 0, &ErrInvalidSquareLength{Len: n}
+	}
+	return x, nil
+}
+
+// intSqrt returns the integer square root of n, rounded down.
+func intSqrt(n int) int {
+	// Newton's method converges to the integer square root from above.
+	x := n
+	for y := (x + 1) / 2; y < x; y = (x + n/x) / 2 {
+		x = y
+	}
+	return x
+}
+
 // dataSquare stores all data for an original data square (ODS) or extended
 // data square (EDS). Data is duplicated in both row-major and column-major
 // order in order to be able to provide zero-allocation column slices.
@@ -15,43 +55,136 @@ type dataSquare struct {
 	chunkSize    uint
 	rowRoots     [][]byte
 	colRoots     [][]byte
+	rootsJob     *rootsJob
 	createTreeFn TreeConstructorFn
+	// copyOnWrite is set when the storage is shared with a snapshot and must
+	// be copied before it is modified.
+	copyOnWrite bool
+	// spill, if set, keeps only some quadrants in memory.
+	spill *spillStore
+	// hashingParallelism is the number of goroutines computing roots; see
+	// SetHashingParallelism.
+	hashingParallelism int
+	// executor runs parallel work; nil runs it on new goroutines.
+	executor Executor
+	// profileCtx holds the pprof labels of labelled operations; see
+	// SetProfilingContext.
+	profileCtx context.Context
+	// nodeCache, if set, keeps the nodes of the axis trees computed for the
+	// roots; see SetTreeCaching.
+	nodeCache *nodeCache
+	// validation, if set, checks the chunk sizes of a square built lazily;
+	// see ImportLazyValidation. It is shared with snapshots, which share the
+	// chunks it checks.
+	validation *lazyValidation
+	// missing, if set, marks the cells holding a placeholder chunk while the
+	// square is being repaired. Setting a cell clears its bit.
+	missing *bitMatrix
+	// provided, if set, holds the shares given for repair in row-major
+	// order, nil for missing cells, while the square is being repaired.
+	provided [][]byte
+}
+
+// nodeCache holds the nodes of the row and column trees of a square, for
+// trees implementing NodeCachingTree. Entries are nil until computed.
+type nodeCache struct {
+	rows [][][][]byte
+	cols [][][][]byte
+}
+
+func newNodeCache(width uint) *nodeCache {
+	return &nodeCache{
+		rows: make([][][][]byte, width),
+		cols: make([][][][]byte, width),
+	}
+}
+
+// lazyValidation checks the chunk sizes of a square once, even if several
+// goroutines first read the square concurrently.
+type lazyValidation struct {
+	once sync.Once
+	err  error
+}
+
+// rootsJob tracks a background computation of the row and column roots.
+type rootsJob struct {
+	done chan struct{}
+	err  error
 }
 
 func newDataSquare(data [][]byte, treeCreator TreeConstructorFn) (*dataSquare, error) {
-	width := int(math.Ceil(math.Sqrt(float64(len(data)))))
-	if width*width != len(data) {
-		return nil, errors.New("number of chunks must be a square number")
+	return buildDataSquare(data, treeCreator, 1, false)
+}
+
+// buildDataSquare lays out data in a new square, using up to workers
+// goroutines. Unless lazy is set, the sizes of all chunks are checked; lazy
+// squares are checked by validate instead.
+func buildDataSquare(data [][]byte, treeCreator TreeConstructorFn, workers int, lazy bool) (*dataSquare, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptySquare
+	}
+
+	width, err := squareWidth(len(data))
+	if err != nil {
+		return nil, err
 	}
 
 	chunkSize := len(data[0])
+	if chunkSize == 0 {
+		return nil, fmt.Errorf("%w: chunks must not be empty", ErrInvalidChunkSize)
+	}
 
 	squareRow := make([][][]byte, width)
 	for i := 0; i < width; i++ {
 		squareRow[i] = data[i*width : i*width+width]
+	}
+	ds := &dataSquare{
+		squareRow:    squareRow,
+		width:        uint(width),
+		chunkSize:    uint(chunkSize),
+		createTreeFn: treeCreator,
+	}
+	if lazy {
+		ds.validation = &lazyValidation{}
+	} else if err := parallelFor(nil, workers, ds.width, ds.validateRow); err != nil {
+		return nil, err
+	}
 
-		for j := 0; j < width; j++ {
-			if len(squareRow[i][j]) != chunkSize {
-				return nil, errors.New("all chunks must be of equal size")
-			}
+	ds.squareCol = make([][][]byte, width)
+	// The layout never fails, so neither does parallelFor.
+	_ = parallelFor(nil, workers, ds.width, func(j uint) error {
+		ds.squareCol[j] = make([][]byte, width)
+		for i := uint(0); i < ds.width; i++ {
+			ds.squareCol[j][i] = data[i*ds.width+j]
 		}
-	}
+		return nil
+	})
+	return ds, nil
+}
 
-	squareCol := make([][][]byte, width)
-	for j := 0; j < width; j++ {
-		squareCol[j] = make([][]byte, width)
-		for i := 0; i < width; i++ {
-			squareCol[j][i] = data[i*width+j]
+// validateRow checks the sizes of the chunks of row i.
+func (ds *dataSquare) validateRow(i uint) error {
+	for j, chunk := range ds.squareRow[i] {
+		if uint(len(chunk)) != ds.chunkSize {
+			return fmt.Errorf(
+				"%w: all chunks must be of equal size, chunk (%d, %d) has size %d, expected %d",
+				ErrInvalidChunkSize, i, j, len(chunk), ds.chunkSize,
+			)
 		}
 	}
+	return nil
+}
 
-	return &dataSquare{
-		squareRow:    squareRow,
-		squareCol:    squareCol,
-		width:        uint(width),
-		chunkSize:    uint(chunkSize),
-		createTreeFn: treeCreator,
-	}, nil
+// validate checks the sizes of the chunks of a square built lazily, once,
+// and returns the outcome of that check on every call.
+func (ds *dataSquare) validate() error {
+	if ds.validation == nil {
+		return nil
+	}
+	ds.validation.once.Do(func() {
+		ds.validation.err = parallelFor(ds.executor, ds.hashingWorkers(), ds.width, ds.validateRow)
+	})
+	return ds.validation.err
 }
 
 func (ds *dataSquare) extendSquare(extendedWidth uint, fillerChunk []byte) error {
@@ -94,6 +227,7 @@ func (ds *dataSquare) extendSquare(extendedWidth uint, fillerChunk []byte) error
 	}
 	ds.squareCol = newSquareCol
 	ds.width = newWidth
+	ds.copyOnWrite = false
 
 	ds.resetRoots()
 
@@ -101,6 +235,9 @@ func (ds *dataSquare) extendSquare(extendedWidth uint, fillerChunk []byte) error
 }
 
 func (ds *dataSquare) rowSlice(x uint, y uint, length uint) [][]byte {
+	if ds.spill != nil {
+		ds.spill.access(ds, x, y, x+1, y+length)
+	}
 	return ds.squareRow[x][y : y+length]
 }
 
@@ -113,13 +250,21 @@ func (ds *dataSquare) row(x uint) [][]byte {
 func (ds *dataSquare) setRowSlice(x uint, y uint, newRow [][]byte) error {
 	for i := uint(0); i < uint(len(newRow)); i++ {
 		if len(newRow[i]) != int(ds.chunkSize) {
-			return errors.New("invalid chunk size")
+			return fmt.Errorf(
+				"%w: chunk (%d, %d) of square width %d has size %d, expected %d",
+				ErrInvalidChunkSize, x, y+i, ds.width, len(newRow[i]), ds.chunkSize,
+			)
 		}
 	}
 
+	ds.prepareWrite()
+	if ds.spill != nil {
+		ds.spill.access(ds, x, y, x+1, y+uint(len(newRow)))
+	}
 	for i := uint(0); i < uint(len(newRow)); i++ {
 		ds.squareRow[x][y+i] = newRow[i]
 		ds.squareCol[y+i][x] = newRow[i]
+		ds.clearMissing(x, y+i)
 	}
 
 	ds.resetRoots()
@@ -127,7 +272,20 @@ func (ds *dataSquare) setRowSlice(x uint, y uint, newRow [][]byte) error {
 	return nil
 }
 
+// assignRowSlice stores newRow at (x, y) without any of the checks and
+// bookkeeping of setRowSlice. Distinct cells may be assigned concurrently.
+func (ds *dataSquare) assignRowSlice(x uint, y uint, newRow [][]byte) error {
+	for i := uint(0); i < uint(len(newRow)); i++ {
+		ds.squareRow[x][y+i] = newRow[i]
+		ds.squareCol[y+i][x] = newRow[i]
+	}
+	return nil
+}
+
 func (ds *dataSquare) colSlice(x uint, y uint, length uint) [][]byte {
+	if ds.spill != nil {
+		ds.spill.access(ds, x, y, x+length, y+1)
+	}
 	return ds.squareCol[y][x : x+length]
 }
 
@@ -140,13 +298,21 @@ func (ds *dataSquare) col(y uint) [][]byte {
 func (ds *dataSquare) setColSlice(x uint, y uint, newCol [][]byte) error {
 	for i := uint(0); i < uint(len(newCol)); i++ {
 		if len(newCol[i]) != int(ds.chunkSize) {
-			return errors.New("invalid chunk size")
+			return fmt.Errorf(
+				"%w: chunk (%d, %d) of square width %d has size %d, expected %d",
+				ErrInvalidChunkSize, x+i, y, ds.width, len(newCol[i]), ds.chunkSize,
+			)
 		}
 	}
 
+	ds.prepareWrite()
+	if ds.spill != nil {
+		ds.spill.access(ds, x, y, x+uint(len(newCol)), y+1)
+	}
 	for i := uint(0); i < uint(len(newCol)); i++ {
 		ds.squareRow[x+i][y] = newCol[i]
 		ds.squareCol[y][x+i] = newCol[i]
+		ds.clearMissing(x+i, y)
 	}
 
 	ds.resetRoots()
@@ -154,25 +320,98 @@ func (ds *dataSquare) setColSlice(x uint, y uint, newCol [][]byte) error {
 	return nil
 }
 
+// assignColSlice stores newCol at (x, y) without any of the checks and
+// bookkeeping of setColSlice. Distinct cells may be assigned concurrently.
+func (ds *dataSquare) assignColSlice(x uint, y uint, newCol [][]byte) error {
+	for i := uint(0); i < uint(len(newCol)); i++ {
+		ds.squareRow[x+i][y] = newCol[i]
+		ds.squareCol[y][x+i] = newCol[i]
+	}
+	return nil
+}
+
 func (ds *dataSquare) resetRoots() {
+	ds.waitRoots()
+	ds.rootsJob = nil
 	ds.rowRoots = nil
 	ds.colRoots = nil
+	if ds.nodeCache != nil {
+		ds.nodeCache = newNodeCache(ds.width)
+	}
 }
 
 func (ds *dataSquare) computeRoots() {
+	workers := ds.hashingParallelism
+	if ds.spill != nil {
+		workers = 1
+	}
 	rowRoots := make([][]byte, ds.width)
 	colRoots := make([][]byte, ds.width)
-	for i := uint(0); i < ds.width; i++ {
-		rowRoots[i] = ds.getRowRoot(i)
-		colRoots[i] = ds.getColRoot(i)
-	}
+	_ = parallelFor(ds.executor, workers, ds.width, func(i uint) error {
+		rowRoots[i] = ds.computeRowRoot(i)
+		colRoots[i] = ds.computeColRoot(i)
+		return nil
+	})
 
 	ds.rowRoots = rowRoots
 	ds.colRoots = colRoots
 }
 
+// precomputeRoots starts computing the row and column roots on background
+// goroutines. The square must not be modified until waitRoots returns.
+func (ds *dataSquare) precomputeRoots(ctx context.Context) {
+	if job := ds.rootsJob; job != nil {
+		select {
+		case <-job.done:
+			if job.err == nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+
+	job := &rootsJob{done: make(chan struct{})}
+	ds.rootsJob = job
+	if ds.rowRoots != nil && ds.colRoots != nil {
+		close(job.done)
+		return
+	}
+
+	go func() {
+		defer close(job.done)
+
+		rowRoots := make([][]byte, ds.width)
+		colRoots := make([][]byte, ds.width)
+		err := parallelFor(ds.executor, ds.hashingWorkers(), ds.width, func(i uint) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rowRoots[i] = ds.computeRowRoot(i)
+			colRoots[i] = ds.computeColRoot(i)
+			return nil
+		})
+		if err != nil {
+			job.err = err
+			return
+		}
+		ds.rowRoots = rowRoots
+		ds.colRoots = colRoots
+	}()
+}
+
+// waitRoots blocks until a background root computation, if any, has finished.
+func (ds *dataSquare) waitRoots() error {
+	if ds.rootsJob == nil {
+		return nil
+	}
+	<-ds.rootsJob.done
+	return ds.rootsJob.err
+}
+
 // getRowRoots returns the Merkle roots of all the rows in the square.
 func (ds *dataSquare) getRowRoots() [][]byte {
+	ds.waitRoots()
 	if ds.rowRoots == nil {
 		ds.computeRoots()
 	}
@@ -183,20 +422,36 @@ func (ds *dataSquare) getRowRoots() [][]byte {
 // getRowRoot calculates and returns the root of the selected row. Note: unlike the
 // getRowRoots method, getRowRoot uses the built-in cache when available.
 func (ds *dataSquare) getRowRoot(x uint) []byte {
+	ds.waitRoots()
 	if ds.rowRoots != nil {
 		return ds.rowRoots[x]
 	}
 
-	tree := ds.createTreeFn()
-	for i, d := range ds.row(x) {
-		tree.Push(d, SquareIndex{Cell: uint(i), Axis: x})
-	}
+	return ds.computeRowRoot(x)
+}
 
-	return tree.Root()
+// computeRowRoot calculates the root of the selected row, ignoring the cache.
+func (ds *dataSquare) computeRowRoot(x uint) (root []byte) {
+	profile(ds.profileCtx, "tree", nil, ds.width, func() {
+		tree := ds.createTreeFn()
+		if ds.spill == nil || !ds.spill.streamAxis(ds, tree, RowAxis, x) {
+			for i, d := range ds.row(x) {
+				tree.Push(d, SquareIndex{Cell: uint(i), Axis: x})
+			}
+		}
+		if cache := ds.nodeCache; cache != nil {
+			if nc, ok := tree.(NodeCachingTree); ok {
+				cache.rows[x] = nc.Nodes()
+			}
+		}
+		root = tree.Root()
+	})
+	return root
 }
 
 // getColRoots returns the Merkle roots of all the columns in the square.
 func (ds *dataSquare) getColRoots() [][]byte {
+	ds.waitRoots()
 	if ds.colRoots == nil {
 		ds.computeRoots()
 	}
@@ -207,35 +462,176 @@ func (ds *dataSquare) getColRoots() [][]byte {
 // getColRoot calculates and returns the root of the selected row. Note: unlike the
 // getColRoots method, getColRoot uses the built-in cache when available.
 func (ds *dataSquare) getColRoot(y uint) []byte {
+	ds.waitRoots()
 	if ds.colRoots != nil {
 		return ds.colRoots[y]
 	}
 
-	tree := ds.createTreeFn()
-	for i, d := range ds.col(y) {
-		tree.Push(d, SquareIndex{Axis: y, Cell: uint(i)})
+	return ds.computeColRoot(y)
+}
+
+// computeColRoot calculates the root of the selected column, ignoring the cache.
+func (ds *dataSquare) computeColRoot(y uint) (root []byte) {
+	profile(ds.profileCtx, "tree", nil, ds.width, func() {
+		tree := ds.createTreeFn()
+		if ds.spill == nil || !ds.spill.streamAxis(ds, tree, ColAxis, y) {
+			for i, d := range ds.col(y) {
+				tree.Push(d, SquareIndex{Axis: y, Cell: uint(i)})
+			}
+		}
+		if cache := ds.nodeCache; cache != nil {
+			if nc, ok := tree.(NodeCachingTree); ok {
+				cache.cols[y] = nc.Nodes()
+			}
+		}
+		root = tree.Root()
+	})
+	return root
+}
+
+// proveRowCell builds an inclusion proof of a cell against its row root.
+func (ds *dataSquare) proveRowCell(x uint, y uint) (Proof, error) {
+	tree, err := ds.provableRowTree(x)
+	if err != nil {
+		return Proof{}, err
+	}
+	return tree.Prove(y)
+}
+
+// provableRowTree returns a tree holding row x, from which proofs of several
+// cells of the row can be generated.
+func (ds *dataSquare) provableRowTree(x uint) (ProvableTree, error) {
+	tree, ok := ds.createTreeFn().(ProvableTree)
+	if !ok {
+		return nil, ErrTreeNotProvable
+	}
+	if cache := ds.nodeCache; cache != nil && cache.rows[x] != nil {
+		if nc, ok := tree.(NodeCachingTree); ok {
+			return nc, nc.FromNodes(cache.rows[x], ds.row(x))
+		}
+	}
+	for i, d := range ds.row(x) {
+		tree.Push(d, SquareIndex{Cell: uint(i), Axis: x})
 	}
+	return tree, nil
+}
 
-	return tree.Root()
+// proveColCell builds an inclusion proof of a cell against its column root.
+func (ds *dataSquare) proveColCell(x uint, y uint) (Proof, error) {
+	tree, err := ds.provableColTree(y)
+	if err != nil {
+		return Proof{}, err
+	}
+	return tree.Prove(x)
+}
+
+// provableColTree returns a tree holding column y, from which proofs of
+// several cells of the column can be generated.
+func (ds *dataSquare) provableColTree(y uint) (ProvableTree, error) {
+	tree, ok := ds.createTreeFn().(ProvableTree)
+	if !ok {
+		return nil, ErrTreeNotProvable
+	}
+	if cache := ds.nodeCache; cache != nil && cache.cols[y] != nil {
+		if nc, ok := tree.(NodeCachingTree); ok {
+			return nc, nc.FromNodes(cache.cols[y], ds.col(y))
+		}
+	}
+	for i, d := range ds.col(y) {
+		tree.Push(d, SquareIndex{Cell: uint(i), Axis: y})
+	}
+	return tree, nil
 }
 
 // getCell returns a single chunk at a specific cell.
 func (ds *dataSquare) getCell(x uint, y uint) []byte {
 	cell := make([]byte, ds.chunkSize)
-	copy(cell, ds.squareRow[x][y])
+	copy(cell, ds.rowSlice(x, y, 1)[0])
 	return cell
 }
 
 func (ds *dataSquare) setCell(x uint, y uint, newChunk []byte) {
+	ds.prepareWrite()
+	if ds.spill != nil {
+		ds.spill.access(ds, x, y, x+1, y+1)
+	}
 	ds.squareRow[x][y] = newChunk
 	ds.squareCol[y][x] = newChunk
+	ds.clearMissing(x, y)
 	ds.resetRoots()
 }
 
+// isMissing reports whether cell (x, y) holds a placeholder chunk.
+func (ds *dataSquare) isMissing(x uint, y uint) bool {
+	return ds.missing != nil && ds.missing.Get(int(x), int(y))
+}
+
+// clearMissing records that cell (x, y) holds real data.
+func (ds *dataSquare) clearMissing(x uint, y uint) {
+	if ds.missing != nil {
+		ds.missing.Clear(int(x), int(y))
+	}
+}
+
+// hideMissing replaces the placeholder chunks of the cells of an axis
+// starting at (x, y), returned by rowSlice or colSlice, with nil.
+func (ds *dataSquare) hideMissing(cells [][]byte, axis Axis, x uint, y uint) {
+	if ds.missing == nil {
+		return
+	}
+	for i := range cells {
+		if axis == RowAxis && ds.isMissing(x, y+uint(i)) || axis == ColAxis && ds.isMissing(x+uint(i), y) {
+			cells[i] = nil
+		}
+	}
+}
+
+// snapshot returns a copy of the square that shares its storage. The storage
+// is copied by whichever of the two squares is modified first, so that the
+// other keeps observing a consistent view.
+func (ds *dataSquare) snapshot() *dataSquare {
+	ds.waitRoots()
+	ds.copyOnWrite = true
+	cp := *ds
+	cp.rootsJob = nil
+	if ds.missing != nil {
+		missing := *ds.missing
+		missing.mask = append([]uint64(nil), ds.missing.mask...)
+		cp.missing = &missing
+	}
+	if ds.nodeCache != nil {
+		// The cached nodes are immutable, but the squares fill in missing
+		// entries independently.
+		cp.nodeCache = &nodeCache{
+			rows: append([][][][]byte(nil), ds.nodeCache.rows...),
+			cols: append([][][][]byte(nil), ds.nodeCache.cols...),
+		}
+	}
+	return &cp
+}
+
+// prepareWrite copies shared storage before the square is modified. Chunks
+// are never modified in place, so only the slices referencing them are copied.
+func (ds *dataSquare) prepareWrite() {
+	if !ds.copyOnWrite {
+		return
+	}
+
+	squareRow := make([][][]byte, ds.width)
+	squareCol := make([][][]byte, ds.width)
+	for i := uint(0); i < ds.width; i++ {
+		squareRow[i] = append([][]byte(nil), ds.squareRow[i]...)
+		squareCol[i] = append([][]byte(nil), ds.squareCol[i]...)
+	}
+	ds.squareRow = squareRow
+	ds.squareCol = squareCol
+	ds.copyOnWrite = false
+}
+
 func (ds *dataSquare) flattened() [][]byte {
 	flattened := [][]byte(nil)
-	for _, data := range ds.squareRow {
-		flattened = append(flattened, data...)
+	for i := uint(0); i < ds.width; i++ {
+		flattened = append(flattened, ds.row(i)...)
 	}
 
 	return flattened
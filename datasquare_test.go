@@ -1,6 +1,7 @@
 package rsmt2d
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -32,6 +33,11 @@ func TestNewDataSquare(t *testing.T) {
 	if err == nil {
 		t.Errorf("newDataSquare failed; chunks of unequal size accepted")
 	}
+
+	_, err = newDataSquare([][]byte{{}, {}, {}, {}}, NewDefaultTree)
+	if !errors.Is(err, ErrInvalidChunkSize) {
+		t.Errorf("newDataSquare failed; empty chunks accepted")
+	}
 }
 
 func TestExtendSquare(t *testing.T) {
@@ -200,3 +206,19 @@ func treeProve(d *DefaultTree, idx int) (merkleRoot []byte, proofSet [][]byte, p
 	}
 	return d.Tree.Prove()
 }
+
+func TestSquareWidth(t *testing.T) {
+	for _, n := range []int{1, 4, 9, 16, 1 << 20, (1<<31 - 1) * (1<<31 - 1)} {
+		width, err := squareWidth(n)
+		if err != nil || width*width != n {
+			t.Errorf("squareWidth(%d) = %d, %v", n, width, err)
+		}
+		if n > 1 {
+			_, err = squareWidth(n - 1)
+			var invalid *ErrInvalidSquareLength
+			if !errors.As(err, &invalid) || invalid.Len != n-1 {
+				t.Errorf("squareWidth(%d) returned %v, expected ErrInvalidSquareLength", n-1, err)
+			}
+		}
+	}
+}
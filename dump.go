@@ -0,0 +1,80 @@
+package rsmt2d
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// defaultDumpBytes is the number of leading bytes of each share listed by
+// Dump by default.
+const defaultDumpBytes = 16
+
+// DumpOptions configures the listing written by Dump.
+type DumpOptions struct {
+	// Cells lists the cells to dump, in order. All cells are dumped in
+	// row-major order if nil.
+	Cells []Coordinate
+	// Bytes is the number of leading bytes of each share written in hex,
+	// 16 if zero. Whole shares are written if negative.
+	Bytes int
+	// LeafHashes adds the hash of each share as a leaf of its row tree,
+	// which must implement NodeCachingTree.
+	LeafHashes bool
+}
+
+// Dump writes a human-readable listing of the square to w, for debugging
+// cells referenced by fraud proofs and errors. Each cell is listed on its own
+// line with its coordinates, quadrant (0 to 3 in row-major order, 0 being the
+// original data), optionally its leaf hash, and the leading bytes of its
+// share.
+func (eds *ExtendedDataSquare) Dump(w io.Writer, opts DumpOptions) error {
+	cells := opts.Cells
+	if cells == nil {
+		cells = make([]Coordinate, 0, eds.width*eds.width)
+		for r := uint(0); r < eds.width; r++ {
+			for c := uint(0); c < eds.width; c++ {
+				cells = append(cells, Coordinate{Row: r, Col: c})
+			}
+		}
+	}
+	n := opts.Bytes
+	if n == 0 {
+		n = defaultDumpBytes
+	}
+
+	codec := fmt.Sprintf("%T", eds.codec)
+	if name, err := codecName(eds.codec); err == nil {
+		codec = name
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "square width %d (original %d), chunk size %d, codec %s\n",
+		eds.width, eds.originalDataWidth, eds.chunkSize, codec)
+
+	leafHashes := make(map[uint][][]byte)
+	for _, coord := range cells {
+		if coord.Row >= eds.width || coord.Col >= eds.width {
+			return fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, eds.width)
+		}
+		fmt.Fprintf(bw, "(%d, %d) Q%d", coord.Row, coord.Col, QuadrantOf(eds.width, coord))
+
+		if opts.LeafHashes {
+			if leafHashes[coord.Row] == nil {
+				nodes, err := eds.AxisNodes(RowAxis, coord.Row)
+				if err != nil {
+					return err
+				}
+				leafHashes[coord.Row] = nodes[0]
+			}
+			fmt.Fprintf(bw, " leaf %x", leafHashes[coord.Row][coord.Col])
+		}
+
+		share := eds.getCell(coord.Row, coord.Col)
+		if n > 0 && len(share) > n {
+			fmt.Fprintf(bw, ": %x... (%d bytes)\n", share[:n], len(share))
+		} else {
+			fmt.Fprintf(bw, ": %x\n", share)
+		}
+	}
+	return bw.Flush()
+}
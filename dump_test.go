@@ -0,0 +1,36 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare([][]byte{
+		bytes.Repeat([]byte{1}, 20), bytes.Repeat([]byte{2}, 20),
+		bytes.Repeat([]byte{3}, 20), bytes.Repeat([]byte{4}, 20),
+	}, NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	var b strings.Builder
+	assert.NoError(t, eds.Dump(&b, DumpOptions{}))
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Len(t, lines, 17)
+	assert.Equal(t, "square width 4 (original 2), chunk size 20, codec RSGF8", lines[0])
+	assert.Equal(t, "(0, 1) Q0: "+strings.Repeat("02", 16)+"... (20 bytes)", lines[2])
+	assert.True(t, strings.HasPrefix(lines[15], "(3, 2) Q3: "))
+
+	b.Reset()
+	leaf := sha256.Sum256(append([]byte{0}, eds.getCell(1, 0)...))
+	assert.NoError(t, eds.Dump(&b, DumpOptions{Cells: []Coordinate{{Row: 1, Col: 0}}, Bytes: -1, LeafHashes: true}))
+	assert.Equal(t, fmt.Sprintf("(1, 0) Q0 leaf %x: %s\n", leaf, strings.Repeat("03", 20)), strings.SplitN(b.String(), "\n", 2)[1])
+
+	assert.Error(t, eds.Dump(&b, DumpOptions{Cells: []Coordinate{{Row: 4, Col: 0}}}))
+}
@@ -0,0 +1,119 @@
+package rsmt2d
+
+import (
+	"container/list"
+	"sync"
+)
+
+// EDSCache is a least-recently-used cache of extended data squares keyed by
+// data root. Concurrent requests for a square that is not cached share a
+// single computation. Cached squares are shared between callers and must not
+// be modified.
+type EDSCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	lru      *list.List // front is most recently used
+	entries  map[string]*list.Element
+	inflight map[string]*edsCall
+}
+
+type edsCacheEntry struct {
+	key  string
+	eds  *ExtendedDataSquare
+	size int
+}
+
+// edsCall is an in-flight computation of a square.
+type edsCall struct {
+	done chan struct{}
+	eds  *ExtendedDataSquare
+	err  error
+}
+
+// NewEDSCache returns a cache holding squares with a total share size of at
+// most maxBytes.
+func NewEDSCache(maxBytes int) *EDSCache {
+	return &EDSCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*edsCall),
+	}
+}
+
+// Get returns the cached square for dataRoot, if any.
+func (c *EDSCache) Get(dataRoot []byte) (*ExtendedDataSquare, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[string(dataRoot)]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*edsCacheEntry).eds, true
+}
+
+// GetOrCompute returns the cached square for dataRoot, calling compute to
+// produce and cache it on a miss. Concurrent callers for the same dataRoot
+// wait for a single call to compute.
+func (c *EDSCache) GetOrCompute(
+	dataRoot []byte,
+	compute func() (*ExtendedDataSquare, error),
+) (*ExtendedDataSquare, error) {
+	key := string(dataRoot)
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*edsCacheEntry).eds, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.eds, call.err
+	}
+	call := &edsCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	call.eds, call.err = compute()
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil {
+		c.add(key, call.eds)
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.eds, call.err
+}
+
+// Len returns the number of cached squares.
+func (c *EDSCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// add inserts a square and evicts the least recently used squares until the
+// cache fits. Squares larger than the whole cache are not stored.
+func (c *EDSCache) add(key string, eds *ExtendedDataSquare) {
+	size := int(eds.width * eds.width * eds.chunkSize)
+	if size > c.maxBytes {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&edsCacheEntry{key: key, eds: eds, size: size})
+	c.size += size
+
+	for c.size > c.maxBytes {
+		oldest := c.lru.Back()
+		entry := oldest.Value.(*edsCacheEntry)
+		c.lru.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+	}
+}
@@ -0,0 +1,67 @@
+package rsmt2d
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEDSCacheEviction(t *testing.T) {
+	codec := NewRSGF8Codec()
+	compute := func() (*ExtendedDataSquare, error) {
+		return ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	}
+
+	// Each square holds 4x4 shares of 256 bytes.
+	cache := NewEDSCache(2 * 16 * 256)
+	for _, key := range []string{"a", "b"} {
+		if _, err := cache.GetOrCompute([]byte(key), compute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, ok := cache.Get([]byte("a"))
+	assert.True(t, ok)
+
+	if _, err := cache.GetOrCompute([]byte("c"), compute); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get([]byte("b"))
+	assert.False(t, ok, "least recently used square was not evicted")
+	_, ok = cache.Get([]byte("a"))
+	assert.True(t, ok)
+}
+
+func TestEDSCacheSingleflight(t *testing.T) {
+	codec := NewRSGF8Codec()
+	cache := NewEDSCache(1 << 20)
+
+	var calls int32
+	start := make(chan struct{})
+	compute := func() (*ExtendedDataSquare, error) {
+		atomic.AddInt32(&calls, 1)
+		<-start
+		return ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*ExtendedDataSquare, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			eds, err := cache.GetOrCompute([]byte("root"), compute)
+			assert.NoError(t, err)
+			results[i] = eds
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, eds := range results {
+		assert.True(t, eds == results[0])
+	}
+}
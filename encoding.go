@@ -0,0 +1,110 @@
+package rsmt2d
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// errMalformedEncoding is returned when decoding truncated or corrupt input.
+var errMalformedEncoding = errors.New("malformed encoding")
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// appendBytes appends data prefixed with its length.
+func appendBytes(b []byte, data []byte) []byte {
+	b = appendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendProof(b []byte, proof Proof) []byte {
+	b = appendUvarint(b, proof.Index)
+	b = appendUvarint(b, proof.NumLeaves)
+	b = appendUvarint(b, uint64(len(proof.Set)))
+	for _, p := range proof.Set {
+		b = appendBytes(b, p)
+	}
+	return b
+}
+
+// decoder reads values written by the append helpers. The first failure is
+// recorded in err and all subsequent reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errMalformedEncoding
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// count reads a collection length, rejecting lengths that cannot possibly fit
+// in the remaining input.
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.err = errMalformedEncoding
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.count()
+	if d.err != nil {
+		return nil
+	}
+	data := make([]byte, n)
+	copy(data, d.buf[:n])
+	d.buf = d.buf[n:]
+	return data
+}
+
+func (d *decoder) proof() Proof {
+	proof := Proof{Index: d.uvarint(), NumLeaves: d.uvarint()}
+	proof.Set = make([][]byte, d.count())
+	for i := range proof.Set {
+		proof.Set[i] = d.bytes()
+	}
+	return proof
+}
+
+// finish returns the first decoding error, or an error if input remains.
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.buf) != 0 {
+		d.err = errMalformedEncoding
+	}
+	return d.err
+}
+
+func (d *decoder) axis() Axis {
+	switch v := d.uvarint(); v {
+	case uint64(RowAxis), uint64(ColAxis):
+		return Axis(v)
+	default:
+		if d.err == nil {
+			d.err = errMalformedEncoding
+		}
+		return RowAxis
+	}
+}
+
+func (d *decoder) cellProof() CellProof {
+	return CellProof{
+		Coord: Coordinate{Row: uint(d.uvarint()), Col: uint(d.uvarint())},
+		Axis:  d.axis(),
+		Proof: d.proof(),
+	}
+}
@@ -0,0 +1,129 @@
+package rsmt2d
+
+import "fmt"
+
+// ErasurePattern classifies why a set of erasures could not be repaired.
+type ErasurePattern int
+
+const (
+	// PatternUnknown is a pattern that matches none of the other classes.
+	PatternUnknown ErasurePattern = iota
+	// PatternNoShares means no shares at all were provided.
+	PatternNoShares
+	// PatternInsufficientShares means fewer shares than the size of the
+	// original data were available, so no decoder could succeed. This points
+	// to insufficient sampling.
+	PatternInsufficientShares
+	// PatternQuadrantMissing means an entire quadrant is missing.
+	PatternQuadrantMissing
+	// PatternWithholding means at least k+1 rows and k+1 columns each have
+	// fewer than k shares, the pattern produced by deliberate withholding.
+	PatternWithholding
+)
+
+func (p ErasurePattern) String() string {
+	switch p {
+	case PatternNoShares:
+		return "no shares available"
+	case PatternInsufficientShares:
+		return "fewer shares than original data"
+	case PatternQuadrantMissing:
+		return "quadrant entirely missing"
+	case PatternWithholding:
+		return "k+1 rows and k+1 columns below threshold"
+	default:
+		return "unclassified erasure pattern"
+	}
+}
+
+// ErrUnrepairable is returned when there are insufficient shares to repair the
+// square. It describes the erasures that remained when repair got stuck and
+// matches ErrUnrepairableDataSquare with errors.Is.
+type ErrUnrepairable struct {
+	Pattern         ErasurePattern
+	PresentCells    int   // Number of available cells
+	MissingCells    int   // Number of missing cells
+	IncompleteRows  int   // Rows with fewer than k available cells
+	IncompleteCols  int   // Columns with fewer than k available cells
+	MissingQuadrant []int // Quadrants without any available cell, in 0-3 row-major order
+
+	Width   int          // Width of the extended square
+	Missing []Coordinate // Missing cells, in row-major order
+
+	// MissingOriginal lists the cells of the original data quadrant that are
+	// still missing, in row-major order. These are the cells to request from
+	// peers to make the square repairable.
+	MissingOriginal []Coordinate
+}
+
+func (e *ErrUnrepairable) Error() string {
+	return fmt.Sprintf(
+		"%v: %v (square width %d, %d cells missing of which %d original, %d rows and %d columns below threshold)",
+		ErrUnrepairableDataSquare, e.Pattern, e.Width, e.MissingCells, len(e.MissingOriginal),
+		e.IncompleteRows, e.IncompleteCols,
+	)
+}
+
+// Coordinates returns the missing cells.
+func (e *ErrUnrepairable) Coordinates() []Coordinate {
+	return e.Missing
+}
+
+// Is reports whether target is ErrUnrepairableDataSquare.
+func (e *ErrUnrepairable) Is(target error) bool {
+	return target == ErrUnrepairableDataSquare
+}
+
+// classifyErasures describes the erasures of a square with original width k
+// whose available cells are set in bitMask.
+func classifyErasures(bitMask bitMatrix, k int) *ErrUnrepairable {
+	width := bitMask.squareSize
+	e := &ErrUnrepairable{Width: width}
+	for i := 0; i < width; i++ {
+		for j := 0; j < width; j++ {
+			if !bitMask.Get(i, j) {
+				e.Missing = append(e.Missing, Coordinate{Row: uint(i), Col: uint(j)})
+				if i < k && j < k {
+					e.MissingOriginal = append(e.MissingOriginal, Coordinate{Row: uint(i), Col: uint(j)})
+				}
+			}
+		}
+		present := bitMask.NumOnesInRow(i)
+		e.PresentCells += present
+		if present < k {
+			e.IncompleteRows++
+		}
+		if bitMask.NumOnesInCol(i) < k {
+			e.IncompleteCols++
+		}
+	}
+	e.MissingCells = width*width - e.PresentCells
+
+	for q := 0; q < 4; q++ {
+		r0, c0 := (q/2)*k, (q%2)*k
+		empty := true
+		for r := r0; r < r0+k && empty; r++ {
+			for c := c0; c < c0+k; c++ {
+				if bitMask.Get(r, c) {
+					empty = false
+					break
+				}
+			}
+		}
+		if empty {
+			e.MissingQuadrant = append(e.MissingQuadrant, q)
+		}
+	}
+
+	switch {
+	case e.PresentCells == 0:
+		e.Pattern = PatternNoShares
+	case e.PresentCells < k*k:
+		e.Pattern = PatternInsufficientShares
+	case len(e.MissingQuadrant) > 0:
+		e.Pattern = PatternQuadrantMissing
+	case e.IncompleteRows > k && e.IncompleteCols > k:
+		e.Pattern = PatternWithholding
+	}
+	return e
+}
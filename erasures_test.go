@@ -0,0 +1,75 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyErasures(t *testing.T) {
+	codec := NewRSGF8Codec()
+
+	// withheld is a 4x4 block of a 6x6 square not covering a whole quadrant.
+	var withheld []int
+	for _, r := range []int{0, 1, 3, 4} {
+		for _, c := range []int{0, 1, 3, 4} {
+			withheld = append(withheld, r*6+c)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		width   int
+		missing []int
+		pattern ErasurePattern
+	}{
+		{"no shares", 2, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, PatternNoShares},
+		{"insufficient", 2, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, PatternInsufficientShares},
+		{"quadrant", 2, []int{0, 1, 2, 4, 5, 6, 8, 9, 10}, PatternQuadrantMissing},
+		{"withholding", 3, withheld, PatternWithholding},
+	}
+	for _, tt := range tests {
+		original, err := ComputeExtendedDataSquare(genRandDS(tt.width), codec, NewDefaultTree)
+		if err != nil {
+			panic(err)
+		}
+		flattened := original.flattened()
+		for _, i := range tt.missing {
+			flattened[i] = nil
+		}
+		_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree)
+
+		var unrepairable *ErrUnrepairable
+		if !errors.As(err, &unrepairable) {
+			t.Errorf("%s: expected ErrUnrepairable, got %v", tt.name, err)
+			continue
+		}
+		assert.ErrorIs(t, err, ErrUnrepairableDataSquare, tt.name)
+		assert.Equal(t, tt.pattern, unrepairable.Pattern, tt.name)
+		assert.Equal(t, len(flattened), unrepairable.MissingCells+unrepairable.PresentCells, tt.name)
+		for _, coord := range unrepairable.MissingOriginal {
+			assert.True(t, coord.Row < uint(tt.width) && coord.Col < uint(tt.width), tt.name)
+			assert.Contains(t, unrepairable.Missing, coord, tt.name)
+		}
+	}
+}
+
+func TestUnrepairableMissingOriginal(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	for _, i := range []int{0, 1, 2, 4, 5, 6, 8, 9, 10} {
+		flattened[i] = nil
+	}
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree)
+
+	var unrepairable *ErrUnrepairable
+	if assert.True(t, errors.As(err, &unrepairable), "expected ErrUnrepairable, got %v", err) {
+		assert.Equal(t, []Coordinate{{0, 0}, {0, 1}, {1, 0}, {1, 1}}, unrepairable.MissingOriginal)
+		assert.Contains(t, err.Error(), "of which 4 original")
+	}
+}
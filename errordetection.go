@@ -0,0 +1,156 @@
+package rsmt2d
+
+import "bytes"
+
+// WithErrorDetection makes repair treat up to maxErrors shares of a row or
+// column as corrupted, rather than declaring the data Byzantine, when the
+// axis does not match its root. Subsets of the provided shares are excluded
+// in turn until the remaining shares decode to an axis matching the root; the
+// corrupted shares are then replaced with their correct values. The
+// coordinates of replaced shares are appended to corrected, if not nil.
+//
+// With codecs implementing ErrorCorrectingCodec, such as RSGF8, the
+// corrupted shares of an axis are first located with a single decode that
+// corrects errors. Otherwise, or if that fails, the number of decodes tried
+// for an axis grows with the number of its shares to the power of
+// maxErrors, so maxErrors should then be small.
+func WithErrorDetection(maxErrors int, corrected *[]Coordinate) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.maxErrors = maxErrors
+		cfg.correctedCells = corrected
+	}
+}
+
+// correctAxis looks for at most cfg.maxErrors corrupted shares among the
+// present (non-nil) shares of an axis, other than verified ones. If excluding
+// some of them yields an axis matching root, it returns the correct shares of
+// the whole axis and the positions of the corrupted shares.
+func (eds *ExtendedDataSquare) correctAxis(
+	axis Axis,
+	index uint,
+	shares [][]byte,
+	root []byte,
+	codec Codec,
+	cfg *repairConfig,
+) ([][]byte, []uint, bool) {
+	var present, suspects []uint
+	for i, s := range shares {
+		if s != nil {
+			present = append(present, uint(i))
+			if cfg.provenanceAt(axis, index, uint(i)) != ProvenanceVerified {
+				suspects = append(suspects, uint(i))
+			}
+		}
+	}
+
+	candidate := make([][]byte, len(shares))
+	var try func(start int, excluded int) ([][]byte, bool)
+	try = func(start int, excluded int) ([][]byte, bool) {
+		if excluded > 0 {
+			if rebuilt, ok := eds.decodeFullAxis(index, candidate, root, codec); ok {
+				return rebuilt, true
+			}
+		}
+		if excluded == cfg.maxErrors || len(present)-excluded <= int(eds.originalDataWidth) {
+			return nil, false
+		}
+		for i := start; i < len(suspects); i++ {
+			pos := suspects[i]
+			candidate[pos] = nil
+			rebuilt, ok := try(i+1, excluded+1)
+			candidate[pos] = shares[pos]
+			if ok {
+				return rebuilt, true
+			}
+		}
+		return nil, false
+	}
+
+	var rebuilt [][]byte
+	ok := false
+	if ec, isCorrecting := codec.(ErrorCorrectingCodec); isCorrecting {
+		rebuilt, ok = eds.correctFullAxis(index, shares, root, ec)
+	}
+	if !ok {
+		copy(candidate, shares)
+		if rebuilt, ok = try(0, 0); !ok {
+			return nil, nil, false
+		}
+	}
+
+	var corrupted []uint
+	for _, pos := range present {
+		if !bytes.Equal(shares[pos], rebuilt[pos]) {
+			if cfg.provenanceAt(axis, index, pos) == ProvenanceVerified {
+				return nil, nil, false
+			}
+			corrupted = append(corrupted, uint(pos))
+		}
+	}
+	if len(corrupted) > cfg.maxErrors {
+		return nil, nil, false
+	}
+	return rebuilt, corrupted, true
+}
+
+// decodeFullAxis decodes an axis from its present shares, re-encodes its
+// parity and reports whether the result matches root.
+func (eds *ExtendedDataSquare) decodeFullAxis(index uint, shares [][]byte, root []byte, codec Codec) ([][]byte, bool) {
+	rebuilt, isDecoded, err := eds.rebuildShares(true, shares, codec)
+	if err != nil || !isDecoded {
+		return nil, false
+	}
+	return rebuilt, bytes.Equal(eds.computeSharesRoot(rebuilt, index), root)
+}
+
+// correctFullAxis decodes an axis with a codec correcting errors, re-encodes
+// its parity and reports whether the result matches root.
+func (eds *ExtendedDataSquare) correctFullAxis(index uint, shares [][]byte, root []byte, codec ErrorCorrectingCodec) ([][]byte, bool) {
+	original, err := codec.Correct(shares)
+	if err != nil {
+		return nil, false
+	}
+	parity, err := codec.Encode(original)
+	if err != nil {
+		return nil, false
+	}
+	rebuilt := append(original, parity...)
+	return rebuilt, bytes.Equal(eds.computeSharesRoot(rebuilt, index), root)
+}
+
+// correctCompleteAxis attempts to correct a complete row or column that does
+// not match its root, writing the correct shares into the square.
+func (eds *ExtendedDataSquare) correctCompleteAxis(
+	axis Axis,
+	index uint,
+	root []byte,
+	codec Codec,
+	cfg *repairConfig,
+) bool {
+	var shares [][]byte
+	if axis == RowAxis {
+		shares = eds.Row(index)
+	} else {
+		shares = eds.Col(index)
+	}
+	rebuilt, corrupted, ok := eds.correctAxis(axis, index, shares, root, codec, cfg)
+	if !ok {
+		return false
+	}
+	for _, pos := range corrupted {
+		coord := Coordinate{Row: index, Col: pos}
+		if axis == ColAxis {
+			coord = Coordinate{Row: pos, Col: index}
+		}
+		eds.setCell(coord.Row, coord.Col, rebuilt[pos])
+		cfg.recordCorrected(coord)
+	}
+	return true
+}
+
+func (cfg *repairConfig) recordCorrected(coord Coordinate) {
+	cfg.provenance[coord.Row*cfg.width+coord.Col] = ProvenanceComputed
+	if cfg.correctedCells != nil {
+		*cfg.correctedCells = append(*cfg.correctedCells, coord)
+	}
+}
@@ -0,0 +1,84 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RepairEventKind identifies what happened in a RepairEvent.
+type RepairEventKind uint8
+
+const (
+	// AxisDecoded is emitted when the missing shares of a row or column
+	// have been decoded, before they are checked against its root.
+	AxisDecoded RepairEventKind = iota
+	// AxisVerified is emitted when a decoded row or column matches its root.
+	AxisVerified
+	// CellRecovered is emitted for every missing cell filled in from a
+	// verified row or column.
+	CellRecovered
+	// ByzantineDetected is emitted when repair fails because the shares or
+	// roots are inconsistent, just before the error is returned.
+	ByzantineDetected
+)
+
+func (k RepairEventKind) String() string {
+	switch k {
+	case AxisDecoded:
+		return "axis decoded"
+	case AxisVerified:
+		return "axis verified"
+	case CellRecovered:
+		return "cell recovered"
+	case ByzantineDetected:
+		return "byzantine detected"
+	default:
+		return fmt.Sprintf("RepairEventKind(%d)", uint8(k))
+	}
+}
+
+// RepairEvent describes a step of repair.
+type RepairEvent struct {
+	Kind  RepairEventKind
+	Axis  Axis       // Row or column, for AxisDecoded and AxisVerified
+	Index uint       // Index of the row or column, for AxisDecoded and AxisVerified
+	Coord Coordinate // Recovered cell, for CellRecovered
+	Err   error      // Error returned by repair, for ByzantineDetected
+}
+
+// WithRepairEvents makes repair call fn with every event, in order, from the
+// goroutine calling RepairExtendedDataSquare. Axis and cell events are only
+// emitted by the default crossword solver. fn must not modify the square.
+func WithRepairEvents(fn func(RepairEvent)) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.events = fn
+	}
+}
+
+// emit calls the event callback, if any.
+func (cfg *repairConfig) emit(event RepairEvent) {
+	if cfg.events != nil {
+		cfg.events(event)
+	}
+}
+
+// emitAxis emits an axis event.
+func (cfg *repairConfig) emitAxis(kind RepairEventKind, axis Axis, index uint) {
+	cfg.emit(RepairEvent{Kind: kind, Axis: axis, Index: index})
+}
+
+// emitByzantine emits ByzantineDetected if err shows that the shares or roots
+// are inconsistent.
+func (cfg *repairConfig) emitByzantine(err error) {
+	var (
+		byzRow   *ErrByzantineRow
+		byzCol   *ErrByzantineCol
+		conflict *ErrConflictingShare
+		root     *ErrRootMismatch
+		parity   *ErrParityInconsistency
+	)
+	if errors.As(err, &byzRow) || errors.As(err, &byzCol) || errors.As(err, &conflict) ||
+		errors.As(err, &root) || errors.As(err, &parity) {
+		cfg.emit(RepairEvent{Kind: ByzantineDetected, Err: err})
+	}
+}
@@ -0,0 +1,48 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairEvents(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := original.getRowRoots(), original.getColRoots()
+
+	// Only row 0 is incomplete, missing cells (0, 0) and (0, 3).
+	data := original.flattened()
+	data[0], data[3] = nil, nil
+
+	var events []RepairEvent
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree, WithRepairEvents(func(e RepairEvent) {
+		events = append(events, e)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []RepairEvent{
+		{Kind: AxisDecoded, Axis: RowAxis, Index: 0},
+		{Kind: AxisVerified, Axis: RowAxis, Index: 0},
+		{Kind: CellRecovered, Coord: Coordinate{Row: 0, Col: 0}},
+		{Kind: CellRecovered, Coord: Coordinate{Row: 0, Col: 3}},
+	}, events)
+
+	// A corrupted share in a complete row is detected before solving.
+	data = original.flattened()
+	data[0] = nil
+	data[5] = make([]byte, original.chunkSize)
+	events = nil
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree, WithRepairEvents(func(e RepairEvent) {
+		events = append(events, e)
+	}))
+	assert.Error(t, err)
+	if assert.NotEmpty(t, events) {
+		last := events[len(events)-1]
+		assert.Equal(t, ByzantineDetected, last.Kind)
+		assert.Equal(t, err, last.Err)
+	}
+	assert.Equal(t, "cell recovered", CellRecovered.String())
+}
@@ -2,9 +2,10 @@ package rsmt2d
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"math"
+	"time"
 )
 
 const (
@@ -12,27 +13,409 @@ const (
 	col
 )
 
+// Axis identifies whether an index refers to a row or a column.
+type Axis int
+
+const (
+	RowAxis Axis = row
+	ColAxis Axis = col
+)
+
+func (a Axis) String() string {
+	if a == ColAxis {
+		return "column"
+	}
+	return "row"
+}
+
+// MarshalText encodes the axis as "row" or "column".
+func (a Axis) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an axis encoded with MarshalText.
+func (a *Axis) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "row":
+		*a = RowAxis
+	case "column":
+		*a = ColAxis
+	default:
+		return fmt.Errorf("unknown axis %q", text)
+	}
+	return nil
+}
+
+// other returns the axis orthogonal to a.
+func (a Axis) other() Axis {
+	if a == ColAxis {
+		return RowAxis
+	}
+	return ColAxis
+}
+
 // ErrUnrepairableDataSquare is thrown when there is insufficient chunks to repair the square.
+// Repair returns it wrapped in an ErrUnrepairable describing the erasures.
 var ErrUnrepairableDataSquare = errors.New("failed to solve data square")
 
+// CoordinateError is implemented by errors concerning specific cells of a
+// square, so that callers can act on the cells without parsing messages.
+type CoordinateError interface {
+	error
+	Coordinates() []Coordinate
+}
+
+var (
+	_ CoordinateError = &ErrByzantineRow{}
+	_ CoordinateError = &ErrByzantineCol{}
+	_ CoordinateError = &ErrConflictingShare{}
+	_ CoordinateError = &ErrAxisTimeout{}
+	_ CoordinateError = &ErrAxisDecode{}
+	_ CoordinateError = &ErrParityInconsistency{}
+	_ CoordinateError = &ErrRootMismatch{}
+	_ CoordinateError = &ErrUnrepairable{}
+	_ CoordinateError = &ErrShareEquivocation{}
+)
+
+// axisCoordinates returns the coordinates of all cells of a row or column.
+func axisCoordinates(axis Axis, index uint, width uint) []Coordinate {
+	coords := make([]Coordinate, width)
+	for i := range coords {
+		if axis == RowAxis {
+			coords[i] = Coordinate{Row: index, Col: uint(i)}
+		} else {
+			coords[i] = Coordinate{Row: uint(i), Col: index}
+		}
+	}
+	return coords
+}
+
 // ErrByzantineRow is thrown when a repaired row does not match the expected row Merkle root.
 type ErrByzantineRow struct {
 	RowNumber uint     // Row index
 	Shares    [][]byte // Pre-repaired row shares. Missing shares are nil.
+	Sources   []string // Sources of the shares, if set with WithShareSources.
+	// Provenance of the shares. Missing shares are ProvenanceMissing.
+	Provenance []Provenance
 }
 
 func (e *ErrByzantineRow) Error() string {
-	return fmt.Sprintf("byzantine row: %d", e.RowNumber)
+	return fmt.Sprintf("byzantine row: %d (square width %d)", e.RowNumber, len(e.Shares))
+}
+
+// Coordinates returns the cells of the row.
+func (e *ErrByzantineRow) Coordinates() []Coordinate {
+	return axisCoordinates(RowAxis, e.RowNumber, uint(len(e.Shares)))
 }
 
 // ErrByzantineCol is thrown when a repaired column does not match the expected column Merkle root.
 type ErrByzantineCol struct {
 	ColNumber uint     // Column index
 	Shares    [][]byte // Pre-repaired column shares. Missing shares are nil.
+	Sources   []string // Sources of the shares, if set with WithShareSources.
+	// Provenance of the shares. Missing shares are ProvenanceMissing.
+	Provenance []Provenance
 }
 
 func (e *ErrByzantineCol) Error() string {
-	return fmt.Sprintf("byzantine column: %d", e.ColNumber)
+	return fmt.Sprintf("byzantine column: %d (square width %d)", e.ColNumber, len(e.Shares))
+}
+
+// Coordinates returns the cells of the column.
+func (e *ErrByzantineCol) Coordinates() []Coordinate {
+	return axisCoordinates(ColAxis, e.ColNumber, uint(len(e.Shares)))
+}
+
+// ErrRootMismatch is returned when a complete row or column provided for
+// repair does not match its expected root.
+type ErrRootMismatch struct {
+	Axis     Axis
+	Index    uint
+	Width    uint // Width of the square
+	Expected []byte
+	Computed []byte
+}
+
+func (e *ErrRootMismatch) Error() string {
+	return fmt.Sprintf(
+		"bad root input: %v %d of square width %d expected %X got %X",
+		e.Axis, e.Index, e.Width, e.Expected, e.Computed,
+	)
+}
+
+// Coordinates returns the cells of the row or column.
+func (e *ErrRootMismatch) Coordinates() []Coordinate {
+	return axisCoordinates(e.Axis, e.Index, e.Width)
+}
+
+// ErrConflictingShare is returned when a provided share differs from the
+// value reconstructed for its cell from shares matching the roots, which is
+// evidence that the share was supplied by a faulty or malicious party.
+type ErrConflictingShare struct {
+	Coord         Coordinate
+	Width         uint // Width of the square
+	Provided      []byte
+	Reconstructed []byte
+	Source        string // Source of the provided share, if set with WithShareSources.
+	Provenance    Provenance
+}
+
+func (e *ErrConflictingShare) Error() string {
+	return fmt.Sprintf(
+		"provided share at (%d, %d) of square width %d conflicts with reconstructed value",
+		e.Coord.Row, e.Coord.Col, e.Width,
+	)
+}
+
+// Coordinates returns the cell of the conflicting share.
+func (e *ErrConflictingShare) Coordinates() []Coordinate {
+	return []Coordinate{e.Coord}
+}
+
+// ErrParityInconsistency is returned by repair with WithParityChecks when a
+// row or column completed by repair is not a codeword of the codec, even if
+// it matches its root.
+type ErrParityInconsistency struct {
+	Axis  Axis
+	Index uint
+	Width uint // Width of the square
+}
+
+func (e *ErrParityInconsistency) Error() string {
+	return fmt.Sprintf("parity of %v %d of square width %d is inconsistent with its data", e.Axis, e.Index, e.Width)
+}
+
+// Coordinates returns the cells of the row or column.
+func (e *ErrParityInconsistency) Coordinates() []Coordinate {
+	return axisCoordinates(e.Axis, e.Index, e.Width)
+}
+
+// ErrAxisTimeout is returned when rebuilding a single row or column takes
+// longer than the budget set with WithAxisTimeout.
+type ErrAxisTimeout struct {
+	Axis   Axis
+	Index  uint
+	Width  uint // Width of the square
+	Budget time.Duration
+}
+
+func (e *ErrAxisTimeout) Error() string {
+	return fmt.Sprintf("rebuilding %v %d of square width %d exceeded budget of %v", e.Axis, e.Index, e.Width, e.Budget)
+}
+
+// Coordinates returns the cells of the row or column.
+func (e *ErrAxisTimeout) Coordinates() []Coordinate {
+	return axisCoordinates(e.Axis, e.Index, e.Width)
+}
+
+// ErrAxisDecode is returned when the codec fails to decode a row or column
+// for a reason that more shares cannot fix, such as ErrBackendFailure, which
+// aborts repair.
+type ErrAxisDecode struct {
+	Axis  Axis
+	Index uint
+	Width uint // Width of the square
+	Err   error
+}
+
+func (e *ErrAxisDecode) Error() string {
+	return fmt.Sprintf("decoding %v %d of square width %d: %v", e.Axis, e.Index, e.Width, e.Err)
+}
+
+func (e *ErrAxisDecode) Unwrap() error {
+	return e.Err
+}
+
+// Coordinates returns the cells of the row or column.
+func (e *ErrAxisDecode) Coordinates() []Coordinate {
+	return axisCoordinates(e.Axis, e.Index, e.Width)
+}
+
+// isFatalDecodeError reports whether a decode error cannot be fixed by
+// decoding again with more shares. Errors of codecs not following the
+// taxonomy of Codec are assumed not to be fatal.
+func isFatalDecodeError(err error) bool {
+	var backend *ErrBackendFailure
+	return errors.As(err, &backend) || errors.Is(err, ErrShardSizeMismatch)
+}
+
+// RepairOption configures optional behaviour of RepairExtendedDataSquare.
+type RepairOption func(*repairConfig)
+
+type repairConfig struct {
+	verifyAllRoots  bool
+	repairedCells   *[]RepairedCell
+	solver          Solver
+	globalDecoding  bool
+	axisTimeout     time.Duration
+	memoryLimit     int
+	maxErrors       int
+	correctedCells  *[]Coordinate
+	sources         []string
+	repairProofs    *[]CellProof
+	parityChecks    bool
+	verifiedAxes    [2]map[uint]bool // Indexed by Axis
+	verifiedCells   map[Coordinate]bool
+	width           uint
+	provenance      []Provenance // Provenance of every cell, in row-major order
+	provenanceOut   *[]Provenance
+	events          func(RepairEvent)
+	maxSuspectRoots int
+	suspectRoots    *[]SuspectRoot
+	trustedEncoding bool
+	profileCtx      context.Context
+}
+
+// RepairedCell is a cell that was reconstructed during repair.
+type RepairedCell struct {
+	Row   uint
+	Col   uint
+	Share []byte
+}
+
+// WithVerifyAllRoots makes repair re-verify every row and column of the solved
+// square against the provided roots, not only the ones touched while solving.
+func WithVerifyAllRoots() RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.verifyAllRoots = true
+	}
+}
+
+// WithRepairedCells makes repair append every cell it reconstructs to cells,
+// so that newly recovered shares can be forwarded without diffing squares.
+func WithRepairedCells(cells *[]RepairedCell) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.repairedCells = cells
+	}
+}
+
+// WithParityChecks makes repair check that every row or column completed by
+// a rebuilt orthogonal axis is a codeword of the codec, in addition to
+// matching its root, returning ErrParityInconsistency otherwise. This costs
+// one encoding per completed axis.
+func WithParityChecks() RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.parityChecks = true
+	}
+}
+
+// WithTrustedEncoding declares that the roots commit to a correctly extended
+// square, for example because they were accepted by consensus and the fraud
+// proof window has passed. Repair then skips re-encoding complete rows and
+// columns to check their parity, as matching their roots already binds it.
+// Without this option, such axes are re-encoded so that squares with bad
+// parity behind consistent roots are detected as Byzantine.
+func WithTrustedEncoding() RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.trustedEncoding = true
+	}
+}
+
+// WithVerifiedAxes marks rows or columns whose provided shares were already
+// verified against their roots, for instance because they arrived with
+// inclusion proofs. Repair then skips hashing those axes when they are
+// complete. Axes that need rebuilding are always checked, since their
+// rebuilt shares are not covered by the earlier verification.
+func WithVerifiedAxes(axis Axis, indices ...uint) RepairOption {
+	return func(cfg *repairConfig) {
+		if cfg.verifiedAxes[axis] == nil {
+			cfg.verifiedAxes[axis] = make(map[uint]bool, len(indices))
+		}
+		for _, i := range indices {
+			cfg.verifiedAxes[axis][i] = true
+		}
+	}
+}
+
+// WithSolver replaces the default crossword algorithm used to reconstruct
+// missing cells.
+func WithSolver(solver Solver) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.solver = solver
+	}
+}
+
+// WithGlobalDecoding makes the default solver fall back to decoding the
+// whole square jointly when the crossword algorithm gets stuck. This recovers
+// some erasure patterns that are otherwise unrepairable, at a cost that grows
+// with the sixth power of the original width, so it is only attempted for
+// original widths up to 16. It is only supported by the RSGF8 codec.
+func WithGlobalDecoding() RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.globalDecoding = true
+	}
+}
+
+// WithAxisTimeout bounds the time spent rebuilding any single row or column,
+// so that one pathological decode cannot stall the whole repair. Exceeding the
+// budget aborts repair with ErrAxisTimeout. The abandoned decode keeps running
+// in the background until the codec returns, so the codec must not be reused
+// concurrently before then.
+func WithAxisTimeout(budget time.Duration) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.axisTimeout = budget
+	}
+}
+
+// WithShareSources tags each provided share with an opaque identifier of
+// its source, such as the peer it was received from. sources is indexed like
+// the data passed to RepairExtendedDataSquare. Byzantine and conflicting
+// share errors then report the sources of the offending shares, for peer
+// scoring and banning.
+func WithShareSources(sources []string) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.sources = sources
+	}
+}
+
+// attributeError adds the sources and provenance of the offending shares to
+// err.
+func (cfg *repairConfig) attributeError(err error) error {
+	err = cfg.attributeSources(cfg.attributeProvenance(err), cfg.width)
+	cfg.emitByzantine(err)
+	return err
+}
+
+// attributeSources adds the sources of the offending shares to err.
+func (cfg *repairConfig) attributeSources(err error, width uint) error {
+	if cfg.sources == nil {
+		return err
+	}
+	var (
+		byzRow   *ErrByzantineRow
+		byzCol   *ErrByzantineCol
+		conflict *ErrConflictingShare
+	)
+	switch {
+	case errors.As(err, &byzRow):
+		byzRow.Sources = make([]string, width)
+		for c := uint(0); c < width; c++ {
+			if byzRow.Shares[c] != nil {
+				byzRow.Sources[c] = cfg.sources[byzRow.RowNumber*width+c]
+			}
+		}
+	case errors.As(err, &byzCol):
+		byzCol.Sources = make([]string, width)
+		for r := uint(0); r < width; r++ {
+			if byzCol.Shares[r] != nil {
+				byzCol.Sources[r] = cfg.sources[r*width+byzCol.ColNumber]
+			}
+		}
+	case errors.As(err, &conflict):
+		conflict.Source = cfg.sources[conflict.Coord.Row*width+conflict.Coord.Col]
+	}
+	return err
+}
+
+// WithMemoryLimit caps the memory held by the shares of the square while
+// solving at about maxBytes, spilling least recently used quadrants to a
+// temporary file. At least two quadrants are always kept in memory. The
+// square holds copies of the shares passed to RepairExtendedDataSquare, so
+// the memory of those shares is only freed if the caller drops them.
+func WithMemoryLimit(maxBytes int) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.memoryLimit = maxBytes
+	}
 }
 
 // RepairExtendedDataSquare attempts to repair an incomplete extended data
@@ -45,19 +428,35 @@ func (e *ErrByzantineCol) Error() string {
 //
 // Output
 //
-// The EDS is modified in-place. If repairing is successful, the EDS will be
-// complete. If repairing is unsuccessful, the EDS will be the most-repaired
-// prior to the Byzantine row or column being repaired, and the Byzantine row
-// or column prior to repair is returned in the error with missing shares as
-// nil.
+// The input data is never modified; the solver works on a private copy, so
+// concurrent readers of data observe a consistent view. If repairing is
+// successful, the returned EDS will be complete. If repairing is unsuccessful,
+// the Byzantine row or column prior to repair is returned in the error with
+// missing shares as nil.
 func RepairExtendedDataSquare(
 	rowRoots [][]byte,
 	colRoots [][]byte,
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+	opts ...RepairOption,
 ) (*ExtendedDataSquare, error) {
-	width := int(math.Ceil(math.Sqrt(float64(len(data)))))
+	var cfg repairConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrEmptySquare
+	}
+	if cfg.sources != nil && len(cfg.sources) != len(data) {
+		return nil, fmt.Errorf("got %d share sources for %d shares", len(cfg.sources), len(data))
+	}
+
+	width, err := squareWidth(len(data))
+	if err != nil {
+		return nil, err
+	}
 	bitMat := newBitMatrix(width)
 	var chunkSize int
 	for i := range data {
@@ -70,41 +469,142 @@ func RepairExtendedDataSquare(
 	}
 
 	if chunkSize == 0 {
-		return nil, ErrUnrepairableDataSquare
+		return nil, classifyErasures(bitMat, width/2)
 	}
 
-	fillerChunk := bytes.Repeat([]byte{0}, chunkSize)
-	for i := range data {
-		if data[i] == nil {
-			data[i] = make([]byte, chunkSize)
-			copy(data[i], fillerChunk)
+	cfg.initProvenance(data, uint(width))
+
+	// Work on a private copy so that the caller's slice is never modified.
+	// Missing cells hold a zero placeholder chunk, which is hidden from the
+	// accessors of the square until the cell is recovered.
+	padded := append([][]byte(nil), data...)
+	missing := newBitMatrix(width)
+	for i := range padded {
+		if padded[i] == nil {
+			padded[i] = make([]byte, chunkSize)
+			missing.SetFlat(i)
 		}
 	}
 
-	eds, err := ImportExtendedDataSquare(data, codec, treeCreatorFn)
+	eds, err := ImportExtendedDataSquare(padded, codec, treeCreatorFn)
 	if err != nil {
 		return nil, err
 	}
+	eds.missing = &missing
+	eds.provided = data
+	eds.profileCtx = cfg.profileCtx
 
-	err = eds.prerepairSanityCheck(rowRoots, colRoots, bitMat, codec)
+	solver := cfg.solver
+	if solver == nil {
+		solver = &crosswordSolver{cfg: &cfg}
+	}
+	isPresent := func(r, c uint) bool {
+		return bitMat.Get(int(r), int(c))
+	}
+	if cfg.maxSuspectRoots > 0 {
+		rowRoots = append([][]byte(nil), rowRoots...)
+		colRoots = append([][]byte(nil), colRoots...)
+	}
+	err = eds.withMemoryLimit(cfg.memoryLimit, func() error {
+		if cfg.maxSuspectRoots > 0 {
+			eds.resolveSuspectRoots(rowRoots, colRoots, bitMat, &cfg)
+		}
+		err := eds.prerepairSanityCheck(rowRoots, colRoots, bitMat, codec, &cfg)
+		if err != nil {
+			return err
+		}
+		return solver.Solve(eds, rowRoots, colRoots, codec, isPresent)
+	})
 	if err != nil {
-		return nil, err
+		return nil, cfg.attributeError(err)
 	}
+	eds.missing = nil
 
-	err = eds.solveCrossword(rowRoots, colRoots, bitMat, codec)
-	if err != nil {
-		return nil, err
+	if cfg.repairedCells != nil {
+		for r := uint(0); r < eds.width; r++ {
+			for c := uint(0); c < eds.width; c++ {
+				if !isPresent(r, c) {
+					*cfg.repairedCells = append(*cfg.repairedCells, RepairedCell{r, c, eds.squareRow[r][c]})
+				}
+			}
+		}
+	}
+
+	if cfg.verifyAllRoots {
+		err = eds.verifyAllRoots(rowRoots, colRoots)
+		if err != nil {
+			return nil, cfg.attributeError(err)
+		}
+	}
+
+	if cfg.repairProofs != nil {
+		err = eds.proveRepairedCells(rowRoots, isPresent, &cfg)
+		if err != nil {
+			return nil, cfg.attributeError(err)
+		}
 	}
 
+	cfg.finishProvenance()
+	eds.provided = nil
 	return eds, err
 }
 
+// verifyAllRoots checks every row and column of a solved EDS against the
+// expected roots.
+func (eds *ExtendedDataSquare) verifyAllRoots(rowRoots [][]byte, colRoots [][]byte) error {
+	computedRowRoots := eds.getRowRoots()
+	computedColRoots := eds.getColRoots()
+	for i := uint(0); i < eds.width; i++ {
+		if !bytes.Equal(computedRowRoots[i], rowRoots[i]) {
+			return eds.errByzantine(RowAxis, i)
+		}
+		if !bytes.Equal(computedColRoots[i], colRoots[i]) {
+			return eds.errByzantine(ColAxis, i)
+		}
+	}
+
+	return nil
+}
+
+// errByzantine returns an ErrByzantineRow or ErrByzantineCol for a repaired
+// row or column that does not match its root, carrying the shares provided
+// for repair rather than the repaired ones, which no root commits to.
+func (eds *ExtendedDataSquare) errByzantine(axis Axis, index uint) error {
+	shares := eds.providedShares(axis, index)
+	if axis == RowAxis {
+		return &ErrByzantineRow{RowNumber: index, Shares: shares}
+	}
+	return &ErrByzantineCol{ColNumber: index, Shares: shares}
+}
+
+// providedShares returns the shares of a row or column as provided for
+// repair, missing shares being nil. Every share of a square that is not
+// being repaired was provided.
+func (eds *ExtendedDataSquare) providedShares(axis Axis, index uint) [][]byte {
+	if eds.provided == nil {
+		if axis == RowAxis {
+			return eds.Row(index)
+		}
+		return eds.Col(index)
+	}
+	shares := make([][]byte, eds.width)
+	for i := range shares {
+		if axis == RowAxis {
+			shares[i] = eds.provided[index*eds.width+uint(i)]
+		} else {
+			shares[i] = eds.provided[uint(i)*eds.width+index]
+		}
+	}
+	return shares
+}
+
 // solveCrossword attempts to iteratively repair an EDS.
 func (eds *ExtendedDataSquare) solveCrossword(
 	rowRoots [][]byte,
 	colRoots [][]byte,
 	bitMask bitMatrix,
 	codec Codec,
+	cfg *repairConfig,
 ) error {
 	// Keep repeating until the square is solved
 	for {
@@ -115,11 +615,11 @@ func (eds *ExtendedDataSquare) solveCrossword(
 
 		// Loop through every row and column, attempt to rebuild each row or column if incomplete
 		for i := 0; i < int(eds.width); i++ {
-			solvedRow, progressMadeRow, err := eds.solveCrosswordRow(i, rowRoots, colRoots, bitMask, codec)
+			solvedRow, progressMadeRow, err := eds.solveCrosswordRow(i, rowRoots, colRoots, bitMask, codec, cfg)
 			if err != nil {
 				return err
 			}
-			solvedCol, progressMadeCol, err := eds.solveCrosswordCol(i, rowRoots, colRoots, bitMask, codec)
+			solvedCol, progressMadeCol, err := eds.solveCrosswordCol(i, rowRoots, colRoots, bitMask, codec, cfg)
 			if err != nil {
 				return err
 			}
@@ -132,7 +632,7 @@ func (eds *ExtendedDataSquare) solveCrossword(
 			break
 		}
 		if !progressMade {
-			return ErrUnrepairableDataSquare
+			return classifyErasures(bitMask, int(eds.originalDataWidth))
 		}
 	}
 
@@ -150,6 +650,7 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 	colRoots [][]byte,
 	bitMask bitMatrix,
 	codec Codec,
+	cfg *repairConfig,
 ) (bool, bool, error) {
 	isComplete := bitMask.RowIsOne(r)
 	if isComplete {
@@ -169,19 +670,27 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 
 	isExtendedPartIncomplete := !bitMask.RowRangeIsOne(r, int(eds.originalDataWidth), int(eds.width))
 	// Attempt rebuild
-	rebuiltShares, isDecoded, err := eds.rebuildShares(isExtendedPartIncomplete, shares, codec)
+	rebuiltShares, isDecoded, err := eds.rebuildAxis(RowAxis, uint(r), isExtendedPartIncomplete, shares, codec, cfg)
 	if err != nil {
 		return false, false, err
 	}
 	if !isDecoded {
 		return false, false, nil
 	}
+	cfg.emitAxis(AxisDecoded, RowAxis, uint(r))
 
 	// Check that rebuilt shares matches appropriate root
 	err = eds.verifyAgainstRowRoots(rowRoots, uint(r), bitMask, rebuiltShares)
+	if err != nil && cfg.maxErrors > 0 {
+		// Corrupted shares are recorded below, as conflicting shares.
+		if corrected, _, ok := eds.correctAxis(RowAxis, uint(r), shares, rowRoots[r], codec, cfg); ok {
+			rebuiltShares, err = corrected, nil
+		}
+	}
 	if err != nil {
 		return false, false, err
 	}
+	cfg.emitAxis(AxisVerified, RowAxis, uint(r))
 
 	// Check that newly completed orthogonal vectors match their new merkle roots
 	for c := 0; c < int(eds.width); c++ {
@@ -194,6 +703,16 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 		}
 	}
 
+	// Check that provided shares agree with the verified rebuilt shares
+	for c := 0; c < int(eds.width); c++ {
+		if shares[c] != nil && !bytes.Equal(shares[c], rebuiltShares[c]) {
+			coord := Coordinate{Row: uint(r), Col: uint(c)}
+			if err := cfg.conflict(coord, eds.width, shares[c], rebuiltShares[c]); err != nil {
+				return false, false, err
+			}
+		}
+	}
+
 	// Set vector mask to true
 	for c := 0; c < int(eds.width); c++ {
 		bitMask.Set(r, c)
@@ -202,6 +721,20 @@ func (eds *ExtendedDataSquare) solveCrosswordRow(
 	// Insert rebuilt shares into square.
 	for c, s := range rebuiltShares {
 		eds.setCell(uint(r), uint(c), s)
+		if shares[c] == nil {
+			cfg.emit(RepairEvent{Kind: CellRecovered, Coord: Coordinate{Row: uint(r), Col: uint(c)}})
+		}
+	}
+
+	// Check that the columns completed by the row are codewords
+	if cfg.parityChecks {
+		for c := 0; c < int(eds.width); c++ {
+			if shares[c] == nil && bitMask.ColIsOne(c) {
+				if err := eds.verifyParity(ColAxis, uint(c), codec); err != nil {
+					return false, false, err
+				}
+			}
+		}
 	}
 
 	return true, true, nil
@@ -218,6 +751,7 @@ func (eds *ExtendedDataSquare) solveCrosswordCol(
 	colRoots [][]byte,
 	bitMask bitMatrix,
 	codec Codec,
+	cfg *repairConfig,
 ) (bool, bool, error) {
 	isComplete := bitMask.ColIsOne(c)
 	if isComplete {
@@ -237,19 +771,27 @@ func (eds *ExtendedDataSquare) solveCrosswordCol(
 
 	isExtendedPartIncomplete := !bitMask.ColRangeIsOne(c, int(eds.originalDataWidth), int(eds.width))
 	// Attempt rebuild
-	rebuiltShares, isDecoded, err := eds.rebuildShares(isExtendedPartIncomplete, shares, codec)
+	rebuiltShares, isDecoded, err := eds.rebuildAxis(ColAxis, uint(c), isExtendedPartIncomplete, shares, codec, cfg)
 	if err != nil {
 		return false, false, err
 	}
 	if !isDecoded {
 		return false, false, nil
 	}
+	cfg.emitAxis(AxisDecoded, ColAxis, uint(c))
 
 	// Check that rebuilt shares matches appropriate root
 	err = eds.verifyAgainstColRoots(colRoots, uint(c), bitMask, rebuiltShares)
+	if err != nil && cfg.maxErrors > 0 {
+		// Corrupted shares are recorded below, as conflicting shares.
+		if corrected, _, ok := eds.correctAxis(ColAxis, uint(c), shares, colRoots[c], codec, cfg); ok {
+			rebuiltShares, err = corrected, nil
+		}
+	}
 	if err != nil {
 		return false, false, err
 	}
+	cfg.emitAxis(AxisVerified, ColAxis, uint(c))
 
 	// Check that newly completed orthogonal vectors match their new merkle roots
 	for r := 0; r < int(eds.width); r++ {
@@ -262,6 +804,16 @@ func (eds *ExtendedDataSquare) solveCrosswordCol(
 		}
 	}
 
+	// Check that provided shares agree with the verified rebuilt shares
+	for r := 0; r < int(eds.width); r++ {
+		if shares[r] != nil && !bytes.Equal(shares[r], rebuiltShares[r]) {
+			coord := Coordinate{Row: uint(r), Col: uint(c)}
+			if err := cfg.conflict(coord, eds.width, shares[r], rebuiltShares[r]); err != nil {
+				return false, false, err
+			}
+		}
+	}
+
 	// Set vector mask to true
 	for r := 0; r < int(eds.width); r++ {
 		bitMask.Set(r, c)
@@ -270,18 +822,128 @@ func (eds *ExtendedDataSquare) solveCrosswordCol(
 	// Insert rebuilt shares into square.
 	for r, s := range rebuiltShares {
 		eds.setCell(uint(r), uint(c), s)
+		if shares[r] == nil {
+			cfg.emit(RepairEvent{Kind: CellRecovered, Coord: Coordinate{Row: uint(r), Col: uint(c)}})
+		}
+	}
+
+	// Check that the rows completed by the column are codewords
+	if cfg.parityChecks {
+		for r := 0; r < int(eds.width); r++ {
+			if shares[r] == nil && bitMask.RowIsOne(r) {
+				if err := eds.verifyParity(RowAxis, uint(r), codec); err != nil {
+					return false, false, err
+				}
+			}
+		}
 	}
 
 	return true, true, nil
 }
 
+// conflict handles a provided share that differs from its verified
+// reconstruction. With error detection the share is treated as corrupted and
+// replaced, unless its proof was verified: a verified share is committed to
+// by a root, so disagreeing with it means the square is Byzantine. Otherwise
+// ErrConflictingShare is returned.
+func (cfg *repairConfig) conflict(coord Coordinate, width uint, provided []byte, reconstructed []byte) error {
+	if cfg.maxErrors > 0 && cfg.provenanceAt(RowAxis, coord.Row, coord.Col) != ProvenanceVerified {
+		cfg.recordCorrected(coord)
+		return nil
+	}
+	return &ErrConflictingShare{Coord: coord, Width: width, Provided: provided, Reconstructed: reconstructed}
+}
+
+// verifyParity checks that a row or column is a codeword of codec.
+func (eds *ExtendedDataSquare) verifyParity(axis Axis, index uint, codec Codec) error {
+	shares := eds.row(index)
+	if axis == ColAxis {
+		shares = eds.col(index)
+	}
+
+	k := eds.originalDataWidth
+	parity, err := codec.Encode(shares[:k])
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(flattenChunks(parity[len(parity)-int(k):]), flattenChunks(shares[k:])) {
+		return &ErrParityInconsistency{Axis: axis, Index: index, Width: eds.width}
+	}
+	return nil
+}
+
+// verifyAllParity checks that every row and column is a codeword of the codec
+// of the square.
+func (eds *ExtendedDataSquare) verifyAllParity() error {
+	return parallelFor(eds.executor, eds.codingParallelism, 2*eds.width, func(i uint) error {
+		if i < eds.width {
+			return eds.verifyParity(RowAxis, i, eds.codec)
+		}
+		return eds.verifyParity(ColAxis, i-eds.width, eds.codec)
+	})
+}
+
+// rebuildAxis rebuilds the shares of a row or column, enforcing the time
+// budget of the repair configuration.
+func (eds *ExtendedDataSquare) rebuildAxis(
+	axis Axis,
+	index uint,
+	isExtendedPartIncomplete bool,
+	shares [][]byte,
+	codec Codec,
+	cfg *repairConfig,
+) ([][]byte, bool, error) {
+	if cfg.axisTimeout <= 0 {
+		rebuiltShares, isDecoded, err := eds.rebuildShares(isExtendedPartIncomplete, shares, codec)
+		return rebuiltShares, isDecoded, eds.axisDecodeError(axis, index, err)
+	}
+
+	type result struct {
+		shares    [][]byte
+		isDecoded bool
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		rebuiltShares, isDecoded, err := eds.rebuildShares(isExtendedPartIncomplete, shares, codec)
+		done <- result{rebuiltShares, isDecoded, err}
+	}()
+
+	timer := time.NewTimer(cfg.axisTimeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.shares, res.isDecoded, eds.axisDecodeError(axis, index, res.err)
+	case <-timer.C:
+		return nil, false, &ErrAxisTimeout{Axis: axis, Index: index, Width: eds.width, Budget: cfg.axisTimeout}
+	}
+}
+
+// axisDecodeError adds the row or column to fatal decode errors.
+func (eds *ExtendedDataSquare) axisDecodeError(axis Axis, index uint, err error) error {
+	if err != nil && isFatalDecodeError(err) {
+		return &ErrAxisDecode{Axis: axis, Index: index, Width: eds.width, Err: err}
+	}
+	return err
+}
+
+// rebuildShares decodes an axis. Decode errors other than fatal ones, such
+// as ErrTooFewShards, are expected while solving and reported as the axis
+// not being decoded.
 func (eds *ExtendedDataSquare) rebuildShares(
 	isExtendedPartIncomplete bool,
 	shares [][]byte,
 	codec Codec,
 ) ([][]byte, bool, error) {
-	rebuiltShares, err := codec.Decode(shares)
+	var rebuiltShares [][]byte
+	var err error
+	profile(eds.profileCtx, "decode", codec, eds.width, func() {
+		rebuiltShares, err = codec.Decode(shares)
+	})
 	if err != nil {
+		if isFatalDecodeError(err) {
+			return nil, false, err
+		}
 		// repair unsuccessful
 		return nil, false, nil
 	}
@@ -323,7 +985,7 @@ func (eds *ExtendedDataSquare) verifyAgainstRowRoots(
 				shares[c] = nil
 			}
 		}
-		return &ErrByzantineRow{r, shares}
+		return &ErrByzantineRow{RowNumber: r, Shares: shares}
 	}
 
 	return nil
@@ -342,7 +1004,7 @@ func (eds *ExtendedDataSquare) verifyAgainstColRoots(
 				shares[r] = nil
 			}
 		}
-		return &ErrByzantineCol{c, shares}
+		return &ErrByzantineCol{ColNumber: c, Shares: shares}
 	}
 
 	return nil
@@ -353,44 +1015,57 @@ func (eds *ExtendedDataSquare) prerepairSanityCheck(
 	colRoots [][]byte,
 	bitMask bitMatrix,
 	codec Codec,
+	cfg *repairConfig,
 ) error {
 	for i := uint(0); i < eds.width; i++ {
 		rowIsComplete := bitMask.RowIsOne(int(i))
 		colIsComplete := bitMask.ColIsOne(int(i))
 
+		// With error detection, first try to correct complete axes that
+		// do not match their roots.
+		if cfg.maxErrors > 0 && rowIsComplete && !cfg.verifiedAxes[RowAxis][i] && !bytes.Equal(rowRoots[i], eds.computeRowRoot(i)) {
+			eds.correctCompleteAxis(RowAxis, i, rowRoots[i], codec, cfg)
+		}
+		if cfg.maxErrors > 0 && colIsComplete && !cfg.verifiedAxes[ColAxis][i] && !bytes.Equal(colRoots[i], eds.computeColRoot(i)) {
+			eds.correctCompleteAxis(ColAxis, i, colRoots[i], codec, cfg)
+		}
+
 		// if there's no missing data in the this row
-		if noMissingData(eds.row(i)) {
+		if noMissingData(eds.row(i)) && !cfg.verifiedAxes[RowAxis][i] {
 			// ensure that the roots are equal and that rowMask is a vector
 			if rowIsComplete && !bytes.Equal(rowRoots[i], eds.getRowRoot(i)) {
-				return fmt.Errorf("bad root input: row %d expected %v got %v", i, rowRoots[i], eds.getRowRoot(i))
+				return &ErrRootMismatch{Axis: RowAxis, Index: i, Width: eds.width, Expected: rowRoots[i], Computed: eds.getRowRoot(i)}
 			}
 		}
 
 		// if there's no missing data in the this col
-		if noMissingData(eds.col(i)) {
+		if noMissingData(eds.col(i)) && !cfg.verifiedAxes[ColAxis][i] {
 			// ensure that the roots are equal and that rowMask is a vector
 			if colIsComplete && !bytes.Equal(colRoots[i], eds.getColRoot(i)) {
-				return fmt.Errorf("bad root input: col %d expected %v got %v", i, colRoots[i], eds.getColRoot(i))
+				return &ErrRootMismatch{Axis: ColAxis, Index: i, Width: eds.width, Expected: colRoots[i], Computed: eds.getColRoot(i)}
 			}
 		}
 
-		if rowIsComplete {
+		// A complete axis matching a root of a correctly extended square
+		// has correct parity, so it is only re-encoded if the encoding is
+		// not trusted.
+		if rowIsComplete && !cfg.trustedEncoding {
 			parityShares, err := codec.Encode(eds.rowSlice(i, 0, eds.originalDataWidth))
 			if err != nil {
 				return err
 			}
 			if !bytes.Equal(flattenChunks(parityShares), flattenChunks(eds.rowSlice(i, eds.originalDataWidth, eds.originalDataWidth))) {
-				return &ErrByzantineRow{i, eds.row(i)}
+				return &ErrByzantineRow{RowNumber: i, Shares: eds.row(i)}
 			}
 		}
 
-		if colIsComplete {
+		if colIsComplete && !cfg.trustedEncoding {
 			parityShares, err := codec.Encode(eds.colSlice(0, i, eds.originalDataWidth))
 			if err != nil {
 				return err
 			}
 			if !bytes.Equal(flattenChunks(parityShares), flattenChunks(eds.colSlice(eds.originalDataWidth, i, eds.originalDataWidth))) {
-				return &ErrByzantineCol{i, eds.col(i)}
+				return &ErrByzantineCol{ColNumber: i, Shares: eds.col(i)}
 			}
 		}
 	}
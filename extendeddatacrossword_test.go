@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -141,6 +141,304 @@ func TestRepairExtendedDataSquare(t *testing.T) {
 	}
 }
 
+func TestRepairInvalidSquareLength(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	for _, n := range []int{2, 15, 17, 24} {
+		data := make([][]byte, n)
+		copy(data, eds.flattened())
+		_, err := RepairExtendedDataSquare(eds.RowRoots(), eds.ColRoots(), data, NewRSGF8Codec(), NewDefaultTree)
+		var invalid *ErrInvalidSquareLength
+		if assert.True(t, errors.As(err, &invalid), "%d shares: got %v", n, err) {
+			assert.Equal(t, n, invalid.Len)
+		}
+	}
+	// A square of missing shares of the wrong length is not classified.
+	_, err = RepairExtendedDataSquare(eds.RowRoots(), eds.ColRoots(), make([][]byte, 17), NewRSGF8Codec(), NewDefaultTree)
+	var invalid *ErrInvalidSquareLength
+	assert.True(t, errors.As(err, &invalid), "got %v", err)
+}
+
+func TestRepairVerifyAllRoots(t *testing.T) {
+	for codecName, codec := range codecs {
+		original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+		if err != nil {
+			panic(err)
+		}
+
+		flattened := original.flattened()
+		for i := 0; i < len(flattened); i += 3 {
+			flattened[i] = nil
+		}
+		result, err := RepairExtendedDataSquare(
+			original.getRowRoots(),
+			original.getColRoots(),
+			flattened,
+			codec,
+			NewDefaultTree,
+			WithVerifyAllRoots(),
+		)
+		if err != nil {
+			t.Errorf("unexpected err while repairing data square: %v, codec: :%s", err, codecName)
+			continue
+		}
+		assert.Equal(t, original.flattened(), result.flattened())
+		for i := 0; i < len(flattened); i += 3 {
+			assert.Nil(t, flattened[i], "repair modified its input")
+		}
+
+		wrongRoots := make([][]byte, len(original.getRowRoots()))
+		copy(wrongRoots, original.getRowRoots())
+		wrongRoots[1] = original.getRowRoot(0)
+		err = result.verifyAllRoots(wrongRoots, original.getColRoots())
+		var byzRow *ErrByzantineRow
+		if !errors.As(err, &byzRow) || byzRow.RowNumber != 1 {
+			t.Errorf("did not return a ErrByzantineRow for row 1; got %v", err)
+		}
+	}
+}
+
+func TestRepairedCells(t *testing.T) {
+	for codecName, codec := range codecs {
+		original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+		if err != nil {
+			panic(err)
+		}
+
+		flattened := original.flattened()
+		missing := map[int]bool{}
+		for i := 0; i < len(flattened); i += 3 {
+			flattened[i] = nil
+			missing[i] = true
+		}
+		var repaired []RepairedCell
+		_, err = RepairExtendedDataSquare(
+			original.getRowRoots(),
+			original.getColRoots(),
+			flattened,
+			codec,
+			NewDefaultTree,
+			WithRepairedCells(&repaired),
+		)
+		if err != nil {
+			t.Errorf("unexpected err while repairing data square: %v, codec: :%s", err, codecName)
+			continue
+		}
+
+		assert.Len(t, repaired, len(missing))
+		for _, cell := range repaired {
+			assert.True(t, missing[int(cell.Row*original.width+cell.Col)])
+			assert.Equal(t, original.getCell(cell.Row, cell.Col), cell.Share)
+		}
+	}
+}
+
+func TestRepairErrorDetection(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	flattened := original.flattened()
+	flattened[0*8+5] = nil
+	flattened[6*8+1] = nil
+	// Corrupt a share of an incomplete row and one in a complete row and column.
+	flattened[0*8+1] = bytes.Repeat([]byte{1}, int(original.chunkSize))
+	flattened[3*8+3] = bytes.Repeat([]byte{2}, int(original.chunkSize))
+
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree)
+	assert.Error(t, err)
+
+	var corrected []Coordinate
+	repaired, err := RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithErrorDetection(1, &corrected),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, original.flattened(), repaired.flattened())
+		assert.ElementsMatch(t, []Coordinate{{0, 1}, {3, 3}}, corrected)
+	}
+
+	// A 2x2 block of corrupted shares puts two errors in each affected axis,
+	// exceeding a budget of one error.
+	for _, i := range []int{0*8 + 2, 1*8 + 1, 1*8 + 2} {
+		flattened[i] = bytes.Repeat([]byte{3}, int(original.chunkSize))
+	}
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithErrorDetection(1, nil),
+	)
+	assert.Error(t, err)
+}
+
+func TestRepairConflictingShare(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	// Row 0 is rebuilt from its original shares, so its parity is
+	// re-encoded and the provided parity share at (0, 6) is never hashed.
+	flattened := original.flattened()
+	flattened[0*8+5] = nil
+	flattened[0*8+6] = bytes.Repeat([]byte{1}, int(original.chunkSize))
+	for r := 1; r < 8; r++ {
+		flattened[r*8+6] = nil
+	}
+
+	sources := make([]string, len(flattened))
+	for i := range sources {
+		sources[i] = fmt.Sprintf("peer-%d", i%3)
+	}
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithShareSources(sources),
+	)
+	var conflict *ErrConflictingShare
+	if assert.True(t, errors.As(err, &conflict), "expected ErrConflictingShare, got %v", err) {
+		assert.Equal(t, Coordinate{Row: 0, Col: 6}, conflict.Coord)
+		assert.Equal(t, flattened[0*8+6], conflict.Provided)
+		assert.Equal(t, original.getCell(0, 6), conflict.Reconstructed)
+		assert.Equal(t, "peer-0", conflict.Source)
+		assert.Equal(t, []Coordinate{{Row: 0, Col: 6}}, conflict.Coordinates())
+	}
+
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithShareSources(sources[1:]),
+	)
+	assert.Error(t, err)
+}
+
+func TestRepairByzantineSources(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	flattened := original.flattened()
+	flattened[0], flattened[13] = nil, nil
+	flattened[1] = bytes.Repeat([]byte{1}, int(original.chunkSize))
+	sources := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p"}
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithShareSources(sources),
+	)
+	var byzRow *ErrByzantineRow
+	var byzCol *ErrByzantineCol
+	switch {
+	case errors.As(err, &byzRow):
+		assert.Equal(t, []string{"", "b", "c", "d"}, byzRow.Sources)
+	case errors.As(err, &byzCol):
+		assert.Equal(t, []string{"b", "f", "j", ""}, byzCol.Sources)
+	default:
+		t.Errorf("expected a Byzantine error, got %v", err)
+	}
+}
+
+// slowCodec delays every Decode call.
+type slowCodec struct {
+	Codec
+	delay time.Duration
+}
+
+func (c slowCodec) Decode(data [][]byte) ([][]byte, error) {
+	time.Sleep(c.delay)
+	return c.Codec.Decode(data)
+}
+
+func TestRepairAxisTimeout(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[0] = nil
+
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		slowCodec{codec, 200 * time.Millisecond},
+		NewDefaultTree,
+		WithAxisTimeout(10*time.Millisecond),
+	)
+	var timeout *ErrAxisTimeout
+	if assert.True(t, errors.As(err, &timeout), "expected ErrAxisTimeout, got %v", err) {
+		assert.Equal(t, RowAxis, timeout.Axis)
+		assert.Equal(t, uint(0), timeout.Index)
+	}
+
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		slowCodec{NewRSGF8Codec(), time.Millisecond},
+		NewDefaultTree,
+		WithAxisTimeout(time.Second),
+	)
+	assert.NoError(t, err)
+}
+
+// failingCodec fails every Decode call with a backend failure.
+type failingCodec struct {
+	Codec
+	calls int
+}
+
+func (c *failingCodec) Decode(data [][]byte) ([][]byte, error) {
+	c.calls++
+	return nil, &ErrBackendFailure{Cause: errors.New("library not loaded")}
+}
+
+func TestRepairAbortsOnBackendFailure(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[0], flattened[9] = nil, nil
+
+	failing := &failingCodec{Codec: codec}
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), flattened, failing, NewDefaultTree)
+	var decodeErr *ErrAxisDecode
+	if assert.True(t, errors.As(err, &decodeErr), "expected ErrAxisDecode, got %v", err) {
+		assert.Equal(t, RowAxis, decodeErr.Axis)
+		assert.Equal(t, uint(0), decodeErr.Index)
+	}
+	var backend *ErrBackendFailure
+	assert.True(t, errors.As(err, &backend))
+	assert.False(t, errors.Is(err, ErrUnrepairableDataSquare))
+	assert.Equal(t, 1, failing.calls)
+}
+
 func BenchmarkRepair(b *testing.B) {
 	// For different ODS sizes
 	for originalDataWidth := 16; originalDataWidth <= 128; originalDataWidth *= 2 {
@@ -169,7 +467,7 @@ func BenchmarkRepair(b *testing.B) {
 						// Randomly remove 1/2 of the shares of each row
 						for r := 0; r < extendedDataWidth; r++ {
 							for c := 0; c < originalDataWidth; {
-								ind := rand.Intn(extendedDataWidth)
+								ind := testRand.Intn(extendedDataWidth)
 								if flattened[r*extendedDataWidth+ind] == nil {
 									continue
 								}
@@ -196,3 +494,138 @@ func BenchmarkRepair(b *testing.B) {
 		}
 	}
 }
+
+func TestErrorCoordinates(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	corrupted := original.flattened()
+	corrupted[1] = bytes.Repeat([]byte{1}, int(original.chunkSize))
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), corrupted, codec, NewDefaultTree)
+	var mismatch *ErrRootMismatch
+	if assert.True(t, errors.As(err, &mismatch), "expected ErrRootMismatch, got %v", err) {
+		assert.Equal(t, RowAxis, mismatch.Axis)
+		assert.Equal(t, uint(0), mismatch.Index)
+		assert.Equal(t, uint(4), mismatch.Width)
+		assert.Equal(t, original.getRowRoot(0), mismatch.Expected)
+	}
+	var coordErr CoordinateError
+	if assert.True(t, errors.As(err, &coordErr)) {
+		assert.Equal(t, []Coordinate{{0, 0}, {0, 1}, {0, 2}, {0, 3}}, coordErr.Coordinates())
+	}
+
+	missing := original.flattened()
+	for _, i := range []int{0, 1, 2, 4, 5, 6, 8, 9, 10} {
+		missing[i] = nil
+	}
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), missing, codec, NewDefaultTree)
+	if assert.True(t, errors.As(err, &coordErr), "expected a CoordinateError, got %v", err) {
+		assert.Equal(t, []Coordinate{
+			{0, 0}, {0, 1}, {0, 2},
+			{1, 0}, {1, 1}, {1, 2},
+			{2, 0}, {2, 1}, {2, 2},
+		}, coordErr.Coordinates())
+	}
+	assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+}
+
+func TestRepairParityChecks(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	// Cell (1, 0) is altered before committing to the roots, so that row 1
+	// and column 0 match their roots without being codewords.
+	flattened := original.flattened()
+	flattened[1*4+0] = bytes.Repeat([]byte{1}, int(original.chunkSize))
+	bad, err := ImportExtendedDataSquare(append([][]byte(nil), flattened...), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	// Rebuilding row 0 completes column 0.
+	flattened[0*4+0], flattened[1*4+1] = nil, nil
+	_, err = RepairExtendedDataSquare(
+		bad.RowRoots(),
+		bad.ColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithParityChecks(),
+	)
+	var inconsistent *ErrParityInconsistency
+	if assert.True(t, errors.As(err, &inconsistent), "expected ErrParityInconsistency, got %v", err) {
+		assert.Equal(t, ColAxis, inconsistent.Axis)
+		assert.Equal(t, uint(0), inconsistent.Index)
+	}
+}
+
+// countingTree counts the roots computed by all its instances.
+type countingTree struct {
+	Tree
+	roots *int
+}
+
+func (t countingTree) Root() []byte {
+	*t.roots++
+	return t.Tree.Root()
+}
+
+func TestRepairVerifiedAxes(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[0] = nil
+
+	var roots int
+	treeFn := func() Tree {
+		return countingTree{NewDefaultTree(), &roots}
+	}
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), flattened, codec, treeFn)
+	assert.NoError(t, err)
+	unverified := roots
+
+	roots = 0
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		treeFn,
+		WithVerifiedAxes(RowAxis, 1, 2, 3, 4, 5, 6, 7),
+		WithVerifiedAxes(ColAxis, 1, 2),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, unverified-9, roots)
+}
+
+func TestRepairTrustedEncoding(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	corrupted, err := original.deepCopy(codec)
+	if err != nil {
+		panic(err)
+	}
+	// Roots computed over a square with bad parity are consistent with it,
+	// so only re-encoding the complete axes detects the bad parity.
+	corrupted.setCell(0, 0, bytes.Repeat([]byte{66}, int(original.chunkSize)))
+	rowRoots, colRoots := corrupted.getRowRoots(), corrupted.getColRoots()
+
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, corrupted.flattened(), codec, NewDefaultTree)
+	var byzRow *ErrByzantineRow
+	assert.True(t, errors.As(err, &byzRow), "expected ErrByzantineRow, got %v", err)
+
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, corrupted.flattened(), codec, NewDefaultTree, WithTrustedEncoding())
+	assert.NoError(t, err)
+}
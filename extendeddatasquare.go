@@ -3,31 +3,76 @@ package rsmt2d
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
 )
 
 // ExtendedDataSquare represents an extended piece of data.
 type ExtendedDataSquare struct {
 	*dataSquare
 	originalDataWidth uint
+	codec             Codec
+	// codingParallelism is the number of workers encoding parity.
+	codingParallelism int
+	// parity is the buffer holding the parity quadrants written by a
+	// BufferedCodec, kept so that Reset can reuse it. It is dropped when the
+	// chunks it holds are shared with a snapshot.
+	parity []byte
+}
+
+// Coordinate identifies a cell of the square.
+type Coordinate struct {
+	Row uint `json:"row"`
+	Col uint `json:"col"`
 }
 
 // ComputeExtendedDataSquare computes the extended data square for some chunks of data.
+// A single chunk is extended to a 2x2 square in which every chunk is a copy of
+// the original. Zero chunks are rejected with ErrEmptySquare.
 func ComputeExtendedDataSquare(
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+	opts ...ExtendOption,
 ) (*ExtendedDataSquare, error) {
+	var cfg extendConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	if len(data) > codec.maxChunks() {
-		return nil, errors.New("number of chunks exceeds the maximum")
+		return nil, &ErrTooManyChunks{Len: len(data), Max: codec.maxChunks()}
 	}
 
 	ds, err := newDataSquare(data, treeCreatorFn)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkChunkSize(codec, ds.chunkSize); err != nil {
+		return nil, err
+	}
+
+	if cfg.autoParallelism && (cfg.codingParallelism == 0 || cfg.hashingParallelism == 0) {
+		t, err := autoParallelism(codec, ds.width, ds.chunkSize, treeCreatorFn)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.codingParallelism == 0 {
+			cfg.codingParallelism = t.coding
+		}
+		if cfg.hashingParallelism == 0 {
+			cfg.hashingParallelism = t.hashing
+		}
+	}
 
-	eds := ExtendedDataSquare{dataSquare: ds}
+	ds.hashingParallelism = cfg.hashingParallelism
+	ds.executor = cfg.executor
+	ds.profileCtx = cfg.profileCtx
+	eds := ExtendedDataSquare{dataSquare: ds, codec: codec, codingParallelism: cfg.codingParallelism}
 	err = eds.erasureExtendSquare(codec)
 	if err != nil {
 		return nil, err
@@ -37,23 +82,45 @@ func ComputeExtendedDataSquare(
 }
 
 // ImportExtendedDataSquare imports an extended data square, represented as flattened chunks of data.
+// The chunks are in row-major order, unless another ordering is set with ImportOrdering.
 func ImportExtendedDataSquare(
 	data [][]byte,
 	codec Codec,
 	treeCreatorFn TreeConstructorFn,
+	opts ...ImportOption,
 ) (*ExtendedDataSquare, error) {
+	var cfg importConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	if len(data) > 4*codec.maxChunks() {
-		return nil, errors.New("number of chunks exceeds the maximum")
+		return nil, &ErrTooManyChunks{Len: len(data), Max: 4 * codec.maxChunks()}
 	}
 
-	ds, err := newDataSquare(data, treeCreatorFn)
+	if cfg.ordering != RowMajor && len(data) > 0 {
+		var err error
+		if data, err = ReorderShares(data, cfg.ordering, RowMajor); err != nil {
+			return nil, err
+		}
+	}
+
+	ds, err := buildDataSquare(data, treeCreatorFn, cfg.parallelism, cfg.lazy)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkChunkSize(codec, ds.chunkSize); err != nil {
+		return nil, err
+	}
+	if cfg.checksums != nil {
+		if err := verifyChecksums(data, cfg.checksums, ds.width, cfg.ordering); err != nil {
+			return nil, err
+		}
+	}
 
-	eds := ExtendedDataSquare{dataSquare: ds}
+	eds := ExtendedDataSquare{dataSquare: ds, codec: codec}
 	if eds.width%2 != 0 {
-		return nil, errors.New("square width must be even")
+		return nil, fmt.Errorf("square width must be even, got %d", eds.width)
 	}
 
 	eds.originalDataWidth = eds.width / 2
@@ -67,8 +134,32 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 		return err
 	}
 
-	var shares [][]byte
 	var err error
+	profile(eds.profileCtx, "encode", codec, eds.width, func() {
+		err = eds.encodeParity(codec)
+	})
+	return err
+}
+
+// encodeParity computes all parity quadrants of the EDS from its original
+// data quadrant.
+func (eds *ExtendedDataSquare) encodeParity(codec Codec) error {
+	k := eds.originalDataWidth
+	encode := eds.parityEncoder(codec)
+
+	// Rows and columns are encoded independently, so with several workers
+	// the parity is written straight into the storage, bypassing the
+	// bookkeeping of setRowSlice and setColSlice that is done once here.
+	setRowSlice, setColSlice := eds.setRowSlice, eds.setColSlice
+	workers := eds.codingParallelism
+	if eds.spill != nil {
+		workers = 1
+	}
+	if workers > 1 {
+		eds.prepareWrite()
+		eds.resetRoots()
+		setRowSlice, setColSlice = eds.assignRowSlice, eds.assignColSlice
+	}
 
 	// Extend original square horizontally and vertically
 	//  ------- -------
@@ -80,24 +171,25 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   E   |
 	// |       |
 	//  -------
-	for i := uint(0); i < eds.originalDataWidth; i++ {
+	err := parallelFor(eds.executor, workers, k, func(i uint) error {
 		// Extend horizontally
-		shares, err = codec.Encode(eds.rowSlice(i, 0, eds.originalDataWidth))
+		shares, err := encode(eds.rowSlice(i, 0, k), i)
 		if err != nil {
 			return err
 		}
-		if err := eds.setRowSlice(i, eds.originalDataWidth, shares[len(shares)-int(eds.originalDataWidth):]); err != nil {
+		if err := setRowSlice(i, k, shares); err != nil {
 			return err
 		}
 
 		// Extend vertically
-		shares, err = codec.Encode(eds.colSlice(0, i, eds.originalDataWidth))
+		shares, err = encode(eds.colSlice(0, i, k), k+i)
 		if err != nil {
 			return err
 		}
-		if err := eds.setColSlice(eds.originalDataWidth, i, shares[len(shares)-int(eds.originalDataWidth):]); err != nil {
-			return err
-		}
+		return setColSlice(k, i, shares)
+	})
+	if err != nil {
+		return err
 	}
 
 	// Extend extended square horizontally
@@ -110,18 +202,153 @@ func (eds *ExtendedDataSquare) erasureExtendSquare(codec Codec) error {
 	// |   E → |   E   |
 	// |       |       |
 	//  ------- -------
-	for i := eds.originalDataWidth; i < eds.width; i++ {
+	return parallelFor(eds.executor, workers, k, func(i uint) error {
 		// Extend horizontally
-		shares, err = codec.Encode(eds.rowSlice(i, 0, eds.originalDataWidth))
+		shares, err := encode(eds.rowSlice(k+i, 0, k), 2*k+i)
 		if err != nil {
 			return err
 		}
-		if err := eds.setRowSlice(i, eds.originalDataWidth, shares[len(shares)-int(eds.originalDataWidth):]); err != nil {
+		return setRowSlice(k+i, k, shares)
+	})
+}
+
+// parityEncoder returns a function computing the parity chunks of an axis
+// half. Codecs implementing BufferedCodec write their output directly into a
+// single buffer holding all three parity quadrants, instead of allocating
+// the chunks of every axis separately. Each of the 3k axis halves encoded
+// must use a distinct slot, which selects its part of the buffer; the
+// function may then be called concurrently.
+func (eds *ExtendedDataSquare) parityEncoder(codec Codec) func(data [][]byte, slot uint) ([][]byte, error) {
+	buffered, ok := codec.(BufferedCodec)
+	if !ok {
+		return func(data [][]byte, _ uint) ([][]byte, error) {
+			shares, err := codec.Encode(data)
+			if err != nil {
+				return nil, err
+			}
+			return shares[len(shares)-len(data):], nil
+		}
+	}
+
+	chunkSize := int(eds.chunkSize)
+	k := int(eds.originalDataWidth)
+	storage := eds.parity
+	if len(storage) != 3*k*k*chunkSize {
+		storage = make([]byte, 3*k*k*chunkSize)
+		eds.parity = storage
+	}
+	return func(data [][]byte, slot uint) ([][]byte, error) {
+		shares := make([][]byte, len(data))
+		offset := int(slot) * k * chunkSize
+		for i := range shares {
+			shares[i] = storage[offset : offset+chunkSize : offset+chunkSize]
+			offset += chunkSize
+		}
+		if err := buffered.EncodeInto(shares, data); err != nil {
+			return nil, err
+		}
+		return shares, nil
+	}
+}
+
+// Reset replaces the original data of the square with original, given in
+// row-major order, and extends it again with the codec of the square. The
+// original data must have the same width as before. The storage of the
+// square, including the parity buffer of codecs implementing BufferedCodec,
+// is reused, so chunks previously obtained from the square must not be used
+// after calling Reset unless they were obtained from a snapshot.
+func (eds *ExtendedDataSquare) Reset(original [][]byte) error {
+	k := eds.originalDataWidth
+	if uint(len(original)) != k*k {
+		return fmt.Errorf("number of chunks must be %d for original width %d, got %d", k*k, k, len(original))
+	}
+	if eds.codec == nil {
+		return errors.New("square has no codec")
+	}
+	chunkSize := len(original[0])
+	if chunkSize == 0 {
+		return fmt.Errorf("%w: chunks must not be empty", ErrInvalidChunkSize)
+	}
+	if err := checkChunkSize(eds.codec, uint(chunkSize)); err != nil {
+		return err
+	}
+	for i, chunk := range original {
+		if len(chunk) != chunkSize {
+			return fmt.Errorf(
+				"%w: all chunks must be of equal size, chunk (%d, %d) has size %d, expected %d",
+				ErrInvalidChunkSize, uint(i)/k, uint(i)%k, len(chunk), chunkSize,
+			)
+		}
+	}
+
+	eds.waitRoots()
+	eds.chunkSize = uint(chunkSize)
+	for i := uint(0); i < k; i++ {
+		if err := eds.setRowSlice(i, 0, original[i*k:(i+1)*k]); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	var err error
+	profile(eds.profileCtx, "encode", eds.codec, eds.width, func() {
+		err = eds.encodeParity(eds.codec)
+	})
+	return err
+}
+
+// Grow enlarges the original data of the square to width, for squares that
+// are still being filled when more data arrives. The existing original chunks
+// keep their coordinates, and the cells added to the original data are
+// filled in row-major order with data, padded with zero chunks when data is
+// too short. Since every row and column of the grown square gains original
+// chunks, all parity and roots are recomputed.
+//
+// If the codec of the square does not support the new width, the square is
+// switched to a codec that does where one is known, such as from Leopard FF8
+// to FF16. Codec returns the codec in use, and the codec name recorded by
+// WriteODS and Roots changes accordingly.
+func (eds *ExtendedDataSquare) Grow(width uint, data [][]byte) error {
+	k := eds.originalDataWidth
+	if width <= k {
+		return fmt.Errorf("new width %d must exceed the original width %d", width, k)
+	}
+	if eds.codec == nil {
+		return errors.New("square has no codec")
+	}
+	codec, err := promoteCodec(eds.codec, int(width))
+	if err != nil {
+		return err
+	}
+	if added := width*width - k*k; uint(len(data)) > added {
+		return fmt.Errorf("number of chunks %d exceeds the %d cells added", len(data), added)
+	}
+
+	padding := make([]byte, eds.chunkSize)
+	original := make([][]byte, 0, width*width)
+	for i := uint(0); i < width; i++ {
+		for j := uint(0); j < width; j++ {
+			switch {
+			case i < k && j < k:
+				original = append(original, eds.getCell(i, j))
+			case len(data) > 0:
+				original = append(original, data[0])
+				data = data[1:]
+			default:
+				original = append(original, padding)
+			}
+		}
+	}
+
+	eds.waitRoots()
+	ds, err := newDataSquare(original, eds.createTreeFn)
+	if err != nil {
+		return err
+	}
+	ds.hashingParallelism = eds.hashingParallelism
+	ds.executor = eds.executor
+	eds.dataSquare = ds
+	eds.codec = codec
+	return eds.erasureExtendSquare(codec)
 }
 
 func (eds *ExtendedDataSquare) deepCopy(codec Codec) (ExtendedDataSquare, error) {
@@ -130,10 +357,14 @@ func (eds *ExtendedDataSquare) deepCopy(codec Codec) (ExtendedDataSquare, error)
 }
 
 // Col returns a column slice.
-// This slice is a copy of the internal column slice.
+// This slice is a copy of the internal column slice. Cells not recovered yet
+// by repair are nil. Columns are stored column-major alongside the rows, so
+// Col costs the same as Row: one allocation of width pointers, without
+// gathering them from every row.
 func (eds *ExtendedDataSquare) Col(y uint) [][]byte {
 	s := make([][]byte, eds.width)
 	copy(s, eds.colSlice(0, y, eds.width))
+	eds.hideMissing(s, ColAxis, 0, y)
 	return s
 }
 
@@ -143,19 +374,210 @@ func (eds *ExtendedDataSquare) ColRoots() [][]byte {
 }
 
 // Row returns a row slice.
-// This slice is a copy of the internal row slice.
+// This slice is a copy of the internal row slice. Cells not recovered yet by
+// repair are nil.
 func (eds *ExtendedDataSquare) Row(x uint) [][]byte {
 	s := make([][]byte, eds.width)
 	copy(s, eds.rowSlice(x, 0, eds.width))
+	eds.hideMissing(s, RowAxis, x, 0)
 	return s
 }
 
+// IsMissing reports whether cell (row, col) is still missing: the square is
+// being repaired, by a Solver, and the cell has not been recovered yet. A
+// square returned by RepairExtendedDataSquare has no missing cells.
+func (eds *ExtendedDataSquare) IsMissing(row uint, col uint) bool {
+	return row < eds.width && col < eds.width && eds.isMissing(row, col)
+}
+
 // RowRoots returns the Merkle roots of all the rows in the square.
 func (eds *ExtendedDataSquare) RowRoots() [][]byte {
 	return eds.getRowRoots()
 }
 
+// ProveCell returns an inclusion proof of a cell against its row root.
+func (eds *ExtendedDataSquare) ProveCell(row uint, col uint) (Proof, error) {
+	if row >= eds.width || col >= eds.width {
+		return Proof{}, fmt.Errorf("cell (%d, %d) out of range for width %d", row, col, eds.width)
+	}
+	return eds.proveRowCell(row, col)
+}
+
+// ProveRowCells returns inclusion proofs of the given cells of a row against
+// its row root. The row tree is built once and shared by all proofs, which is
+// considerably cheaper than calling ProveCell for each cell.
+func (eds *ExtendedDataSquare) ProveRowCells(row uint, cols []uint) ([]Proof, error) {
+	if row >= eds.width {
+		return nil, fmt.Errorf("row %d out of range for width %d", row, eds.width)
+	}
+	tree, err := eds.provableRowTree(row)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]Proof, len(cols))
+	for i, col := range cols {
+		if col >= eds.width {
+			return nil, fmt.Errorf("cell (%d, %d) out of range for width %d", row, col, eds.width)
+		}
+		if proofs[i], err = tree.Prove(col); err != nil {
+			return nil, err
+		}
+	}
+	return proofs, nil
+}
+
+// ProveColumnCells returns inclusion proofs of the given cells of a column
+// against its column root, sharing one column tree like ProveRowCells.
+func (eds *ExtendedDataSquare) ProveColumnCells(col uint, rows []uint) ([]Proof, error) {
+	if col >= eds.width {
+		return nil, fmt.Errorf("column %d out of range for width %d", col, eds.width)
+	}
+	tree, err := eds.provableColTree(col)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]Proof, len(rows))
+	for i, row := range rows {
+		if row >= eds.width {
+			return nil, fmt.Errorf("cell (%d, %d) out of range for width %d", row, col, eds.width)
+		}
+		if proofs[i], err = tree.Prove(row); err != nil {
+			return nil, err
+		}
+	}
+	return proofs, nil
+}
+
+// PrecomputeRoots starts computing the row and column roots of the square on
+// background goroutines, so that hashing can overlap with other work. The
+// square must not be modified until WaitRoots returns.
+func (eds *ExtendedDataSquare) PrecomputeRoots(ctx context.Context) {
+	eds.precomputeRoots(ctx)
+}
+
+// WaitRoots blocks until the computation started by PrecomputeRoots has
+// finished. It returns the context's error if the computation was cancelled,
+// in which case the roots are computed on demand instead.
+func (eds *ExtendedDataSquare) WaitRoots() error {
+	return eds.waitRoots()
+}
+
+// SetTreeCaching sets whether the nodes of the row and column trees built
+// to compute the roots are kept, for trees implementing NodeCachingTree.
+// Proofs are then generated from the cached nodes instead of hashing the
+// whole row or column again, at the cost of keeping about two hashes per
+// share in memory.
+func (eds *ExtendedDataSquare) SetTreeCaching(enabled bool) {
+	eds.waitRoots()
+	if !enabled {
+		eds.nodeCache = nil
+	} else if eds.nodeCache == nil {
+		eds.nodeCache = newNodeCache(eds.width)
+		// Roots computed before caching was enabled have no cached nodes.
+		eds.rowRoots, eds.colRoots = nil, nil
+	}
+}
+
+// Snapshot returns a copy of the square that remains unchanged when the
+// original is modified, and vice versa. Storage is shared until the first
+// modification, so taking a snapshot is cheap.
+func (eds *ExtendedDataSquare) Snapshot() *ExtendedDataSquare {
+	// The parity chunks are now shared and must not be overwritten by Reset.
+	eds.parity = nil
+	return &ExtendedDataSquare{
+		dataSquare:        eds.snapshot(),
+		originalDataWidth: eds.originalDataWidth,
+		codec:             eds.codec,
+		codingParallelism: eds.codingParallelism,
+	}
+}
+
+// Validate checks that all chunks of the square have the same size. Only
+// squares imported with ImportLazyValidation may fail it; it is a no-op once
+// the square has been validated.
+func (eds *ExtendedDataSquare) Validate() error {
+	return eds.validate()
+}
+
+// ErrCellMissing is returned by GetCell for a cell that has not been
+// recovered yet by repair.
+type ErrCellMissing struct {
+	Coord Coordinate
+}
+
+func (e *ErrCellMissing) Error() string {
+	return fmt.Sprintf("cell (%d, %d) is missing", e.Coord.Row, e.Coord.Col)
+}
+
+// GetCell returns a copy of the chunk at a cell. It fails with
+// ErrCellMissing rather than returning placeholder data if the cell has not
+// been recovered yet, see IsMissing.
+func (eds *ExtendedDataSquare) GetCell(row uint, col uint) ([]byte, error) {
+	if row >= eds.width || col >= eds.width {
+		return nil, fmt.Errorf("cell (%d, %d) out of range for width %d", row, col, eds.width)
+	}
+	if eds.isMissing(row, col) {
+		return nil, &ErrCellMissing{Coord: Coordinate{Row: row, Col: col}}
+	}
+	return eds.getCell(row, col), nil
+}
+
+// SetCell replaces the chunk at a cell, rejecting chunks whose size differs
+// from the chunk size of the square.
+func (eds *ExtendedDataSquare) SetCell(row uint, col uint, chunk []byte) error {
+	if row >= eds.width || col >= eds.width {
+		return fmt.Errorf("cell (%d, %d) out of range for width %d", row, col, eds.width)
+	}
+	if uint(len(chunk)) != eds.chunkSize {
+		return fmt.Errorf("%w: chunk has size %d, expected %d", ErrInvalidChunkSize, len(chunk), eds.chunkSize)
+	}
+	eds.setCell(row, col, chunk)
+	return nil
+}
+
+// ChunkSize returns the size in bytes of every chunk in the square.
+func (eds *ExtendedDataSquare) ChunkSize() uint {
+	return eds.chunkSize
+}
+
+// Codec returns the codec the square is extended with, or nil if unknown.
+func (eds *ExtendedDataSquare) Codec() Codec {
+	return eds.codec
+}
+
 // Width returns the width of the square.
 func (eds *ExtendedDataSquare) Width() uint {
 	return eds.width
 }
+
+var checksumTable = crc64.MakeTable(crc64.ECMA)
+
+// Checksum returns a fingerprint of the width, chunk size and shares of the
+// square, for quick equality checks and cache keys. It is much cheaper than
+// computing the Merkle roots, but is not collision resistant and must not be
+// used where shares may be chosen adversarially.
+func (eds *ExtendedDataSquare) Checksum() uint64 {
+	h := crc64.New(checksumTable)
+	eds.HashSquare(h)
+	return h.Sum64()
+}
+
+// HashSquare writes the square to h and returns the resulting digest, for
+// content addressing whole squares without their Merkle structure. The width
+// and chunk size, as 8-byte big-endian integers, are followed by the shares
+// in row-major order. With a collision resistant hash such as SHA-256, equal
+// digests mean equal squares.
+func (eds *ExtendedDataSquare) HashSquare(h hash.Hash) []byte {
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[:8], uint64(eds.width))
+	binary.BigEndian.PutUint64(header[8:], uint64(eds.chunkSize))
+	h.Write(header[:])
+	for i := uint(0); i < eds.width; i++ {
+		for _, share := range eds.row(i) {
+			h.Write(share)
+		}
+	}
+	return h.Sum(nil)
+}
@@ -1,12 +1,50 @@
 package rsmt2d
 
 import (
-	"crypto/rand"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"flag"
 	"fmt"
+	"math/rand"
 	"reflect"
+	"sync"
 	"testing"
 )
 
+var testSeed = flag.Int64("seed", 1, "seed of the random data and erasures used by tests and benchmarks")
+
+// testRand is the source of all randomness in tests, so that failures can be
+// reproduced by running with the same -seed.
+var testRand = rand.New(&lockedSource{src: rand.NewSource(1)})
+
+// lockedSource makes a rand.Source safe for concurrent use. It is seeded
+// from -seed on first use, after flags are parsed.
+type lockedSource struct {
+	mu     sync.Mutex
+	src    rand.Source
+	seeded bool
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.seeded {
+		s.src.Seed(*testSeed)
+		s.seeded = true
+	}
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+	s.seeded = true
+}
+
 func TestComputeExtendedDataSquare(t *testing.T) {
 	codec := NewRSGF8Codec()
 	result, err := ComputeExtendedDataSquare([][]byte{
@@ -26,6 +64,48 @@ func TestComputeExtendedDataSquare(t *testing.T) {
 	}
 }
 
+func TestSingleChunkSquare(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare([][]byte{{1, 2}}, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	if !reflect.DeepEqual(eds.squareRow, [][][]byte{
+		{{1, 2}, {1, 2}},
+		{{1, 2}, {1, 2}},
+	}) {
+		t.Errorf("ComputeExtendedDataSquare failed for 1x1 square")
+	}
+
+	flattened := eds.flattened()
+	flattened[0], flattened[1], flattened[2] = nil, nil, nil
+	repaired, err := RepairExtendedDataSquare(eds.RowRoots(), eds.ColRoots(), flattened, codec, NewDefaultTree)
+	if err != nil {
+		t.Fatalf("RepairExtendedDataSquare failed for 2x2 square: %v", err)
+	}
+	if !reflect.DeepEqual(repaired.flattened(), eds.flattened()) {
+		t.Errorf("RepairExtendedDataSquare failed for 2x2 square")
+	}
+
+	_, err = ImportExtendedDataSquare([][]byte{{1, 2}}, codec, NewDefaultTree)
+	if err == nil {
+		t.Errorf("ImportExtendedDataSquare accepted a square of odd width")
+	}
+}
+
+func TestEmptySquare(t *testing.T) {
+	codec := NewRSGF8Codec()
+	if _, err := ComputeExtendedDataSquare(nil, codec, NewDefaultTree); err != ErrEmptySquare {
+		t.Errorf("ComputeExtendedDataSquare returned %v, expected ErrEmptySquare", err)
+	}
+	if _, err := ImportExtendedDataSquare(nil, codec, NewDefaultTree); err != ErrEmptySquare {
+		t.Errorf("ImportExtendedDataSquare returned %v, expected ErrEmptySquare", err)
+	}
+	if _, err := RepairExtendedDataSquare(nil, nil, nil, codec, NewDefaultTree); err != ErrEmptySquare {
+		t.Errorf("RepairExtendedDataSquare returned %v, expected ErrEmptySquare", err)
+	}
+}
+
 // dump acts as a data dump for the benchmarks to stop the compiler from making
 // unrealistic optimizations
 var dump *ExtendedDataSquare
@@ -52,6 +132,20 @@ func BenchmarkExtension(b *testing.B) {
 	}
 }
 
+var colDump [][]byte
+
+func BenchmarkCol(b *testing.B) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(128), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		colDump = eds.Col(uint(n) % eds.Width())
+	}
+}
+
 // genRandDS make a datasquare of random data, with width describing the number
 // of shares on a single side of the ds
 func genRandDS(width int) [][]byte {
@@ -59,8 +153,303 @@ func genRandDS(width int) [][]byte {
 	count := width * width
 	for i := 0; i < count; i++ {
 		share := make([]byte, 256)
-		rand.Read(share)
+		testRand.Read(share)
 		ds = append(ds, share)
 	}
 	return ds
 }
+
+func TestPrecomputeRoots(t *testing.T) {
+	codec := NewRSGF8Codec()
+	square := genRandDS(8)
+	want, err := ComputeExtendedDataSquare(square, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	eds, err := ComputeExtendedDataSquare(square, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	eds.PrecomputeRoots(context.Background())
+	if err := eds.WaitRoots(); err != nil {
+		t.Errorf("WaitRoots failed: %v", err)
+	}
+	if !reflect.DeepEqual(eds.RowRoots(), want.RowRoots()) || !reflect.DeepEqual(eds.ColRoots(), want.ColRoots()) {
+		t.Errorf("precomputed roots do not match")
+	}
+
+	eds, err = ComputeExtendedDataSquare(square, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	eds.PrecomputeRoots(ctx)
+	if err := eds.WaitRoots(); err != context.Canceled {
+		t.Errorf("WaitRoots returned %v, want %v", err, context.Canceled)
+	}
+	if !reflect.DeepEqual(eds.RowRoots(), want.RowRoots()) || !reflect.DeepEqual(eds.ColRoots(), want.ColRoots()) {
+		t.Errorf("roots after cancelled precomputation do not match")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	want := eds.flattened()
+	snapshot := eds.Snapshot()
+
+	eds.setCell(0, 0, make([]byte, eds.chunkSize))
+	if !reflect.DeepEqual(snapshot.flattened(), want) {
+		t.Errorf("snapshot changed after modifying the original square")
+	}
+	if reflect.DeepEqual(eds.flattened(), want) {
+		t.Errorf("original square was not modified")
+	}
+
+	snapshot.setCell(1, 1, make([]byte, eds.chunkSize))
+	if !reflect.DeepEqual(snapshot.Col(1)[1], make([]byte, eds.chunkSize)) {
+		t.Errorf("column view of snapshot not updated")
+	}
+	if reflect.DeepEqual(eds.getCell(1, 1), make([]byte, eds.chunkSize)) {
+		t.Errorf("original square changed after modifying the snapshot")
+	}
+}
+
+func TestSetCell(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	if eds.ChunkSize() != 256 {
+		t.Errorf("ChunkSize returned %d, expected 256", eds.ChunkSize())
+	}
+
+	chunk := make([]byte, eds.ChunkSize())
+	if err := eds.SetCell(1, 2, chunk); err != nil {
+		t.Errorf("SetCell failed: %v", err)
+	}
+	if !reflect.DeepEqual(eds.Row(1)[2], chunk) || !reflect.DeepEqual(eds.Col(2)[1], chunk) {
+		t.Errorf("SetCell did not update the square")
+	}
+
+	if err := eds.SetCell(0, 0, make([]byte, eds.ChunkSize()+1)); !errors.Is(err, ErrInvalidChunkSize) {
+		t.Errorf("SetCell accepted a chunk of the wrong size; got %v", err)
+	}
+	if err := eds.SetCell(0, eds.Width(), chunk); err == nil {
+		t.Errorf("SetCell accepted an out of range cell")
+	}
+}
+
+func TestProveRowCells(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	cols := []uint{7, 0, 3, 3}
+	proofs, err := eds.ProveRowCells(2, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := NewDefaultTree().(ProvableTree)
+	for i, col := range cols {
+		want, err := eds.ProveCell(2, col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, proofs[i]) {
+			t.Errorf("ProveRowCells and ProveCell differ for cell (2, %d)", col)
+		}
+		if !tree.VerifyProof(eds.RowRoots()[2], proofs[i]) {
+			t.Errorf("proof of cell (2, %d) does not verify", col)
+		}
+	}
+
+	if _, err := eds.ProveRowCells(2, []uint{8}); err == nil {
+		t.Errorf("ProveRowCells accepted an out of range column")
+	}
+	if _, err := eds.ProveRowCells(8, nil); err == nil {
+		t.Errorf("ProveRowCells accepted an out of range row")
+	}
+}
+
+func TestProveColumnCells(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	rows := []uint{5, 1, 1}
+	proofs, err := eds.ProveColumnCells(6, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := NewDefaultTree().(ProvableTree)
+	for i, row := range rows {
+		want, err := eds.ProveCellOnAxis(Coordinate{Row: row, Col: 6}, ColAxis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want.Proof, proofs[i]) {
+			t.Errorf("ProveColumnCells and ProveCellOnAxis differ for cell (%d, 6)", row)
+		}
+		if !tree.VerifyProof(eds.ColRoots()[6], proofs[i]) {
+			t.Errorf("proof of cell (%d, 6) does not verify", row)
+		}
+	}
+
+	if _, err := eds.ProveColumnCells(6, []uint{8}); err == nil {
+		t.Errorf("ProveColumnCells accepted an out of range row")
+	}
+	if _, err := eds.ProveColumnCells(8, nil); err == nil {
+		t.Errorf("ProveColumnCells accepted an out of range column")
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	imported, err := ImportExtendedDataSquare(eds.flattened(), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	if eds.Checksum() != imported.Checksum() {
+		t.Errorf("equal squares have different checksums")
+	}
+
+	if err := imported.SetCell(3, 3, make([]byte, eds.ChunkSize())); err != nil {
+		t.Fatal(err)
+	}
+	if eds.Checksum() == imported.Checksum() {
+		t.Errorf("modifying a share did not change the checksum")
+	}
+}
+
+func TestHashSquare(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	expected := sha256.New()
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[:8], 4)
+	binary.BigEndian.PutUint64(header[8:], uint64(eds.ChunkSize()))
+	expected.Write(header[:])
+	for _, share := range eds.flattened() {
+		expected.Write(share)
+	}
+	if got := eds.HashSquare(sha256.New()); !bytes.Equal(got, expected.Sum(nil)) {
+		t.Errorf("HashSquare() = %x, expected %x", got, expected.Sum(nil))
+	}
+}
+
+func TestReset(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	snapshot := eds.Snapshot()
+	before := snapshot.flattened()
+
+	for i := 0; i < 2; i++ {
+		data := genRandDS(4)
+		if err := eds.Reset(data); err != nil {
+			t.Fatalf("Reset failed: %v", err)
+		}
+		want, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+		if err != nil {
+			panic(err)
+		}
+		if !reflect.DeepEqual(eds.flattened(), want.flattened()) {
+			t.Errorf("reset square differs from a newly computed square")
+		}
+		if !reflect.DeepEqual(eds.RowRoots(), want.RowRoots()) || !reflect.DeepEqual(eds.ColRoots(), want.ColRoots()) {
+			t.Errorf("reset square has stale roots")
+		}
+	}
+	if !reflect.DeepEqual(snapshot.flattened(), before) {
+		t.Errorf("snapshot changed after resetting the original square")
+	}
+
+	if err := eds.Reset(genRandDS(2)); err == nil {
+		t.Errorf("Reset accepted original data of a different width")
+	}
+}
+
+func TestGrow(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(3)
+	eds, err := ComputeExtendedDataSquare(data[:4], codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	// Cells (0, 2), (1, 2), (2, 0), (2, 1) and (2, 2) are added; the last
+	// two are padded.
+	if err := eds.Grow(3, data[4:7]); err != nil {
+		t.Fatalf("Grow failed: %v", err)
+	}
+	want := [][]byte{
+		data[0], data[1], data[4],
+		data[2], data[3], data[5],
+		data[6], make([]byte, len(data[0])), make([]byte, len(data[0])),
+	}
+	expected, err := ComputeExtendedDataSquare(want, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	if !reflect.DeepEqual(eds.flattened(), expected.flattened()) {
+		t.Errorf("grown square differs from a newly computed square")
+	}
+	if !reflect.DeepEqual(eds.RowRoots(), expected.RowRoots()) {
+		t.Errorf("grown square has stale roots")
+	}
+
+	if err := eds.Grow(3, nil); err == nil {
+		t.Errorf("Grow accepted a width that is not larger")
+	}
+	if err := eds.Grow(4, make([][]byte, 8)); err == nil {
+		t.Errorf("Grow accepted more chunks than cells added")
+	}
+}
+
+// smallCodec supports original squares of width up to 2.
+type smallCodec struct {
+	Codec
+}
+
+func (smallCodec) maxChunks() int {
+	return 4
+}
+
+func TestGrowPromotesCodec(t *testing.T) {
+	registerCodec("small", smallCodec{NewRSGF8Codec()})
+	codecPromotions["small"] = "RSGF8"
+	defer func() {
+		delete(codecs, "small")
+		delete(codecPromotions, "small")
+	}()
+
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), smallCodec{NewRSGF8Codec()}, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	if err := eds.Grow(3, nil); err != nil {
+		t.Fatalf("Grow failed: %v", err)
+	}
+	if name, _ := codecName(eds.Codec()); name != "RSGF8" {
+		t.Errorf("square was not promoted to RSGF8, codec is %s", name)
+	}
+	if err := eds.Grow(200, nil); err == nil {
+		t.Errorf("Grow accepted a width no codec supports")
+	}
+}
@@ -0,0 +1,124 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Extender extends a square whose original data arrives one row at a time.
+// The parity of each row is computed as soon as the row is pushed, so that
+// share production can be pipelined with the production of the data itself,
+// while the rows are buffered to compute column parity once all are known.
+type Extender struct {
+	width         uint
+	codec         Codec
+	treeCreatorFn TreeConstructorFn
+	chunkSize     int
+	rows          [][]byte // original data, row-major
+	rowParity     [][][]byte
+	finished      bool
+}
+
+// NewExtender returns an Extender for an original data square of the given
+// width.
+func NewExtender(width uint, codec Codec, treeCreatorFn TreeConstructorFn) (*Extender, error) {
+	if !IsValidWidth(int(width), codec) {
+		return nil, fmt.Errorf("width %d is not supported by the codec", width)
+	}
+	return &Extender{
+		width:         width,
+		codec:         codec,
+		treeCreatorFn: treeCreatorFn,
+		rows:          make([][]byte, 0, width*width),
+		rowParity:     make([][][]byte, 0, width),
+	}, nil
+}
+
+// PushRow adds the next row of original data and returns its parity shares.
+func (e *Extender) PushRow(row [][]byte) ([][]byte, error) {
+	if e.finished {
+		return nil, errors.New("extender already finished")
+	}
+	if uint(len(e.rowParity)) == e.width {
+		return nil, fmt.Errorf("all %d rows already pushed", e.width)
+	}
+	if uint(len(row)) != e.width {
+		return nil, fmt.Errorf("row has %d chunks, expected %d", len(row), e.width)
+	}
+	if e.chunkSize == 0 {
+		e.chunkSize = len(row[0])
+		if e.chunkSize == 0 {
+			return nil, fmt.Errorf("%w: chunks must not be empty", ErrInvalidChunkSize)
+		}
+		if err := checkChunkSize(e.codec, uint(e.chunkSize)); err != nil {
+			e.chunkSize = 0
+			return nil, err
+		}
+	}
+	for i, chunk := range row {
+		if len(chunk) != e.chunkSize {
+			return nil, fmt.Errorf(
+				"%w: chunk (%d, %d) has size %d, expected %d",
+				ErrInvalidChunkSize, len(e.rowParity), i, len(chunk), e.chunkSize,
+			)
+		}
+	}
+
+	shares, err := e.codec.Encode(row)
+	if err != nil {
+		return nil, err
+	}
+	parity := shares[len(shares)-int(e.width):]
+	e.rows = append(e.rows, row...)
+	e.rowParity = append(e.rowParity, parity)
+	return parity, nil
+}
+
+// Finish computes the column parity once all rows have been pushed and
+// returns the extended data square. The Extender cannot be used afterwards.
+func (e *Extender) Finish() (*ExtendedDataSquare, error) {
+	if e.finished {
+		return nil, errors.New("extender already finished")
+	}
+	if uint(len(e.rowParity)) != e.width {
+		return nil, fmt.Errorf("%d of %d rows pushed", len(e.rowParity), e.width)
+	}
+	e.finished = true
+
+	ds, err := newDataSquare(e.rows, e.treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+	eds := &ExtendedDataSquare{dataSquare: ds, codec: e.codec, originalDataWidth: e.width}
+	if err := eds.extendSquare(e.width, bytes.Repeat([]byte{0}, e.chunkSize)); err != nil {
+		return nil, err
+	}
+
+	k := e.width
+	for i := uint(0); i < k; i++ {
+		if err := eds.setRowSlice(i, k, e.rowParity[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := uint(0); i < k; i++ {
+		shares, err := e.codec.Encode(eds.colSlice(0, i, k))
+		if err != nil {
+			return nil, err
+		}
+		if err := eds.setColSlice(k, i, shares[len(shares)-int(k):]); err != nil {
+			return nil, err
+		}
+	}
+	for i := k; i < eds.width; i++ {
+		shares, err := e.codec.Encode(eds.rowSlice(i, 0, k))
+		if err != nil {
+			return nil, err
+		}
+		if err := eds.setRowSlice(i, k, shares[len(shares)-int(k):]); err != nil {
+			return nil, err
+		}
+	}
+	e.rows, e.rowParity = nil, nil
+	return eds, nil
+}
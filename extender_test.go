@@ -0,0 +1,54 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtender(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(4)
+	want, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	ext, err := NewExtender(4, codec, NewDefaultTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ext.Finish()
+	assert.Error(t, err)
+
+	for i := uint(0); i < 4; i++ {
+		parity, err := ext.PushRow(data[i*4 : i*4+4])
+		if assert.NoError(t, err) {
+			assert.Equal(t, want.Row(i)[4:], parity)
+		}
+	}
+	_, err = ext.PushRow(data[:4])
+	assert.Error(t, err)
+
+	eds, err := ext.Finish()
+	if assert.NoError(t, err) {
+		assert.Equal(t, want.flattened(), eds.flattened())
+		assert.Equal(t, want.RowRoots(), eds.RowRoots())
+	}
+	_, err = ext.Finish()
+	assert.Error(t, err)
+}
+
+func TestExtenderRejectsBadRows(t *testing.T) {
+	ext, err := NewExtender(2, NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ext.PushRow([][]byte{{1}})
+	assert.Error(t, err)
+	_, err = ext.PushRow([][]byte{{1}, {2, 3}})
+	assert.ErrorIs(t, err, ErrInvalidChunkSize)
+
+	_, err = NewExtender(0, NewRSGF8Codec(), NewDefaultTree)
+	assert.Error(t, err)
+}
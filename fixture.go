@@ -0,0 +1,205 @@
+package rsmt2d
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Fixture is a square produced by an implementation of the scheme, in the
+// JSON format of the test vectors in testdata/vectors: all byte strings are
+// hex encoded and squares are listed in row-major order. Either Original or
+// Extended may be omitted, as may the roots; whatever is given must be
+// consistent.
+type Fixture struct {
+	Codec         string   `json:"codec"`
+	Tree          string   `json:"tree"`
+	OriginalWidth int      `json:"original_width"`
+	ChunkSize     int      `json:"chunk_size"`
+	Original      []string `json:"original,omitempty"`
+	Extended      []string `json:"extended,omitempty"`
+	RowRoots      []string `json:"row_roots,omitempty"`
+	ColRoots      []string `json:"col_roots,omitempty"`
+	DataRoot      string   `json:"data_root,omitempty"`
+}
+
+// LoadFixtures reads fixtures from r, which holds either a JSON array of
+// fixtures or a single fixture object. CAR archives are not supported.
+func LoadFixtures(r io.Reader) ([]Fixture, error) {
+	br := bufio.NewReader(r)
+	first, err := firstNonSpace(br)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(br)
+	dec.DisallowUnknownFields()
+	switch first {
+	case '[':
+		var fixtures []Fixture
+		if err := dec.Decode(&fixtures); err != nil {
+			return nil, err
+		}
+		return fixtures, nil
+	case '{':
+		var f Fixture
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		return []Fixture{f}, nil
+	default:
+		return nil, fmt.Errorf("fixtures must be a JSON array or object, found %q", first)
+	}
+}
+
+// firstNonSpace returns the first non-whitespace byte of br without
+// consuming it.
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, errors.New("no fixtures")
+			}
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// Check rebuilds the square of the fixture with its codec and treeCreatorFn,
+// or the tree named by the fixture if treeCreatorFn is nil, and checks that
+// it matches every field given: the extension of the original data, the
+// parity of the extended square, the roots and the data root. Mismatching
+// roots are reported with ErrRootsMismatch.
+func (f *Fixture) Check(treeCreatorFn TreeConstructorFn) error {
+	codec, err := CodecByName(f.Codec)
+	if err != nil {
+		return err
+	}
+	if treeCreatorFn == nil {
+		if f.Tree != "" && f.Tree != "DefaultTree" {
+			return fmt.Errorf("unknown tree %q", f.Tree)
+		}
+		treeCreatorFn = NewDefaultTree
+	}
+	if f.OriginalWidth <= 0 || !IsValidWidth(f.OriginalWidth, codec) {
+		return fmt.Errorf("invalid original width %d for codec %s", f.OriginalWidth, f.Codec)
+	}
+	k := f.OriginalWidth
+
+	original, err := decodeFixtureChunks("original", f.Original, k*k, f.ChunkSize)
+	if err != nil {
+		return err
+	}
+	extended, err := decodeFixtureChunks("extended", f.Extended, 4*k*k, f.ChunkSize)
+	if err != nil {
+		return err
+	}
+
+	var eds *ExtendedDataSquare
+	switch {
+	case original != nil:
+		eds, err = ComputeExtendedDataSquare(original, codec, treeCreatorFn)
+		if err != nil {
+			return err
+		}
+		if extended != nil {
+			for i, chunk := range eds.flattened() {
+				if !bytes.Equal(chunk, extended[i]) {
+					return fmt.Errorf("extended share (%d, %d) differs from the extension of the original data", i/(2*k), i%(2*k))
+				}
+			}
+		}
+	case extended != nil:
+		eds, err = ImportExtendedDataSquare(extended, codec, treeCreatorFn)
+		if err != nil {
+			return err
+		}
+		if err := eds.verifyAllParity(); err != nil {
+			return err
+		}
+	default:
+		return errors.New("fixture has neither original nor extended shares")
+	}
+
+	rowRoots, err := decodeFixtureRoots("row", f.RowRoots, 2*k)
+	if err != nil {
+		return err
+	}
+	colRoots, err := decodeFixtureRoots("column", f.ColRoots, 2*k)
+	if err != nil {
+		return err
+	}
+	var rows, cols []uint
+	if rowRoots != nil {
+		rows = DiffRoots(rowRoots, eds.getRowRoots())
+	}
+	if colRoots != nil {
+		cols = DiffRoots(colRoots, eds.getColRoots())
+	}
+	if rows != nil || cols != nil {
+		return &ErrRootsMismatch{Rows: rows, Cols: cols}
+	}
+
+	if f.DataRoot != "" {
+		dataRoot, err := hex.DecodeString(f.DataRoot)
+		if err != nil {
+			return fmt.Errorf("data root: %w", err)
+		}
+		if !bytes.Equal(dataRoot, eds.DataRoot()) {
+			return ErrDataRootMismatch
+		}
+	}
+	return nil
+}
+
+// decodeFixtureChunks decodes the hex encoded shares of a fixture, checking
+// their number and size. It returns nil if there are none.
+func decodeFixtureChunks(name string, shares []string, count int, chunkSize int) ([][]byte, error) {
+	if len(shares) == 0 {
+		return nil, nil
+	}
+	if len(shares) != count {
+		return nil, fmt.Errorf("%s square has %d shares, expected %d", name, len(shares), count)
+	}
+	chunks := make([][]byte, count)
+	for i, s := range shares {
+		chunk, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s share %d: %w", name, i, err)
+		}
+		if chunkSize != 0 && len(chunk) != chunkSize {
+			return nil, fmt.Errorf("%s share %d has %d bytes, expected %d", name, i, len(chunk), chunkSize)
+		}
+		chunks[i] = chunk
+	}
+	return chunks, nil
+}
+
+// decodeFixtureRoots decodes the hex encoded row or column roots of a
+// fixture. It returns nil if there are none.
+func decodeFixtureRoots(name string, roots []string, width int) ([][]byte, error) {
+	if len(roots) == 0 {
+		return nil, nil
+	}
+	if len(roots) != width {
+		return nil, fmt.Errorf("fixture has %d %s roots, expected %d", len(roots), name, width)
+	}
+	decoded := make([][]byte, width)
+	for i, s := range roots {
+		root, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s root %d: %w", name, i, err)
+		}
+		decoded[i] = root
+	}
+	return decoded, nil
+}
@@ -0,0 +1,78 @@
+package rsmt2d
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtureCheck(t *testing.T) {
+	vectors, err := generateVectors("RSGF8", NewRSGF8Codec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := vectors[2]
+
+	t.Run("valid", func(t *testing.T) {
+		f := valid
+		assert.NoError(t, f.Check(nil))
+	})
+	t.Run("extended only", func(t *testing.T) {
+		f := valid
+		f.Original = nil
+		assert.NoError(t, f.Check(nil))
+	})
+	t.Run("original only", func(t *testing.T) {
+		f := valid
+		f.Extended, f.RowRoots, f.ColRoots, f.DataRoot = nil, nil, nil, ""
+		assert.NoError(t, f.Check(nil))
+	})
+	t.Run("bad parity", func(t *testing.T) {
+		f := valid
+		f.Original = nil
+		f.Extended = append([]string(nil), valid.Extended...)
+		f.Extended[len(f.Extended)-1] = strings.Repeat("00", valid.ChunkSize)
+		assert.Error(t, f.Check(nil))
+	})
+	t.Run("bad row root", func(t *testing.T) {
+		f := valid
+		f.RowRoots = append([]string(nil), valid.RowRoots...)
+		f.RowRoots[1] = f.RowRoots[0]
+		err := f.Check(nil)
+		var mismatch *ErrRootsMismatch
+		if assert.True(t, errors.As(err, &mismatch)) {
+			assert.Equal(t, []uint{1}, mismatch.Rows)
+			assert.Empty(t, mismatch.Cols)
+		}
+	})
+	t.Run("bad data root", func(t *testing.T) {
+		f := valid
+		f.DataRoot = valid.RowRoots[0]
+		assert.True(t, errors.Is(f.Check(nil), ErrDataRootMismatch))
+	})
+	t.Run("unknown tree", func(t *testing.T) {
+		f := valid
+		f.Tree = "OtherTree"
+		assert.Error(t, f.Check(nil))
+		assert.NoError(t, f.Check(NewDefaultTree))
+	})
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures, err := LoadFixtures(strings.NewReader(` {"codec": "RSGF8", "original_width": 1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Fixture{{Codec: "RSGF8", OriginalWidth: 1}}, fixtures)
+
+	fixtures, err = LoadFixtures(strings.NewReader(`[{"codec": "RSGF8"}, {"codec": "RSGF8"}]`))
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 2)
+
+	_, err = LoadFixtures(strings.NewReader(`{"codec": "RSGF8", "unknown": 1}`))
+	assert.Error(t, err)
+	_, err = LoadFixtures(strings.NewReader(""))
+	assert.Error(t, err)
+	_, err = LoadFixtures(strings.NewReader("CAR"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// The Fuzz functions are entry points for go-fuzz style fuzzers such as
+// OSS-Fuzz. Each takes arbitrary input, returns 1 if the input reached the
+// code under test, 0 if it was rejected early and -1 if it should not be
+// added to the corpus, and panics when an invariant of the package is
+// violated.
+
+// fuzzInput consumes fuzz input.
+type fuzzInput struct {
+	data []byte
+}
+
+func (in *fuzzInput) byte() byte {
+	if len(in.data) == 0 {
+		return 0
+	}
+	b := in.data[0]
+	in.data = in.data[1:]
+	return b
+}
+
+// bytes returns the next n bytes, padded with zeros if the input runs out.
+func (in *fuzzInput) bytes(n int) []byte {
+	b := make([]byte, n)
+	copy(b, in.data)
+	if n > len(in.data) {
+		n = len(in.data)
+	}
+	in.data = in.data[n:]
+	return b
+}
+
+// FuzzRepair parses the input into an original square, an erasure mask and
+// corruptions of the remaining shares, and repairs the square against the
+// roots of the original, verifying every root. Repair must either restore the
+// original square or fail.
+//
+// The input is the original width minus one (mod 4) and chunk size minus one
+// (mod 32), one bit per cell of the extended square marking it present, one
+// byte selecting a cell to corrupt (none if it is 0xff), and the original
+// shares.
+func FuzzRepair(data []byte) int {
+	in := &fuzzInput{data: data}
+	k := int(in.byte()%4) + 1
+	chunkSize := int(in.byte()%32) + 1
+	width := 2 * k
+	mask := in.bytes((width*width + 7) / 8)
+	corrupt := in.byte()
+
+	original := make([][]byte, k*k)
+	for i := range original {
+		original[i] = in.bytes(chunkSize)
+	}
+	eds, err := ComputeExtendedDataSquare(original, NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(fmt.Sprintf("extending a valid square failed: %v", err))
+	}
+
+	shares := eds.flattened()
+	for i := range shares {
+		if mask[i/8]&(1<<uint(i%8)) == 0 {
+			shares[i] = nil
+		}
+	}
+	if corrupt != 0xff && int(corrupt) < len(shares) && shares[corrupt] != nil {
+		shares[corrupt] = append([]byte(nil), shares[corrupt]...)
+		shares[corrupt][0] ^= 1
+	}
+
+	repaired, err := RepairExtendedDataSquare(eds.RowRoots(), eds.ColRoots(), shares, NewRSGF8Codec(), NewDefaultTree, WithVerifyAllRoots())
+	if err != nil {
+		return 1
+	}
+	for i, share := range repaired.flattened() {
+		if !bytes.Equal(share, eds.flattened()[i]) {
+			panic(fmt.Sprintf("repair returned a different share at (%d, %d)", i/width, i%width))
+		}
+	}
+	return 1
+}
+
+// FuzzImport parses the input into the shares of an extended square and
+// imports it. An imported square must export the same shares and roots when
+// imported again.
+//
+// The input is the width minus one (mod 8), the chunk size minus one (mod
+// 32) and the shares.
+func FuzzImport(data []byte) int {
+	in := &fuzzInput{data: data}
+	width := int(in.byte()%8) + 1
+	chunkSize := int(in.byte()%32) + 1
+	shares := make([][]byte, width*width)
+	for i := range shares {
+		shares[i] = in.bytes(chunkSize)
+	}
+
+	eds, err := ImportExtendedDataSquare(shares, NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		return 0
+	}
+	again, err := ImportExtendedDataSquare(eds.flattened(), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(fmt.Sprintf("re-importing an imported square failed: %v", err))
+	}
+	if !bytes.Equal(eds.DataRoot(), again.DataRoot()) {
+		panic("re-importing a square changed its data root")
+	}
+	return 1
+}
+
+// FuzzReadODS reads the input as a serialized original data square. A square
+// that is read must be written and read back to the same data root.
+func FuzzReadODS(data []byte) int {
+	eds, err := ReadODS(bytes.NewReader(data), NewDefaultTree)
+	if err != nil {
+		return 0
+	}
+	var buf bytes.Buffer
+	if err := eds.WriteODS(&buf); err != nil {
+		panic(fmt.Sprintf("writing a square that was read failed: %v", err))
+	}
+	again, err := ReadODS(&buf, NewDefaultTree)
+	if err != nil {
+		panic(fmt.Sprintf("reading a written square failed: %v", err))
+	}
+	if !bytes.Equal(eds.DataRoot(), again.DataRoot()) {
+		panic("writing and reading a square changed its data root")
+	}
+	return 1
+}
+
+// FuzzProofs decodes the input as each proof type. A decoded proof must
+// encode to the input.
+func FuzzProofs(data []byte) int {
+	result := 0
+	for _, proof := range []interface {
+		MarshalBinary() ([]byte, error)
+		UnmarshalBinary([]byte) error
+	}{&CellProof{}, &AxisRootProof{}, &BadEncodingProof{}} {
+		if err := proof.UnmarshalBinary(data); err != nil {
+			if errors.Is(err, ErrUnsupportedProofVersion) {
+				return -1
+			}
+			continue
+		}
+		encoded, err := proof.MarshalBinary()
+		if err != nil {
+			panic(fmt.Sprintf("encoding a decoded %T failed: %v", proof, err))
+		}
+		if !bytes.Equal(encoded, data) {
+			panic(fmt.Sprintf("%T does not encode to the input it was decoded from", proof))
+		}
+		result = 1
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzEntryPoints(t *testing.T) {
+	inputs := [][]byte{nil, {0}, {0xff, 0xff, 0xff}}
+	for i := 0; i < 200; i++ {
+		input := make([]byte, testRand.Intn(300))
+		testRand.Read(input)
+		inputs = append(inputs, input)
+	}
+
+	// Inputs that keep every share and corrupt none repair trivially.
+	complete := append([]byte{3, 7}, bytes.Repeat([]byte{0xff}, 9)...)
+	original := make([]byte, 16*8)
+	testRand.Read(original)
+	assert.Equal(t, 1, FuzzRepair(append(complete, original...)))
+
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	var ods bytes.Buffer
+	assert.NoError(t, eds.WriteODS(&ods))
+	assert.Equal(t, 1, FuzzReadODS(ods.Bytes()))
+
+	proof, err := eds.ProveCellOnAxis(Coordinate{Row: 1, Col: 3}, RowAxis)
+	assert.NoError(t, err)
+	encoded, err := proof.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, FuzzProofs(encoded))
+	inputs = append(inputs, ods.Bytes(), encoded)
+
+	for _, input := range inputs {
+		assert.NotPanics(t, func() {
+			FuzzRepair(input)
+			FuzzImport(input)
+			FuzzReadODS(input)
+			FuzzProofs(input)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package rsmt2d
+
+// gf256Poly is the reduction polynomial x^8+x^4+x^3+x^2+1 of GF(2^8) as used
+// by the RSGF8 codec.
+const gf256Poly = 0x11d
+
+var (
+	gf256Exp [510]byte
+	gf256Log [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gf256Exp[i] = byte(x)
+		gf256Exp[i+255] = byte(x)
+		gf256Log[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gf256Poly
+		}
+	}
+}
+
+// gf256Codec is implemented by codecs whose symbols are bytes in GF(2^8)
+// with the polynomial gf256Poly in the standard basis, and which therefore
+// encode linearly over the field implemented here.
+type gf256Codec interface {
+	gf256()
+}
+
+func gf256Mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[gf256Log[a]+gf256Log[b]]
+}
+
+func gf256Inv(a byte) byte {
+	return gf256Exp[255-gf256Log[a]]
+}
+
+// gf256MulAdd sets dst to dst + c*src.
+func gf256MulAdd(dst []byte, src []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	logC := gf256Log[c]
+	for i, s := range src {
+		if s != 0 {
+			dst[i] ^= gf256Exp[gf256Log[s]+logC]
+		}
+	}
+}
+
+// gf256Scale sets data to c*data.
+func gf256Scale(data []byte, c byte) {
+	for i, d := range data {
+		data[i] = gf256Mul(d, c)
+	}
+}
@@ -0,0 +1,119 @@
+package rsmt2d
+
+// maxGlobalDecodingWidth is the largest original width that solveGlobal
+// attempts. The joint system has k^2 unknowns and up to 4k^2 equations, so its
+// coefficient matrix takes about 4k^4 bytes and elimination O(k^6) operations;
+// at this width that is 256 KiB and a few seconds of work at most.
+const maxGlobalDecodingWidth = 16
+
+// solveGlobal reconstructs the square by treating it as a product code: every
+// known cell is a linear combination of the original data, so the original
+// data is recovered by solving the joint linear system of all known cells at
+// once rather than axis by axis. This recovers erasure patterns that stop the
+// crossword, but costs O(k^6) field operations for an original width k and is
+// only supported by GF(2^8) codecs. Squares wider than maxGlobalDecodingWidth
+// are reported as unrepairable without attempting the decode.
+func (eds *ExtendedDataSquare) solveGlobal(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	bitMask bitMatrix,
+	codec Codec,
+) error {
+	if _, ok := codec.(gf256Codec); !ok {
+		return ErrUnrepairableDataSquare
+	}
+
+	k := int(eds.originalDataWidth)
+	if k > maxGlobalDecodingWidth {
+		return classifyErasures(bitMask, k)
+	}
+	gen, err := generatorMatrix(codec, k)
+	if err != nil {
+		return err
+	}
+
+	// Each known cell (r, c) yields the equation
+	// cell = sum over (i, j) of gen[r][i] * gen[c][j] * original[i][j].
+	var coeffs, values [][]byte
+	for r := 0; r < int(eds.width); r++ {
+		rowData := eds.row(uint(r))
+		for c := 0; c < int(eds.width); c++ {
+			if !bitMask.Get(r, c) {
+				continue
+			}
+			coeff := make([]byte, k*k)
+			for i := 0; i < k; i++ {
+				for j := 0; j < k; j++ {
+					coeff[i*k+j] = gf256Mul(gen[r][i], gen[c][j])
+				}
+			}
+			coeffs = append(coeffs, coeff)
+			values = append(values, append([]byte(nil), rowData[c]...))
+		}
+	}
+
+	// Gauss-Jordan elimination, applying every row operation to the values.
+	for unknown := 0; unknown < k*k; unknown++ {
+		pivot := -1
+		for row := unknown; row < len(coeffs); row++ {
+			if coeffs[row][unknown] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot == -1 {
+			return classifyErasures(bitMask, k)
+		}
+		coeffs[unknown], coeffs[pivot] = coeffs[pivot], coeffs[unknown]
+		values[unknown], values[pivot] = values[pivot], values[unknown]
+
+		inv := gf256Inv(coeffs[unknown][unknown])
+		gf256Scale(coeffs[unknown], inv)
+		gf256Scale(values[unknown], inv)
+		for row := range coeffs {
+			if row != unknown && coeffs[row][unknown] != 0 {
+				factor := coeffs[row][unknown]
+				gf256MulAdd(coeffs[row], coeffs[unknown], factor)
+				gf256MulAdd(values[row], values[unknown], factor)
+			}
+		}
+	}
+
+	// The first k*k values now hold the original data in row-major order.
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			eds.setCell(uint(i), uint(j), values[i*k+j])
+		}
+	}
+	if err := eds.encodeParity(codec); err != nil {
+		return err
+	}
+
+	return eds.verifyAllRoots(rowRoots, colRoots)
+}
+
+// generatorMatrix returns the 2k x k systematic generator matrix of codec by
+// encoding unit vectors.
+func generatorMatrix(codec Codec, k int) ([][]byte, error) {
+	gen := make([][]byte, 2*k)
+	for i := range gen {
+		gen[i] = make([]byte, k)
+	}
+	for j := 0; j < k; j++ {
+		gen[j][j] = 1
+
+		unit := make([][]byte, k)
+		for i := range unit {
+			unit[i] = []byte{0}
+		}
+		unit[j][0] = 1
+		parity, err := codec.Encode(unit)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < k; i++ {
+			gen[k+i][j] = parity[len(parity)-k+i][0]
+		}
+	}
+	return gen, nil
+}
@@ -0,0 +1,92 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalDecoding(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	// Only one cell per row and column is present, which stops the crossword
+	// but still determines the original data.
+	flattened := make([][]byte, 16)
+	for _, i := range []int{2, 5, 8, 15} {
+		flattened[i] = original.flattened()[i]
+	}
+
+	_, err = RepairExtendedDataSquare(original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree)
+	assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+
+	result, err := RepairExtendedDataSquare(
+		original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree, WithGlobalDecoding(),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, original.flattened(), result.flattened())
+	}
+
+	// A mismatching root is reported with the provided shares of its row.
+	rowRoots := append([][]byte(nil), original.getRowRoots()...)
+	rowRoots[1] = rowRoots[0]
+	_, err = RepairExtendedDataSquare(rowRoots, original.getColRoots(), flattened, codec, NewDefaultTree, WithGlobalDecoding())
+	var byzRow *ErrByzantineRow
+	if assert.ErrorAs(t, err, &byzRow) {
+		assert.Equal(t, uint(1), byzRow.RowNumber)
+		assert.Equal(t, flattened[4:8], byzRow.Shares)
+	}
+
+	// Three cells cannot determine four unknowns.
+	flattened[15] = nil
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree, WithGlobalDecoding(),
+	)
+	assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+}
+
+func TestGeneratorMatrix(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(4)[:4]
+	parity, err := codec.Encode(data)
+	if err != nil {
+		panic(err)
+	}
+
+	gen, err := generatorMatrix(codec, 4)
+	if err != nil {
+		panic(err)
+	}
+	for i, p := range parity {
+		want := make([]byte, len(p))
+		for j, d := range data {
+			gf256MulAdd(want, d, gen[4+i][j])
+		}
+		assert.Equal(t, p, want)
+	}
+}
+
+func TestGlobalDecodingWidthLimit(t *testing.T) {
+	codec := NewRSGF8Codec()
+	k := maxGlobalDecodingWidth * 2
+	eds, err := ComputeExtendedDataSquare(genRandDS(k), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	// Every cell is known, so only the width limit stops the decode.
+	bitMask := newBitMatrix(2 * k)
+	for r := 0; r < 2*k; r++ {
+		for c := 0; c < 2*k; c++ {
+			bitMask.Set(r, c)
+		}
+	}
+	err = eds.solveGlobal(eds.getRowRoots(), eds.getColRoots(), bitMask, codec)
+	var unrepairable *ErrUnrepairable
+	if assert.ErrorAs(t, err, &unrepairable) {
+		assert.Equal(t, 2*k, unrepairable.Width)
+	}
+}
@@ -0,0 +1,102 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrInvalidShareProof is returned when a share does not verify against the
+// expected row root.
+var ErrInvalidShareProof = errors.New("share does not match its inclusion proof")
+
+// ShareRequest asks a peer for the shares at the given coordinates.
+type ShareRequest struct {
+	Coords []Coordinate
+}
+
+// MarshalBinary encodes the request.
+func (r *ShareRequest) MarshalBinary() ([]byte, error) {
+	b := appendUvarint(nil, uint64(len(r.Coords)))
+	for _, c := range r.Coords {
+		b = appendUvarint(b, uint64(c.Row))
+		b = appendUvarint(b, uint64(c.Col))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a request encoded with MarshalBinary.
+func (r *ShareRequest) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	coords := make([]Coordinate, d.count())
+	for i := range coords {
+		coords[i] = Coordinate{Row: uint(d.uvarint()), Col: uint(d.uvarint())}
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+	r.Coords = coords
+	return nil
+}
+
+// ShareResponse carries a share together with its inclusion proof against
+// the root of the share's row.
+type ShareResponse struct {
+	Coord Coordinate
+	Share []byte
+	Proof Proof
+}
+
+// NewShareResponse builds the response for a single cell of a complete EDS.
+func NewShareResponse(eds *ExtendedDataSquare, coord Coordinate) (*ShareResponse, error) {
+	proof, err := eds.ProveCell(coord.Row, coord.Col)
+	if err != nil {
+		return nil, err
+	}
+	return &ShareResponse{
+		Coord: coord,
+		Share: eds.getCell(coord.Row, coord.Col),
+		Proof: proof,
+	}, nil
+}
+
+// MarshalBinary encodes the response.
+func (r *ShareResponse) MarshalBinary() ([]byte, error) {
+	b := appendUvarint(nil, uint64(r.Coord.Row))
+	b = appendUvarint(b, uint64(r.Coord.Col))
+	b = appendBytes(b, r.Share)
+	return appendProof(b, r.Proof), nil
+}
+
+// UnmarshalBinary decodes a response encoded with MarshalBinary.
+func (r *ShareResponse) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	resp := ShareResponse{
+		Coord: Coordinate{Row: uint(d.uvarint()), Col: uint(d.uvarint())},
+		Share: d.bytes(),
+		Proof: d.proof(),
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*r = resp
+	return nil
+}
+
+// Verify checks that the share is included at its coordinate under the
+// corresponding row root.
+func (r *ShareResponse) Verify(rowRoots [][]byte, treeCreatorFn TreeConstructorFn) error {
+	if r.Coord.Row >= uint(len(rowRoots)) || r.Coord.Col >= uint(len(rowRoots)) {
+		return ErrInvalidShareProof
+	}
+	tree, ok := treeCreatorFn().(ProvableTree)
+	if !ok {
+		return ErrTreeNotProvable
+	}
+	if r.Proof.Index != uint64(r.Coord.Col) ||
+		len(r.Proof.Set) == 0 ||
+		!bytes.Equal(r.Proof.Set[0], r.Share) ||
+		!tree.VerifyProof(rowRoots[r.Coord.Row], r.Proof) {
+		return ErrInvalidShareProof
+	}
+	return nil
+}
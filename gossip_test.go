@@ -0,0 +1,53 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareRequestRoundTrip(t *testing.T) {
+	req := ShareRequest{Coords: []Coordinate{{0, 1}, {3, 2}, {300, 70000}}}
+	data, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded ShareRequest
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, req, decoded)
+
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Errorf("truncated request decoded without error")
+	}
+}
+
+func TestShareResponse(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, coord := range []Coordinate{{0, 0}, {2, 7}, {7, 5}} {
+		resp, err := NewShareResponse(eds, coord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := resp.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded ShareResponse
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, *resp, decoded)
+		assert.NoError(t, decoded.Verify(eds.RowRoots(), NewDefaultTree))
+
+		decoded.Coord.Col = (coord.Col + 1) % eds.Width()
+		assert.Equal(t, ErrInvalidShareProof, decoded.Verify(eds.RowRoots(), NewDefaultTree))
+	}
+}
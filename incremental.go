@@ -0,0 +1,377 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrIngestionQueueFull is returned by TryAddShare when the ingestion queue
+// of an IncrementalSolver is full. The share should be dropped or retried
+// later; it is not retained.
+var ErrIngestionQueueFull = errors.New("share ingestion queue is full")
+
+// ErrShareEquivocation is returned when two shares proven for the same cell
+// differ. The roots of the square commit to both, one through the row and
+// the other through the column of the cell, so the pair is evidence that the
+// producer of the square equivocated.
+type ErrShareEquivocation struct {
+	Coord  Coordinate
+	First  []byte // Proven share supplied first, which is kept
+	Second []byte // Conflicting proven share, which is discarded
+}
+
+func (e *ErrShareEquivocation) Error() string {
+	return fmt.Sprintf("roots commit to conflicting shares for cell (%d, %d)", e.Coord.Row, e.Coord.Col)
+}
+
+// Coordinates returns the cell supplied twice.
+func (e *ErrShareEquivocation) Coordinates() []Coordinate {
+	return []Coordinate{e.Coord}
+}
+
+// IncrementalSolver collects the shares of a square as they arrive, from
+// storage or peers, tracks which cells are present, and repairs the square
+// once enough shares have arrived. It is safe for concurrent use.
+//
+// Shares from peers are added with a proof against the root of their row or
+// column, which is verified before the share occupies its cell, so that a
+// forged share cannot take the place of the honest one. Shares the caller
+// already trusts, such as those read from its own storage, are added without
+// a proof by AddTrustedShare; a proven share replaces a trusted share that
+// differs from it. The chunk size is likewise fixed by the first proven
+// share, trusted shares of another size being removed when it arrives.
+//
+// Shares from the network should be passed to TryAddShare, which buffers
+// them in a bounded queue and drops them when it is full, so that a flood of
+// shares from many peers cannot grow memory without bounds. Queued shares are
+// verified and added by Repairable, Solve and Drain.
+type IncrementalSolver struct {
+	rowRoots      [][]byte
+	colRoots      [][]byte
+	codec         Codec
+	treeCreatorFn TreeConstructorFn
+	width         uint
+	queue         chan CellProof
+
+	mu        sync.Mutex
+	data      [][]byte
+	present   bitMatrix
+	proven    bitMatrix
+	chunkSize int
+	// sizeProven is set once a proven share has fixed the chunk size, which
+	// trusted shares only set provisionally.
+	sizeProven bool
+	solved     *ExtendedDataSquare
+	stats      IncrementalStats
+	// conflicts holds the first equivocation of each cell, so that it is
+	// bounded by the size of the square.
+	conflicts  []*ErrShareEquivocation
+	conflicted bitMatrix
+}
+
+// IncrementalStats are the counters of an IncrementalSolver.
+type IncrementalStats struct {
+	Added      int // Shares added to the square
+	Rejected   int // Shares rejected as invalid or failing their proof
+	Dropped    int // Shares dropped because the ingestion queue was full
+	Queued     int // Shares waiting in the ingestion queue
+	Duplicates int // Shares supplied again, identical to the present share
+	Conflicts  int // Shares supplied again, differing from the present share
+	Evicted    int // Trusted shares removed for differing in size from proven shares
+}
+
+// IncrementalOption configures an IncrementalSolver.
+type IncrementalOption func(*incrementalConfig)
+
+type incrementalConfig struct {
+	queueSize int
+}
+
+// WithIngestionQueue sets the number of shares TryAddShare can buffer until
+// they are added. The default is the number of cells of the square.
+func WithIngestionQueue(size int) IncrementalOption {
+	return func(cfg *incrementalConfig) {
+		cfg.queueSize = size
+	}
+}
+
+// NewIncrementalSolver returns a solver for the square committed to by
+// rowRoots and colRoots, starting without any shares.
+func NewIncrementalSolver(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...IncrementalOption,
+) (*IncrementalSolver, error) {
+	width := uint(len(rowRoots))
+	if width == 0 || width%2 != 0 || uint(len(colRoots)) != width {
+		return nil, fmt.Errorf("got %d row and %d column roots, expected the same even number", len(rowRoots), len(colRoots))
+	}
+	cfg := incrementalConfig{queueSize: int(width * width)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.queueSize <= 0 {
+		return nil, fmt.Errorf("ingestion queue size must be positive, got %d", cfg.queueSize)
+	}
+	return &IncrementalSolver{
+		rowRoots:      rowRoots,
+		colRoots:      colRoots,
+		codec:         codec,
+		treeCreatorFn: treeCreatorFn,
+		width:         width,
+		queue:         make(chan CellProof, cfg.queueSize),
+		data:          make([][]byte, width*width),
+		present:       newBitMatrix(int(width)),
+		proven:        newBitMatrix(int(width)),
+		conflicted:    newBitMatrix(int(width)),
+	}, nil
+}
+
+// AddShare verifies the proof of a share against the roots and adds the
+// share at its cell. It fails with ErrInvalidShareProof if the proof does not
+// verify; the proof must not be modified afterwards. A share supplied again
+// for a cell is only counted as a duplicate, and if it differs from the
+// present proven share it is discarded and ErrShareEquivocation is returned.
+func (s *IncrementalSolver) AddShare(proof CellProof) error {
+	if err := proof.Verify(s.rowRoots, s.colRoots, s.treeCreatorFn); err != nil {
+		s.mu.Lock()
+		s.stats.Rejected++
+		s.mu.Unlock()
+		return err
+	}
+	return s.addVerified(proof)
+}
+
+// addVerified adds the share of a proof that has already been verified.
+func (s *IncrementalSolver) addVerified(proof CellProof) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(proof.Coord, proof.Share(), true)
+}
+
+// AddTrustedShare adds the share at coord without a proof, for shares the
+// caller trusts, such as those read from its own storage. Trusted shares are
+// only verified when the square is solved, when mismatching roots fail
+// Solve; share must not be modified afterwards.
+func (s *IncrementalSolver) AddTrustedShare(coord Coordinate, share []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(coord, share, false)
+}
+
+// TryAddShare queues a proven share to be verified and added, without
+// blocking. It fails with ErrIngestionQueueFull if the queue is full, and
+// immediately for out of range cells. The proof must not be modified
+// afterwards.
+func (s *IncrementalSolver) TryAddShare(proof CellProof) error {
+	coord := proof.Coord
+	if coord.Row >= s.width || coord.Col >= s.width {
+		return fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, s.width)
+	}
+	select {
+	case s.queue <- proof:
+		return nil
+	default:
+		s.mu.Lock()
+		s.stats.Dropped++
+		s.mu.Unlock()
+		return ErrIngestionQueueFull
+	}
+}
+
+// Drain verifies and adds the shares queued by TryAddShare. Invalid shares
+// are counted as rejected in the stats.
+func (s *IncrementalSolver) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+}
+
+// Stats returns the counters of the solver.
+func (s *IncrementalSolver) Stats() IncrementalStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Queued = len(s.queue)
+	return stats
+}
+
+// Equivocations returns the first conflicting pair of proven shares supplied
+// for each cell, in the order they were detected. Shares still queued by
+// TryAddShare are only checked once drained.
+func (s *IncrementalSolver) Equivocations() []*ErrShareEquivocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ErrShareEquivocation(nil), s.conflicts...)
+}
+
+func (s *IncrementalSolver) drain() {
+	for {
+		select {
+		case proof := <-s.queue:
+			if err := proof.Verify(s.rowRoots, s.colRoots, s.treeCreatorFn); err != nil {
+				s.stats.Rejected++
+				continue
+			}
+			_ = s.add(proof.Coord, proof.Share(), true)
+		default:
+			return
+		}
+	}
+}
+
+// add adds the share at coord, proven if its proof has been verified.
+func (s *IncrementalSolver) add(coord Coordinate, share []byte, proven bool) error {
+	if coord.Row >= s.width || coord.Col >= s.width {
+		s.stats.Rejected++
+		return fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, s.width)
+	}
+	if len(share) > 0 && proven && !s.sizeProven {
+		if s.chunkSize != len(share) {
+			s.evictTrusted()
+			s.chunkSize = len(share)
+		}
+		s.sizeProven = true
+	}
+	if s.chunkSize == 0 {
+		s.chunkSize = len(share)
+	}
+	if len(share) == 0 || len(share) != s.chunkSize {
+		s.stats.Rejected++
+		return fmt.Errorf("%w: share (%d, %d) has %d bytes, expected %d",
+			ErrInvalidChunkSize, coord.Row, coord.Col, len(share), s.chunkSize)
+	}
+	r, c := int(coord.Row), int(coord.Col)
+	if s.present.Get(r, c) {
+		present := s.data[coord.Row*s.width+coord.Col]
+		presentProven := s.proven.Get(r, c)
+		if proven {
+			s.proven.Set(r, c)
+		}
+		if bytes.Equal(present, share) {
+			s.stats.Duplicates++
+			return nil
+		}
+		s.stats.Conflicts++
+		switch {
+		case proven && !presentProven:
+			// The trusted share was wrong.
+			s.data[coord.Row*s.width+coord.Col] = share
+			return nil
+		case proven && presentProven:
+			conflict := &ErrShareEquivocation{Coord: coord, First: present, Second: share}
+			if !s.conflicted.Get(r, c) {
+				s.conflicted.Set(r, c)
+				s.conflicts = append(s.conflicts, conflict)
+			}
+			return conflict
+		}
+		return fmt.Errorf("trusted share for cell (%d, %d) differs from the share already added", coord.Row, coord.Col)
+	}
+	s.data[coord.Row*s.width+coord.Col] = share
+	s.present.Set(r, c)
+	if proven {
+		s.proven.Set(r, c)
+	}
+	s.stats.Added++
+	return nil
+}
+
+// evictTrusted removes the trusted shares, whose chunk size differs from the
+// size of the proven shares.
+func (s *IncrementalSolver) evictTrusted() {
+	for i := range s.data {
+		r, c := i/int(s.width), i%int(s.width)
+		if s.present.Get(r, c) && !s.proven.Get(r, c) {
+			s.data[i] = nil
+			s.present.Clear(r, c)
+			s.stats.Added--
+			s.stats.Evicted++
+		}
+	}
+}
+
+// IsPresent reports whether the share at coord has been added.
+func (s *IncrementalSolver) IsPresent(coord Coordinate) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return coord.Row < s.width && coord.Col < s.width && s.present.Get(int(coord.Row), int(coord.Col))
+}
+
+// Repairable reports whether the shares added so far, including queued ones,
+// suffice to repair the square by decoding rows and columns.
+func (s *IncrementalSolver) Repairable() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+	return isComplete(completeCrossword(s.present, int(s.width/2)))
+}
+
+// Solve repairs the square from the shares added so far, including queued
+// ones, with the given repair options. Once it has succeeded, the same square
+// is returned by every call.
+func (s *IncrementalSolver) Solve(opts ...RepairOption) (*ExtendedDataSquare, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+	if s.solved != nil {
+		return s.solved, nil
+	}
+	data := make([][]byte, len(s.data))
+	copy(data, s.data)
+	eds, err := RepairExtendedDataSquare(s.rowRoots, s.colRoots, data, s.codec, s.treeCreatorFn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.solved = eds
+	return eds, nil
+}
+
+// presence returns a copy of the mask of present cells, including queued
+// ones.
+func (s *IncrementalSolver) presence() bitMatrix {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drain()
+	return s.present.copy()
+}
+
+// completeCrossword returns the cells known once every row and column with
+// at least k of the cells in mask has been decoded, repeatedly, as done by
+// the crossword solver.
+func completeCrossword(mask bitMatrix, k int) bitMatrix {
+	known := mask.copy()
+	width := known.squareSize
+	for progress := true; progress; {
+		progress = false
+		for i := 0; i < width; i++ {
+			if n := known.NumOnesInRow(i); n >= k && n < width {
+				for j := 0; j < width; j++ {
+					known.Set(i, j)
+				}
+				progress = true
+			}
+			if n := known.NumOnesInCol(i); n >= k && n < width {
+				for j := 0; j < width; j++ {
+					known.Set(j, i)
+				}
+				progress = true
+			}
+		}
+	}
+	return known
+}
+
+// isComplete reports whether every cell of mask is set.
+func isComplete(mask bitMatrix) bool {
+	for i := 0; i < mask.squareSize; i++ {
+		if !mask.RowIsOne(i) {
+			return false
+		}
+	}
+	return true
+}
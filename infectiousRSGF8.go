@@ -1,85 +1,211 @@
 package rsmt2d
 
 import (
+	"fmt"
+	"runtime"
+	"sync"
+
 	"github.com/vivint/infectious"
 )
 
 var _ Codec = &rsGF8Codec{}
+var _ BufferedCodec = &rsGF8Codec{}
+var _ ErrorCorrectingCodec = &rsGF8Codec{}
+var _ KernelReporter = &rsGF8Codec{}
+
+// rsGF8Kernel is the kernel infectious dispatches to, which has AVX2 and
+// SSSE3 assembly on amd64 only.
+var rsGF8Kernel = func() string {
+	if runtime.GOARCH != "amd64" {
+		return KernelGeneric
+	}
+	return bestKernel(cpuFeatures, KernelAVX2, KernelSSSE3)
+}()
 
 func init() {
 	registerCodec("RSGF8", NewRSGF8Codec())
 }
 
 type rsGF8Codec struct {
+	mu              sync.Mutex
 	infectiousCache map[int]*infectious.FEC
+	// scratch holds *[]byte buffers for flattening encoder input.
+	scratch sync.Pool
 }
 
-// NewRSGF8Codec issues a new cached RSGF8Codec
+// NewRSGF8Codec issues a new cached RSGF8Codec. The codec is safe for
+// concurrent use.
 func NewRSGF8Codec() *rsGF8Codec {
-	return &rsGF8Codec{make(map[int]*infectious.FEC)}
+	return &rsGF8Codec{infectiousCache: make(map[int]*infectious.FEC)}
 }
 
-// Encode uses uses the infectous RSGF8 codec to encode the provided data
-func (c *rsGF8Codec) Encode(data [][]byte) ([][]byte, error) {
-	var fec *infectious.FEC
-	var err error
-	if value, ok := c.infectiousCache[len(data)]; ok {
-		fec = value
-	} else {
-		fec, err = infectious.NewFEC(len(data), len(data)*2)
-		if err != nil {
-			return nil, err
-		}
+// fec returns the cached FEC for k data shares, creating it if needed.
+// FECs are immutable and can be shared between goroutines.
+func (c *rsGF8Codec) fec(k int) (*infectious.FEC, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		c.infectiousCache[len(data)] = fec
+	if fec, ok := c.infectiousCache[k]; ok {
+		return fec, nil
+	}
+	fec, err := infectious.NewFEC(k, k*2)
+	if err != nil {
+		return nil, err
 	}
+	c.infectiousCache[k] = fec
+	return fec, nil
+}
 
+// Encode uses uses the infectous RSGF8 codec to encode the provided data
+func (c *rsGF8Codec) Encode(data [][]byte) ([][]byte, error) {
 	shares := make([][]byte, len(data))
-	output := func(s infectious.Share) {
-		if s.Number >= len(data) {
-			shareData := make([]byte, len(data[0]))
-			copy(shareData, s.Data)
-			shares[s.Number-len(data)] = shareData
-		}
+	for i := range shares {
+		shares[i] = make([]byte, len(data[0]))
+	}
+	if err := c.EncodeInto(shares, data); err != nil {
+		return nil, err
 	}
+	return shares, nil
+}
 
-	flattened := flattenChunks(data)
-	err = fec.Encode(flattened, output)
+// EncodeInto writes the parity shares of data into dst, which must hold
+// len(data) chunks of the same size as the data chunks.
+func (c *rsGF8Codec) EncodeInto(dst [][]byte, data [][]byte) error {
+	if len(dst) != len(data) {
+		return fmt.Errorf("destination holds %d chunks, expected %d", len(dst), len(data))
+	}
+	fec, err := c.fec(len(data))
+	if err != nil {
+		return &ErrBackendFailure{Cause: err}
+	}
 
-	return shares, err
+	flattened := c.flatten(data)
+	defer c.scratch.Put(flattened)
+	for i := range dst {
+		if err := fec.EncodeSingle(*flattened, dst[i], len(data)+i); err != nil {
+			return &ErrBackendFailure{Cause: err}
+		}
+	}
+	return nil
 }
 
 // Decode uses uses the infectous RSGF8 codec to decode the provided data
 func (c *rsGF8Codec) Decode(data [][]byte) ([][]byte, error) {
-	var fec *infectious.FEC
-	var err error
-	if value, ok := c.infectiousCache[len(data)/2]; ok {
-		fec = value
-	} else {
-		fec, err = infectious.NewFEC(len(data)/2, len(data))
-		if err != nil {
-			return nil, err
+	var chunkSize int
+	for _, d := range data {
+		if d != nil {
+			chunkSize = len(d)
+			break
 		}
-
-		c.infectiousCache[len(data)/2] = fec
 	}
-
 	rebuiltShares := make([][]byte, len(data)/2)
-	rebuiltSharesOutput := func(s infectious.Share) {
-		rebuiltShares[s.Number] = s.DeepCopy().Data
+	for i := range rebuiltShares {
+		rebuiltShares[i] = make([]byte, chunkSize)
+	}
+	err := c.DecodeInto(rebuiltShares, data)
+
+	return rebuiltShares, err
+}
+
+// DecodeInto writes the len(data)/2 original shares rebuilt from data into
+// dst, which must hold chunks of the same size as the present shares.
+func (c *rsGF8Codec) DecodeInto(dst [][]byte, data [][]byte) error {
+	if len(dst) != len(data)/2 {
+		return fmt.Errorf("destination holds %d chunks, expected %d", len(dst), len(data)/2)
+	}
+	fec, err := c.fec(len(data) / 2)
+	if err != nil {
+		return &ErrBackendFailure{Cause: err}
 	}
 
 	shares := []infectious.Share{}
 	for j := 0; j < len(data); j++ {
 		if data[j] != nil {
+			if len(data[j]) != len(dst[0]) {
+				return fmt.Errorf("%w: share %d has size %d, expected %d", ErrShardSizeMismatch, j, len(data[j]), len(dst[0]))
+			}
 			shares = append(shares, infectious.Share{Number: j, Data: data[j]})
 		}
 	}
-	err = fec.Rebuild(shares, rebuiltSharesOutput)
+	if len(shares) < len(dst) {
+		return ErrTooFewShards
+	}
+	err = fec.Rebuild(shares, func(s infectious.Share) {
+		copy(dst[s.Number], s.Data)
+	})
+	if err != nil {
+		return &ErrBackendFailure{Cause: err}
+	}
+	return nil
+}
 
-	return rebuiltShares, err
+// Correct rebuilds the original shares from data like Decode, correcting
+// corrupted shares with the Berlekamp-Welch algorithm.
+func (c *rsGF8Codec) Correct(data [][]byte) ([][]byte, error) {
+	fec, err := c.fec(len(data) / 2)
+	if err != nil {
+		return nil, &ErrBackendFailure{Cause: err}
+	}
+
+	// Correct fixes shares in place, so it works on copies.
+	var shares []infectious.Share
+	for j, d := range data {
+		if d == nil {
+			continue
+		}
+		if len(shares) > 0 && len(d) != len(shares[0].Data) {
+			return nil, fmt.Errorf("%w: share %d has size %d, expected %d", ErrShardSizeMismatch, j, len(d), len(shares[0].Data))
+		}
+		shares = append(shares, infectious.Share{Number: j, Data: append([]byte(nil), d...)})
+	}
+	if len(shares) < len(data)/2 {
+		return nil, ErrTooFewShards
+	}
+	// Berlekamp-Welch fails on too many errors, which is a property of the
+	// shares rather than of the backend.
+	if err := fec.Correct(shares); err != nil {
+		return nil, err
+	}
+	rebuiltShares := make([][]byte, len(data)/2)
+	for i := range rebuiltShares {
+		rebuiltShares[i] = make([]byte, len(shares[0].Data))
+	}
+	err = fec.Rebuild(shares, func(s infectious.Share) {
+		copy(rebuiltShares[s.Number], s.Data)
+	})
+	if err != nil {
+		return nil, &ErrBackendFailure{Cause: err}
+	}
+	return rebuiltShares, nil
+}
+
+// flatten concatenates chunks into a scratch buffer, which must be returned
+// to c.scratch once no longer used.
+func (c *rsGF8Codec) flatten(chunks [][]byte) *[]byte {
+	buf, _ := c.scratch.Get().(*[]byte)
+	if buf == nil {
+		buf = new([]byte)
+	}
+	*buf = (*buf)[:0]
+	for _, chunk := range chunks {
+		*buf = append(*buf, chunk...)
+	}
+	return buf
 }
 
+// HealthCheck encodes and decodes a tiny square.
+func (c *rsGF8Codec) HealthCheck() error {
+	return healthCheck(c)
+}
+
+// Kernel returns the kernel the codec dispatches to on this CPU.
+func (c *rsGF8Codec) Kernel() string {
+	return rsGF8Kernel
+}
+
+// gf256 marks the codec as encoding linearly over GF(2^8).
+func (c *rsGF8Codec) gf256() {}
+
 // maxChunks returns the max. number of chunks each code supports in a 2D square.
 func (c *rsGF8Codec) maxChunks() int {
 	return 128 * 128
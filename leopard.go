@@ -5,31 +5,82 @@
 // Otherwise go-leopard won't build.
 package rsmt2d
 
-import "github.com/lazyledger/go-leopard"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lazyledger/go-leopard"
+)
 
 var _ Codec = leoRSFF8Codec{}
 var _ Codec = leoRSFF16Codec{}
+var _ ChunkSizeLimits = leoRSFF8Codec{}
+var _ ChunkSizeLimits = leoRSFF16Codec{}
+var _ KernelReporter = leoRSFF8Codec{}
+var _ KernelReporter = leoRSFF16Codec{}
+
+// leopardKernel is the kernel Leopard dispatches to at runtime.
+var leopardKernel = bestKernel(cpuFeatures, KernelAVX2, KernelSSSE3, KernelNEON)
+
+// leopardChunkSizeMultiple is the block size Leopard codes chunks in.
+const leopardChunkSizeMultiple = 64
 
 func init() {
 	registerCodec(LeopardFF8, newLeoRSFF8Codec())
 	registerCodec(LeopardFF16, newLeoRSFF16Codec())
 }
 
+// leopardError maps the errors of go-leopard to the codec errors of the
+// package.
+func leopardError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, leopard.ErrNeedMoreData):
+		return ErrTooFewShards
+	case errors.Is(err, leopard.ErrInvalidSize):
+		return fmt.Errorf("%w: %v", ErrShardSizeMismatch, err)
+	default:
+		return &ErrBackendFailure{Cause: err}
+	}
+}
+
 type leoRSFF8Codec struct{}
 
 func (l leoRSFF8Codec) Encode(data [][]byte) ([][]byte, error) {
-	return leopard.Encode(data)
+	parity, err := leopard.Encode(data)
+	return parity, leopardError(err)
 }
 
 func (l leoRSFF8Codec) Decode(data [][]byte) ([][]byte, error) {
 	half := len(data) / 2
-	return leopard.Decode(data[:half], data[half:])
+	original, err := leopard.Decode(data[:half], data[half:])
+	return original, leopardError(err)
+}
+
+// HealthCheck encodes and decodes a tiny square, which fails if the native
+// library is broken.
+func (l leoRSFF8Codec) HealthCheck() error {
+	return healthCheck(l)
+}
+
+// Kernel returns the kernel Leopard dispatches to on this CPU.
+func (l leoRSFF8Codec) Kernel() string {
+	return leopardKernel
 }
 
 func (l leoRSFF8Codec) maxChunks() int {
 	return 128 * 128
 }
 
+func (l leoRSFF8Codec) MaxChunkSize() int {
+	return 0
+}
+
+func (l leoRSFF8Codec) ChunkSizeMultiple() int {
+	return leopardChunkSizeMultiple
+}
+
 func newLeoRSFF8Codec() leoRSFF8Codec {
 	return leoRSFF8Codec{}
 }
@@ -37,18 +88,39 @@ func newLeoRSFF8Codec() leoRSFF8Codec {
 type leoRSFF16Codec struct{}
 
 func (leo leoRSFF16Codec) Encode(data [][]byte) ([][]byte, error) {
-	return leopard.Encode(data)
+	parity, err := leopard.Encode(data)
+	return parity, leopardError(err)
 }
 
 func (leo leoRSFF16Codec) Decode(data [][]byte) ([][]byte, error) {
 	half := len(data) / 2
-	return leopard.Decode(data[:half], data[half:])
+	original, err := leopard.Decode(data[:half], data[half:])
+	return original, leopardError(err)
+}
+
+// HealthCheck encodes and decodes a tiny square, which fails if the native
+// library is broken.
+func (leo leoRSFF16Codec) HealthCheck() error {
+	return healthCheck(leo)
+}
+
+// Kernel returns the kernel Leopard dispatches to on this CPU.
+func (leo leoRSFF16Codec) Kernel() string {
+	return leopardKernel
 }
 
 func (leo leoRSFF16Codec) maxChunks() int {
 	return 32768 * 32768
 }
 
+func (leo leoRSFF16Codec) MaxChunkSize() int {
+	return 0
+}
+
+func (leo leoRSFF16Codec) ChunkSizeMultiple() int {
+	return leopardChunkSizeMultiple
+}
+
 func newLeoRSFF16Codec() leoRSFF16Codec {
 	return leoRSFF16Codec{}
 }
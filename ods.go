@@ -0,0 +1,177 @@
+package rsmt2d
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxCodecNameLength bounds the codec name read by ReadODS.
+const maxCodecNameLength = 64
+
+// odsChecksums is the header flag of ODS encodings in which every chunk is
+// followed by its checksum.
+const odsChecksums = 1 << 0
+
+// ODSOption configures WriteODS.
+type ODSOption func(*odsConfig)
+
+type odsConfig struct {
+	checksums bool
+}
+
+// WithShareChecksums makes WriteODS follow every chunk with its checksum, see
+// ShareChecksum, which ReadODS checks before extending the square.
+func WithShareChecksums() ODSOption {
+	return func(cfg *odsConfig) {
+		cfg.checksums = true
+	}
+}
+
+// WriteODS writes the original data square (ODS) of eds, the top-left
+// quadrant, to w. Parity is not written since it can be recomputed, which
+// makes this the most compact representation for persisting a square.
+//
+// The format is a version byte followed by the codec name, the width of the
+// ODS, the chunk size and flags, then the chunks of the ODS in row-major
+// order, each followed by its big-endian CRC-32C checksum if the odsChecksums
+// flag is set. The name is prefixed with its length, and all integers are
+// unsigned varints. Version 1 had no flags.
+func (eds *ExtendedDataSquare) WriteODS(w io.Writer, opts ...ODSOption) error {
+	var cfg odsConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if eds.codec == nil {
+		return errors.New("square has no codec")
+	}
+	if err := eds.validate(); err != nil {
+		return err
+	}
+	name, err := codecName(eds.codec)
+	if err != nil {
+		return err
+	}
+
+	header := []byte{ODSFormatVersion}
+	header = appendBytes(header, []byte(name))
+	header = appendUvarint(header, uint64(eds.originalDataWidth))
+	header = appendUvarint(header, uint64(eds.chunkSize))
+	var flags uint64
+	if cfg.checksums {
+		flags |= odsChecksums
+	}
+	header = appendUvarint(header, flags)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	for i := uint(0); i < eds.originalDataWidth; i++ {
+		for _, chunk := range eds.rowSlice(i, 0, eds.originalDataWidth) {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			if cfg.checksums {
+				var sum [4]byte
+				binary.BigEndian.PutUint32(sum[:], ShareChecksum(chunk))
+				if _, err := w.Write(sum[:]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ReadODS reads an original data square written by WriteODS and extends it
+// with the codec recorded in the header. If the chunks have checksums, all of
+// them are read before failing with ErrChecksumMismatch listing the corrupted
+// cells.
+func ReadODS(r io.Reader, treeCreatorFn TreeConstructorFn) (*ExtendedDataSquare, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		buffered := bufio.NewReader(r)
+		r, br = buffered, buffered
+	}
+
+	version, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if !FormatODS.IsCompatible(uint(version)) {
+		return nil, fmt.Errorf("unsupported ODS format version %d", version)
+	}
+	nameLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if nameLen > maxCodecNameLength {
+		return nil, errMalformedEncoding
+	}
+	name := make([]byte, nameLen)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return nil, err
+	}
+	codec, ok := codecs[string(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q", name)
+	}
+
+	width, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if width > uint64(codec.maxChunks()) || !IsValidWidth(int(width), codec) {
+		return nil, fmt.Errorf("invalid ODS width %d for codec %s", width, name)
+	}
+	if chunkSize == 0 {
+		return nil, fmt.Errorf("%w: chunks must not be empty", ErrInvalidChunkSize)
+	}
+	var flags uint64
+	if version >= 2 {
+		if flags, err = binary.ReadUvarint(br); err != nil {
+			return nil, err
+		}
+		if flags&^odsChecksums != 0 {
+			return nil, fmt.Errorf("unknown ODS flags %#x", flags)
+		}
+	}
+
+	// Buffers, and the list of chunks, grow as data arrives, so that a
+	// corrupt header cannot trigger a huge allocation.
+	var data [][]byte
+	var mismatched []Coordinate
+	for i := 0; uint64(i) < width*width; i++ {
+		var chunk bytes.Buffer
+		if _, err := io.CopyN(&chunk, r, int64(chunkSize)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		data = append(data, chunk.Bytes())
+
+		if flags&odsChecksums != 0 {
+			var sum [4]byte
+			if _, err := io.ReadFull(r, sum[:]); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, err
+			}
+			if binary.BigEndian.Uint32(sum[:]) != ShareChecksum(data[i]) {
+				mismatched = append(mismatched, Coordinate{Row: uint(i) / uint(width), Col: uint(i) % uint(width)})
+			}
+		}
+	}
+	if mismatched != nil {
+		return nil, &ErrChecksumMismatch{Cells: mismatched}
+	}
+	return ComputeExtendedDataSquare(data, codec, treeCreatorFn)
+}
@@ -0,0 +1,104 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestODSRoundTrip(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	if err := eds.WriteODS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	assert.Less(t, buf.Len(), len(eds.flattened())*int(eds.ChunkSize())/3)
+
+	data := buf.Bytes()
+	loaded, err := ReadODS(bytes.NewReader(data), NewDefaultTree)
+	if assert.NoError(t, err) {
+		assert.Equal(t, eds.flattened(), loaded.flattened())
+		assert.Equal(t, eds.RowRoots(), loaded.RowRoots())
+	}
+
+	_, err = ReadODS(bytes.NewReader(data[:len(data)-1]), NewDefaultTree)
+	assert.Error(t, err)
+
+	data[0] = ODSFormatVersion + 1
+	_, err = ReadODS(bytes.NewReader(data), NewDefaultTree)
+	assert.Error(t, err)
+}
+
+func TestReadODSRejectsHugeHeader(t *testing.T) {
+	header := []byte{ODSFormatVersion}
+	header = appendBytes(header, []byte("RSGF8"))
+	header = appendUvarint(header, 2)
+	header = appendUvarint(header, 1<<62)
+	_, err := ReadODS(bytes.NewReader(header), NewDefaultTree)
+	assert.Error(t, err)
+
+	// The largest width is only allocated for as its chunks arrive.
+	header = []byte{ODSFormatVersion}
+	header = appendBytes(header, []byte("RSGF8"))
+	header = appendUvarint(header, 128)
+	header = appendUvarint(header, 1)
+	header = appendUvarint(header, 0)
+	_, err = ReadODS(bytes.NewReader(append(header, 1, 2, 3)), NewDefaultTree)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestODSChecksums(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	if err := eds.WriteODS(&buf, WithShareChecksums()); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	loaded, err := ReadODS(bytes.NewReader(data), NewDefaultTree)
+	if assert.NoError(t, err) {
+		assert.Equal(t, eds.flattened(), loaded.flattened())
+	}
+
+	// Flip a bit in the chunks of cells (0, 1) and (2, 3).
+	record := int(eds.ChunkSize()) + 4
+	header := len(data) - 16*record
+	data[header+1*record] ^= 1
+	data[header+11*record+5] ^= 1
+	_, err = ReadODS(bytes.NewReader(data), NewDefaultTree)
+	var mismatch *ErrChecksumMismatch
+	if assert.True(t, errors.As(err, &mismatch), "expected ErrChecksumMismatch, got %v", err) {
+		assert.Equal(t, []Coordinate{{Row: 0, Col: 1}, {Row: 2, Col: 3}}, mismatch.Cells)
+	}
+}
+
+func TestReadODSVersion1(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	data := []byte{1}
+	data = appendBytes(data, []byte("RSGF8"))
+	data = appendUvarint(data, 2)
+	data = appendUvarint(data, uint64(eds.ChunkSize()))
+	for _, chunk := range eds.flattened()[:2] {
+		data = append(data, chunk...)
+	}
+	for _, chunk := range eds.flattened()[4:6] {
+		data = append(data, chunk...)
+	}
+	loaded, err := ReadODS(bytes.NewReader(data), NewDefaultTree)
+	if assert.NoError(t, err) {
+		assert.Equal(t, eds.RowRoots(), loaded.RowRoots())
+	}
+}
@@ -0,0 +1,164 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ordering describes how the shares of a square are laid out in a flat slice.
+type Ordering int
+
+const (
+	// RowMajor lists the shares row by row. It is the ordering used by the
+	// rest of the package.
+	RowMajor Ordering = iota
+	// ColumnMajor lists the shares column by column.
+	ColumnMajor
+	// QuadrantMajor lists the quadrants of an extended square in the order
+	// original data, row parity, column parity, parity of parity, each in
+	// row-major order. The original data thus forms a prefix of the slice.
+	QuadrantMajor
+)
+
+func (o Ordering) String() string {
+	switch o {
+	case RowMajor:
+		return "row-major"
+	case ColumnMajor:
+		return "column-major"
+	case QuadrantMajor:
+		return "quadrant-major"
+	default:
+		return fmt.Sprintf("Ordering(%d)", int(o))
+	}
+}
+
+// CellIndex returns the position of cell (r, c) of a square of the given
+// width in a slice laid out in this ordering. The width must be supported by
+// the ordering, that is even for QuadrantMajor, and the cell in range.
+func (o Ordering) CellIndex(width uint, r uint, c uint) uint {
+	switch o {
+	case ColumnMajor:
+		return c*width + r
+	case QuadrantMajor:
+		half := width / 2
+		return uint(QuadrantOf(width, Coordinate{Row: r, Col: c}))*half*half + (r%half)*half + c%half
+	default:
+		return r*width + c
+	}
+}
+
+// CoordOf returns the cell at position i of a slice laid out in this
+// ordering holding a square of the given width. It is the inverse of
+// CellIndex, with the same restrictions.
+func (o Ordering) CoordOf(width uint, i uint) Coordinate {
+	switch o {
+	case ColumnMajor:
+		return Coordinate{Row: i % width, Col: i / width}
+	case QuadrantMajor:
+		half := width / 2
+		origin := QuadrantOrigin(width, int(i/(half*half)))
+		i %= half * half
+		return Coordinate{Row: origin.Row + i/half, Col: origin.Col + i%half}
+	default:
+		return Coordinate{Row: i / width, Col: i % width}
+	}
+}
+
+// QuadrantOf returns the quadrant of an extended square of the given width
+// holding coord: 0 for the original data, 1 for its row parity, 2 for its
+// column parity and 3 for the parity of parity.
+func QuadrantOf(width uint, coord Coordinate) int {
+	half := width / 2
+	q := 0
+	if coord.Row >= half {
+		q += 2
+	}
+	if coord.Col >= half {
+		q++
+	}
+	return q
+}
+
+// QuadrantOrigin returns the top-left cell of quadrant q, numbered as by
+// QuadrantOf, of an extended square of the given width.
+func QuadrantOrigin(width uint, q int) Coordinate {
+	half := width / 2
+	return Coordinate{Row: uint(q/2) * half, Col: uint(q%2) * half}
+}
+
+// validate checks that the ordering can lay out a square of the given width.
+func (o Ordering) validate(width uint) error {
+	switch o {
+	case RowMajor, ColumnMajor:
+		return nil
+	case QuadrantMajor:
+		if width%2 != 0 {
+			return errors.New("quadrant-major ordering requires an even square width")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown ordering %v", o)
+	}
+}
+
+// ReorderShares returns the shares of a square laid out in ordering from,
+// rearranged into ordering to. The shares themselves are not copied.
+func ReorderShares(data [][]byte, from Ordering, to Ordering) ([][]byte, error) {
+	n, err := squareWidth(len(data))
+	if err != nil {
+		return nil, err
+	}
+	width := uint(n)
+	if err := from.validate(width); err != nil {
+		return nil, err
+	}
+	if err := to.validate(width); err != nil {
+		return nil, err
+	}
+
+	reordered := make([][]byte, len(data))
+	for r := uint(0); r < width; r++ {
+		for c := uint(0); c < width; c++ {
+			reordered[to.CellIndex(width, r, c)] = data[from.CellIndex(width, r, c)]
+		}
+	}
+	return reordered, nil
+}
+
+// ImportOption configures ImportExtendedDataSquare.
+type ImportOption func(*importConfig)
+
+type importConfig struct {
+	ordering    Ordering
+	checksums   []uint32
+	parallelism int
+	lazy        bool
+}
+
+// ImportParallelism sets the number of goroutines checking and laying out
+// the imported chunks, which pays off for squares with tens of thousands of
+// cells. The default is 1.
+func ImportParallelism(n int) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.parallelism = n
+	}
+}
+
+// ImportLazyValidation defers checking that all imported chunks have the
+// same size to the first call of Validate, for callers importing squares
+// they produced themselves. VerifyRoots, ConformsTo and WriteODS call
+// Validate; other methods assume the square is valid.
+func ImportLazyValidation() ImportOption {
+	return func(cfg *importConfig) {
+		cfg.lazy = true
+	}
+}
+
+// ImportOrdering sets the ordering of the imported shares. The default is
+// RowMajor.
+func ImportOrdering(o Ordering) ImportOption {
+	return func(cfg *importConfig) {
+		cfg.ordering = o
+	}
+}
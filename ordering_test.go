@@ -0,0 +1,130 @@
+package rsmt2d
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportOrdering(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	f := eds.flattened()
+
+	tests := []struct {
+		ordering Ordering
+		data     [][]byte
+	}{
+		{RowMajor, f},
+		{ColumnMajor, [][]byte{
+			f[0], f[4], f[8], f[12],
+			f[1], f[5], f[9], f[13],
+			f[2], f[6], f[10], f[14],
+			f[3], f[7], f[11], f[15],
+		}},
+		{QuadrantMajor, [][]byte{
+			f[0], f[1], f[4], f[5],
+			f[2], f[3], f[6], f[7],
+			f[8], f[9], f[12], f[13],
+			f[10], f[11], f[14], f[15],
+		}},
+	}
+	for _, tt := range tests {
+		imported, err := ImportExtendedDataSquare(tt.data, codec, NewDefaultTree, ImportOrdering(tt.ordering))
+		if assert.NoError(t, err, tt.ordering.String()) {
+			assert.Equal(t, f, imported.flattened(), tt.ordering.String())
+		}
+
+		reordered, err := ReorderShares(f, RowMajor, tt.ordering)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.data, reordered, tt.ordering.String())
+	}
+
+	_, err = ReorderShares(genRandDS(3), QuadrantMajor, RowMajor)
+	assert.Error(t, err)
+	_, err = ReorderShares(genRandDS(2)[:3], ColumnMajor, RowMajor)
+	assert.Error(t, err)
+}
+
+func TestOrderingCellIndex(t *testing.T) {
+	const width = 4
+	for _, o := range []Ordering{RowMajor, ColumnMajor, QuadrantMajor} {
+		seen := make(map[uint]bool)
+		for r := uint(0); r < width; r++ {
+			for c := uint(0); c < width; c++ {
+				i := o.CellIndex(width, r, c)
+				assert.Less(t, i, uint(width*width), o.String())
+				assert.False(t, seen[i], o.String())
+				seen[i] = true
+				assert.Equal(t, Coordinate{Row: r, Col: c}, o.CoordOf(width, i), o.String())
+			}
+		}
+	}
+	assert.Equal(t, uint(6), QuadrantMajor.CellIndex(width, 1, 2))
+	assert.Equal(t, Coordinate{Row: 3, Col: 0}, QuadrantMajor.CoordOf(width, 10))
+}
+
+func TestQuadrantOf(t *testing.T) {
+	assert.Equal(t, 0, QuadrantOf(4, Coordinate{Row: 1, Col: 1}))
+	assert.Equal(t, 1, QuadrantOf(4, Coordinate{Row: 0, Col: 2}))
+	assert.Equal(t, 2, QuadrantOf(4, Coordinate{Row: 3, Col: 0}))
+	assert.Equal(t, 3, QuadrantOf(4, Coordinate{Row: 2, Col: 3}))
+	for q := 0; q < 4; q++ {
+		assert.Equal(t, q, QuadrantOf(4, QuadrantOrigin(4, q)))
+	}
+	assert.Equal(t, Coordinate{Row: 2, Col: 0}, QuadrantOrigin(4, 2))
+}
+
+func TestImportParallelism(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(16), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	imported, err := ImportExtendedDataSquare(eds.flattened(), codec, NewDefaultTree, ImportParallelism(4))
+	if assert.NoError(t, err) {
+		assert.Equal(t, eds.flattened(), imported.flattened())
+		assert.Equal(t, eds.Col(5), imported.Col(5))
+		assert.Equal(t, eds.RowRoots(), imported.RowRoots())
+	}
+
+	data := eds.flattened()
+	data[700] = data[700][1:]
+	_, err = ImportExtendedDataSquare(data, codec, NewDefaultTree, ImportParallelism(4))
+	assert.ErrorIs(t, err, ErrInvalidChunkSize)
+}
+
+func TestImportLazyValidation(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	imported, err := ImportExtendedDataSquare(eds.flattened(), codec, NewDefaultTree, ImportLazyValidation())
+	if assert.NoError(t, err) {
+		// Concurrent first reads validate the square once.
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, imported.Validate())
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, imported.VerifyRoots(eds.RowRoots(), eds.ColRoots()))
+	}
+
+	data := eds.flattened()
+	data[5] = data[5][1:]
+	imported, err = ImportExtendedDataSquare(data, codec, NewDefaultTree, ImportLazyValidation())
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, imported.Validate(), ErrInvalidChunkSize)
+		assert.ErrorIs(t, imported.VerifyRoots(eds.RowRoots(), eds.ColRoots()), ErrInvalidChunkSize)
+	}
+}
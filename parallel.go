@@ -0,0 +1,134 @@
+package rsmt2d
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ExtendOption configures ComputeExtendedDataSquare.
+type ExtendOption func(*extendConfig)
+
+type extendConfig struct {
+	codingParallelism  int
+	hashingParallelism int
+	executor           Executor
+	autoParallelism    bool
+	profileCtx         context.Context
+}
+
+// WithCodingParallelism sets the number of goroutines encoding parity. The
+// default is 1. The codec must be safe for concurrent use if n is above 1.
+func WithCodingParallelism(n int) ExtendOption {
+	return func(cfg *extendConfig) {
+		cfg.codingParallelism = n
+	}
+}
+
+// WithHashingParallelism sets the number of goroutines computing the row and
+// column roots of the square. See SetHashingParallelism.
+func WithHashingParallelism(n int) ExtendOption {
+	return func(cfg *extendConfig) {
+		cfg.hashingParallelism = n
+	}
+}
+
+// SetHashingParallelism sets the number of goroutines computing the row and
+// column roots of the square. By default, roots computed on demand are
+// computed sequentially and PrecomputeRoots uses GOMAXPROCS goroutines.
+// Hashing usually scales with the number of cores better than coding does,
+// so the two are configured separately.
+func (eds *ExtendedDataSquare) SetHashingParallelism(n int) {
+	eds.hashingParallelism = n
+}
+
+// Executor runs the tasks the package splits parallel work into. A custom
+// executor lets integrators pin workers to cores, bound concurrency across
+// all squares, or integrate with their own scheduler. Go may run the task
+// on the calling goroutine or queue it, but every task must eventually run.
+type Executor interface {
+	Go(task func())
+}
+
+// goroutineExecutor runs every task on a new goroutine.
+type goroutineExecutor struct{}
+
+func (goroutineExecutor) Go(task func()) {
+	go task()
+}
+
+// WithExecutor sets the executor running the parallel work of the square.
+// See SetExecutor.
+func WithExecutor(e Executor) ExtendOption {
+	return func(cfg *extendConfig) {
+		cfg.executor = e
+	}
+}
+
+// SetExecutor sets the executor running the parallel work of the square,
+// such as encoding with WithCodingParallelism and computing roots. By default
+// every task runs on a new goroutine.
+func (eds *ExtendedDataSquare) SetExecutor(e Executor) {
+	eds.executor = e
+}
+
+// parallelFor calls f for every i in [0, n) using up to the given number of
+// tasks run by exec, and returns the first error encountered. The remaining
+// calls are skipped once an error occurs. A nil exec runs tasks on new
+// goroutines.
+func parallelFor(exec Executor, workers int, n uint, f func(i uint) error) error {
+	if workers <= 1 {
+		for i := uint(0); i < n; i++ {
+			if err := f(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if exec == nil {
+		exec = goroutineExecutor{}
+	}
+
+	var (
+		next     uint64
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	// Indices are claimed from a shared counter, so that executors running
+	// tasks inline or one at a time still complete all of them.
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		exec.Go(func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddUint64(&next, 1) - 1
+				if i >= uint64(n) {
+					return
+				}
+				if err := f(uint(i)); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					// Stop all tasks from claiming further indices.
+					atomic.StoreUint64(&next, uint64(n))
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// hashingWorkers returns the number of goroutines computing roots in the
+// background.
+func (ds *dataSquare) hashingWorkers() int {
+	if ds.hashingParallelism > 0 {
+		return ds.hashingParallelism
+	}
+	return runtime.GOMAXPROCS(0)
+}
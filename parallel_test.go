@@ -0,0 +1,138 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelExtension(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(8)
+	want, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, workers := range []int{2, 5} {
+		eds, err := ComputeExtendedDataSquare(
+			data,
+			codec,
+			NewDefaultTree,
+			WithCodingParallelism(workers),
+			WithHashingParallelism(workers),
+		)
+		if assert.NoError(t, err) {
+			assert.Equal(t, want.flattened(), eds.flattened())
+			assert.Equal(t, want.RowRoots(), eds.RowRoots())
+			assert.Equal(t, want.ColRoots(), eds.ColRoots())
+		}
+	}
+}
+
+func TestParallelFor(t *testing.T) {
+	for _, workers := range []int{0, 1, 4} {
+		var sum int64
+		err := parallelFor(nil, workers, 100, func(i uint) error {
+			atomic.AddInt64(&sum, int64(i))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4950), sum)
+
+		errStop := errors.New("stop")
+		err = parallelFor(nil, workers, 100, func(i uint) error {
+			if i == 10 {
+				return errStop
+			}
+			return nil
+		})
+		assert.Equal(t, errStop, err)
+	}
+}
+
+// inlineExecutor runs tasks on the calling goroutine and counts them.
+type inlineExecutor struct {
+	tasks int64
+}
+
+func (e *inlineExecutor) Go(task func()) {
+	atomic.AddInt64(&e.tasks, 1)
+	task()
+}
+
+func TestExecutor(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(4)
+	want, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	exec := &inlineExecutor{}
+	eds, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree, WithCodingParallelism(2), WithExecutor(exec))
+	if assert.NoError(t, err) {
+		assert.Equal(t, want.flattened(), eds.flattened())
+		assert.Equal(t, int64(4), atomic.LoadInt64(&exec.tasks))
+	}
+
+	eds.SetHashingParallelism(2)
+	eds.PrecomputeRoots(context.Background())
+	assert.NoError(t, eds.WaitRoots())
+	assert.Equal(t, want.RowRoots(), eds.RowRoots())
+	assert.Equal(t, int64(6), atomic.LoadInt64(&exec.tasks))
+}
+
+func TestAutoParallelism(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(4)
+	want, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	eds, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree, WithAutoParallelism())
+	if assert.NoError(t, err) {
+		assert.Equal(t, want.flattened(), eds.flattened())
+		assert.Equal(t, want.RowRoots(), eds.RowRoots())
+		assert.GreaterOrEqual(t, eds.codingParallelism, 1)
+		assert.GreaterOrEqual(t, eds.hashingParallelism, 1)
+	}
+
+	tunings.Lock()
+	_, cached := tunings.m[tuneKey{codec: "*rsmt2d.rsGF8Codec", width: 4, chunkSize: eds.chunkSize}]
+	tunings.Unlock()
+	assert.True(t, cached)
+
+	eds, err = ComputeExtendedDataSquare(data, codec, NewDefaultTree, WithAutoParallelism(), WithCodingParallelism(3))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, eds.codingParallelism)
+	}
+
+	assert.Equal(t, []int{1, 2, 4, 6}, parallelismCandidates(6))
+	assert.Equal(t, []int{1}, parallelismCandidates(1))
+
+	// Concurrent callers for the same key share a single benchmark.
+	results := make([]tuning, 4)
+	errs := make([]error, len(results))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = autoParallelism(codec, 2, 16, NewDefaultTree)
+		}(i)
+	}
+	wg.Wait()
+	for i, r := range results {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, results[0], r)
+	}
+	tunings.Lock()
+	assert.Empty(t, tunings.inflight)
+	tunings.Unlock()
+}
@@ -0,0 +1,80 @@
+package rsmt2d
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+)
+
+// profilingLabels is non-zero when hot paths are labelled for profiling.
+var profilingLabels int32
+
+// SetProfilingLabels enables or disables pprof labels on encoding, decoding
+// and tree building, so that CPU profiles attribute time to the operation,
+// codec and square width. Labelling allocates, so it is disabled by default.
+// The labels are added to those of the profiling context of the square, see
+// SetProfilingContext, which are restored once the operation is done.
+func SetProfilingLabels(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&profilingLabels, v)
+}
+
+// WithProfilingContext sets the profiling context of the square. See
+// SetProfilingContext.
+func WithProfilingContext(ctx context.Context) ExtendOption {
+	return func(cfg *extendConfig) {
+		cfg.profileCtx = ctx
+	}
+}
+
+// WithRepairProfilingContext sets the profiling context of the repaired
+// square. See SetProfilingContext.
+func WithRepairProfilingContext(ctx context.Context) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.profileCtx = ctx
+	}
+}
+
+// SetProfilingContext sets the context whose pprof labels the labels of the
+// operations on the square are added to, when enabled with
+// SetProfilingLabels. Labelled operations leave the goroutine with the labels
+// of ctx, so a caller labelling its goroutine with pprof.Do keeps its labels
+// by passing the context given to its function. The default is
+// context.Background, which has no labels.
+func (eds *ExtendedDataSquare) SetProfilingContext(ctx context.Context) {
+	eds.profileCtx = ctx
+}
+
+// profile runs f, labelled with the operation, codec and width if profiling
+// labels are enabled, on top of the labels of ctx, which may be nil. codec may
+// be nil for operations that do not use one.
+func profile(ctx context.Context, op string, codec Codec, width uint, f func()) {
+	if atomic.LoadInt32(&profilingLabels) == 0 {
+		f()
+		return
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pprof.Do(ctx, pprof.Labels(profileLabels(op, codec, width)...), func(context.Context) {
+		f()
+	})
+}
+
+// profileLabels returns the label key-value pairs of an operation.
+func profileLabels(op string, codec Codec, width uint) []string {
+	labels := []string{"rsmt2d_op", op, "rsmt2d_width", strconv.FormatUint(uint64(width), 10)}
+	if codec != nil {
+		name, err := codecName(codec)
+		if err != nil {
+			name = "unknown"
+		}
+		labels = append(labels, "rsmt2d_codec", name)
+	}
+	return labels
+}
@@ -0,0 +1,39 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	assert.Equal(t,
+		[]string{"rsmt2d_op", "encode", "rsmt2d_width", "8", "rsmt2d_codec", "RSGF8"},
+		profileLabels("encode", NewRSGF8Codec(), 8),
+	)
+	assert.Equal(t, []string{"rsmt2d_op", "tree", "rsmt2d_width", "4"}, profileLabels("tree", nil, 4))
+
+	for _, enabled := range []bool{true, false} {
+		SetProfilingLabels(enabled)
+		called := false
+		profile(nil, "tree", nil, 8, func() { called = true })
+		assert.True(t, called)
+	}
+}
+
+func TestProfileKeepsCallerLabels(t *testing.T) {
+	SetProfilingLabels(true)
+	defer SetProfilingLabels(false)
+
+	pprof.Do(context.Background(), pprof.Labels("caller", "test"), func(ctx context.Context) {
+		profile(ctx, "tree", nil, 8, func() {})
+		var goroutines bytes.Buffer
+		if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 1); err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, goroutines.String(), `"caller":"test"`)
+	})
+}
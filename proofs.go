@@ -0,0 +1,689 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ProofFormatVersion is the version of the binary and JSON encodings of
+// CellProof, AxisRootProof and BadEncodingProof. Encodings start with the
+// version so that verifiers can reject formats they do not understand.
+//
+// The binary encoding of a proof is the version byte, a byte identifying the
+// proof type, and the fields of the proof in declaration order. Integers are
+// unsigned varints, byte strings and lists are prefixed with their length as
+// an unsigned varint, and an Axis is encoded as 0 for rows and 1 for columns.
+const ProofFormatVersion = 1
+
+// Proof type identifiers of the binary encoding.
+const (
+	cellProofType        byte = 1
+	axisRootProofType    byte = 2
+	badEncodingProofType byte = 3
+)
+
+// ErrUnsupportedProofVersion is returned when decoding a proof encoded with a
+// different ProofFormatVersion.
+var ErrUnsupportedProofVersion = errors.New("unsupported proof format version")
+
+// ErrInvalidBadEncodingProof is returned when a bad encoding proof does not
+// demonstrate that an axis was incorrectly encoded.
+var ErrInvalidBadEncodingProof = errors.New("invalid bad encoding proof")
+
+// CellProof proves that a share is included at a coordinate of the square,
+// against the root of the row or column (Axis) containing the cell.
+type CellProof struct {
+	Coord Coordinate `json:"coord"`
+	Axis  Axis       `json:"axis"`
+	Proof Proof      `json:"proof"`
+}
+
+// ProveCellOnAxis returns a proof of the cell against the root of the given
+// axis through it.
+func (eds *ExtendedDataSquare) ProveCellOnAxis(coord Coordinate, axis Axis) (CellProof, error) {
+	if coord.Row >= eds.width || coord.Col >= eds.width {
+		return CellProof{}, fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, eds.width)
+	}
+	var proof Proof
+	var err error
+	if axis == RowAxis {
+		proof, err = eds.proveRowCell(coord.Row, coord.Col)
+	} else {
+		proof, err = eds.proveColCell(coord.Row, coord.Col)
+	}
+	if err != nil {
+		return CellProof{}, err
+	}
+	return CellProof{Coord: coord, Axis: axis, Proof: proof}, nil
+}
+
+// ProveCells returns proofs of the cells against the roots of the given axis,
+// in the order of coords, for answering a batch of sample requests. Cells on
+// the same row or column share one tree, and the trees of different rows or
+// columns are built on up to parallelism goroutines. A non-positive
+// parallelism uses GOMAXPROCS goroutines.
+func (eds *ExtendedDataSquare) ProveCells(coords []Coordinate, axis Axis, parallelism int) ([]CellProof, error) {
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	// groups maps an axis index to the positions in coords of its cells.
+	groups := make(map[uint][]int)
+	var indexes []uint
+	for i, coord := range coords {
+		if coord.Row >= eds.width || coord.Col >= eds.width {
+			return nil, fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, eds.width)
+		}
+		index := coord.Row
+		if axis == ColAxis {
+			index = coord.Col
+		}
+		if groups[index] == nil {
+			indexes = append(indexes, index)
+		}
+		groups[index] = append(groups[index], i)
+	}
+
+	proofs := make([]CellProof, len(coords))
+	err := parallelFor(eds.executor, parallelism, uint(len(indexes)), func(i uint) error {
+		index := indexes[i]
+		cells := make([]uint, len(groups[index]))
+		for j, pos := range groups[index] {
+			cells[j] = coords[pos].Col
+			if axis == ColAxis {
+				cells[j] = coords[pos].Row
+			}
+		}
+		var axisProofs []Proof
+		var err error
+		if axis == RowAxis {
+			axisProofs, err = eds.ProveRowCells(index, cells)
+		} else {
+			axisProofs, err = eds.ProveColumnCells(index, cells)
+		}
+		if err != nil {
+			return err
+		}
+		for j, pos := range groups[index] {
+			proofs[pos] = CellProof{Coord: coords[pos], Axis: axis, Proof: axisProofs[j]}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
+
+// AxisNodes returns the nodes of the tree of a row or column, as returned by
+// NodeCachingTree.Nodes. Together with ProveFromNodes, they let proof servers
+// drop the shares of a square they do not serve often and fetch them from
+// elsewhere when needed, while still generating proofs without rehashing the
+// whole axis. The nodes are taken from the cache if SetTreeCaching is enabled.
+func (eds *ExtendedDataSquare) AxisNodes(axis Axis, index uint) ([][][]byte, error) {
+	if index >= eds.width {
+		return nil, fmt.Errorf("%v %d out of range for width %d", axis, index, eds.width)
+	}
+	if cache := eds.nodeCache; cache != nil {
+		nodes := cache.rows[index]
+		if axis == ColAxis {
+			nodes = cache.cols[index]
+		}
+		if nodes != nil {
+			return nodes, nil
+		}
+	}
+	var tree ProvableTree
+	var err error
+	if axis == RowAxis {
+		tree, err = eds.provableRowTree(index)
+	} else {
+		tree, err = eds.provableColTree(index)
+	}
+	if err != nil {
+		return nil, err
+	}
+	nc, ok := tree.(NodeCachingTree)
+	if !ok {
+		return nil, ErrTreeNotCaching
+	}
+	return nc.Nodes(), nil
+}
+
+// ProveFromNodes returns an inclusion proof of the share at index of an axis
+// from the nodes of the axis tree and that single share. It fails with
+// ErrInvalidShareProof if the share is not the one the nodes commit to.
+func ProveFromNodes(nodes [][][]byte, index uint, share []byte, treeCreatorFn TreeConstructorFn) (Proof, error) {
+	tree, ok := treeCreatorFn().(NodeCachingTree)
+	if !ok {
+		return Proof{}, ErrTreeNotCaching
+	}
+	if len(nodes) == 0 || index >= uint(len(nodes[0])) {
+		return Proof{}, fmt.Errorf("leaf index %d out of range for the tree nodes", index)
+	}
+	leaves := make([][]byte, len(nodes[0]))
+	leaves[index] = share
+	if err := tree.FromNodes(nodes, leaves); err != nil {
+		return Proof{}, err
+	}
+	proof, err := tree.Prove(index)
+	if err != nil {
+		return Proof{}, err
+	}
+	if !tree.VerifyProof(tree.Root(), proof) {
+		return Proof{}, ErrInvalidShareProof
+	}
+	return proof, nil
+}
+
+// Share returns the proven share.
+func (p *CellProof) Share() []byte {
+	if len(p.Proof.Set) == 0 {
+		return nil
+	}
+	return p.Proof.Set[0]
+}
+
+// Verify checks the proof against the row and column roots of the square.
+func (p *CellProof) Verify(rowRoots [][]byte, colRoots [][]byte, treeCreatorFn TreeConstructorFn) error {
+	width := uint(len(rowRoots))
+	if p.Coord.Row >= width || p.Coord.Col >= width || uint(len(colRoots)) != width {
+		return ErrInvalidShareProof
+	}
+	root := rowRoots[p.Coord.Row]
+	if p.Axis == ColAxis {
+		root = colRoots[p.Coord.Col]
+	}
+	return p.verifyAgainstRoot(root, width, treeCreatorFn)
+}
+
+// verifyAgainstRoot checks the proof against the root of its axis in a square
+// of the given width.
+func (p *CellProof) verifyAgainstRoot(root []byte, width uint, treeCreatorFn TreeConstructorFn) error {
+	tree, ok := treeCreatorFn().(ProvableTree)
+	if !ok {
+		return ErrTreeNotProvable
+	}
+	index := p.Coord.Col
+	if p.Axis == ColAxis {
+		index = p.Coord.Row
+	}
+	if p.Proof.Index != uint64(index) || p.Proof.NumLeaves != uint64(width) ||
+		len(p.Proof.Set) == 0 || !tree.VerifyProof(root, p.Proof) {
+		return ErrInvalidShareProof
+	}
+	return nil
+}
+
+// ProofMetadata classifies the cell proven by a CellProof within its square
+// and within the row or column it is proven against.
+type ProofMetadata struct {
+	// Quadrant is the quadrant of the cell, numbered as by QuadrantOf.
+	Quadrant int
+	// Parity is set if the cell is outside the original data quadrant.
+	Parity bool
+	// AxisParity is set if the cell is in the parity half of the row or
+	// column it is proven against.
+	AxisParity bool
+}
+
+// Metadata classifies the proven cell, taking the width of the square from
+// the number of leaves of the proof. The classification is determined by the
+// coordinate and width, which Verify checks, so it needs no encoding of its
+// own and cannot be forged separately.
+func (p *CellProof) Metadata() (ProofMetadata, error) {
+	width := uint(p.Proof.NumLeaves)
+	if width == 0 || width%2 != 0 || uint64(width) != p.Proof.NumLeaves ||
+		p.Coord.Row >= width || p.Coord.Col >= width {
+		return ProofMetadata{}, ErrInvalidShareProof
+	}
+	half := width / 2
+	position := p.Coord.Col
+	if p.Axis == ColAxis {
+		position = p.Coord.Row
+	}
+	quadrant := QuadrantOf(width, p.Coord)
+	return ProofMetadata{
+		Quadrant:   quadrant,
+		Parity:     quadrant != 0,
+		AxisParity: position >= half,
+	}, nil
+}
+
+// ShareValidator checks a proven share against its classification, for
+// example that parity shares use the reserved parity namespace when the
+// square is committed to by namespaced Merkle trees.
+type ShareValidator func(share []byte, meta ProofMetadata) error
+
+// VerifyShare checks the proof like Verify, then the proven share with
+// validate. A nil validate only checks the proof.
+func (p *CellProof) VerifyShare(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	treeCreatorFn TreeConstructorFn,
+	validate ShareValidator,
+) error {
+	if err := p.Verify(rowRoots, colRoots, treeCreatorFn); err != nil {
+		return err
+	}
+	if validate == nil {
+		return nil
+	}
+	meta, err := p.Metadata()
+	if err != nil {
+		return err
+	}
+	return validate(p.Share(), meta)
+}
+
+// MarshalBinary encodes the proof.
+func (p *CellProof) MarshalBinary() ([]byte, error) {
+	return appendCellProof([]byte{ProofFormatVersion, cellProofType}, p), nil
+}
+
+// UnmarshalBinary decodes a proof encoded with MarshalBinary.
+func (p *CellProof) UnmarshalBinary(data []byte) error {
+	d, err := proofDecoder(data, cellProofType)
+	if err != nil {
+		return err
+	}
+	proof := d.cellProof()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalJSON encodes the proof as a JSON object carrying the format version.
+func (p CellProof) MarshalJSON() ([]byte, error) {
+	type plain CellProof
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		plain
+	}{ProofFormatVersion, plain(p)})
+}
+
+// UnmarshalJSON decodes a proof encoded with MarshalJSON.
+func (p *CellProof) UnmarshalJSON(data []byte) error {
+	type plain CellProof
+	v := struct {
+		Version int `json:"version"`
+		*plain
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return checkProofVersion(v.Version)
+}
+
+// AxisRootProof proves that a row or column root is committed to by a data
+// root, as computed by DataRoot.
+type AxisRootProof struct {
+	Axis  Axis   `json:"axis"`
+	Index uint   `json:"index"`
+	Root  []byte `json:"root"`
+	Proof Proof  `json:"proof"`
+}
+
+// ProveAxisRoot returns a proof of the root of a row or column against the
+// data root of the square.
+func (eds *ExtendedDataSquare) ProveAxisRoot(axis Axis, index uint) (AxisRootProof, error) {
+	rowRoots, colRoots := eds.getRowRoots(), eds.getColRoots()
+	proof, err := proveAxisRoot(rowRoots, colRoots, axis, index)
+	if err != nil {
+		return AxisRootProof{}, err
+	}
+	root := rowRoots[index]
+	if axis == ColAxis {
+		root = colRoots[index]
+	}
+	return AxisRootProof{Axis: axis, Index: index, Root: root, Proof: proof}, nil
+}
+
+// Verify checks the proof against the data root of a square of the given
+// width.
+func (p *AxisRootProof) Verify(dataRoot []byte, width uint) error {
+	leaf := uint64(p.Index)
+	if p.Axis == ColAxis {
+		leaf += uint64(width)
+	}
+	tree := NewDefaultTree().(ProvableTree)
+	if p.Index >= width || p.Proof.Index != leaf || p.Proof.NumLeaves != 2*uint64(width) ||
+		len(p.Proof.Set) == 0 || !bytes.Equal(p.Proof.Set[0], p.Root) ||
+		!tree.VerifyProof(dataRoot, p.Proof) {
+		return fmt.Errorf("%v root %d is not committed to by the data root", p.Axis, p.Index)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the proof.
+func (p *AxisRootProof) MarshalBinary() ([]byte, error) {
+	b := []byte{ProofFormatVersion, axisRootProofType}
+	b = appendUvarint(b, uint64(p.Axis))
+	b = appendUvarint(b, uint64(p.Index))
+	b = appendBytes(b, p.Root)
+	return appendProof(b, p.Proof), nil
+}
+
+// UnmarshalBinary decodes a proof encoded with MarshalBinary.
+func (p *AxisRootProof) UnmarshalBinary(data []byte) error {
+	d, err := proofDecoder(data, axisRootProofType)
+	if err != nil {
+		return err
+	}
+	proof := AxisRootProof{
+		Axis:  d.axis(),
+		Index: uint(d.uvarint()),
+		Root:  d.bytes(),
+		Proof: d.proof(),
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalJSON encodes the proof as a JSON object carrying the format version.
+func (p AxisRootProof) MarshalJSON() ([]byte, error) {
+	type plain AxisRootProof
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		plain
+	}{ProofFormatVersion, plain(p)})
+}
+
+// UnmarshalJSON decodes a proof encoded with MarshalJSON.
+func (p *AxisRootProof) UnmarshalJSON(data []byte) error {
+	type plain AxisRootProof
+	v := struct {
+		Version int `json:"version"`
+		*plain
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return checkProofVersion(v.Version)
+}
+
+// BadEncodingProof proves that a row or column was not correctly erasure
+// coded. It holds shares of the axis, each proven against the root of the
+// orthogonal axis through it. At least half of the shares of the axis are
+// required to re-encode it.
+type BadEncodingProof struct {
+	Axis   Axis        `json:"axis"`
+	Index  uint        `json:"index"`
+	Shares []CellProof `json:"shares"`
+}
+
+// NewBadEncodingProof builds a bad encoding proof for a row or column of
+// eds from the shares at the given positions along the axis.
+func NewBadEncodingProof(eds *ExtendedDataSquare, axis Axis, index uint, positions []uint) (*BadEncodingProof, error) {
+	if index >= eds.width {
+		return nil, fmt.Errorf("%v %d out of range for width %d", axis, index, eds.width)
+	}
+	proof := &BadEncodingProof{Axis: axis, Index: index, Shares: make([]CellProof, len(positions))}
+	for i, pos := range positions {
+		coord := Coordinate{Row: index, Col: pos}
+		if axis == ColAxis {
+			coord = Coordinate{Row: pos, Col: index}
+		}
+		share, err := eds.ProveCellOnAxis(coord, axis.other())
+		if err != nil {
+			return nil, err
+		}
+		proof.Shares[i] = share
+	}
+	return proof, nil
+}
+
+// byzantineAxis returns the axis reported by an ErrByzantineRow or
+// ErrByzantineCol and its shares.
+func byzantineAxis(err error) (Axis, uint, [][]byte, error) {
+	var (
+		byzRow *ErrByzantineRow
+		byzCol *ErrByzantineCol
+	)
+	switch {
+	case errors.As(err, &byzRow):
+		return RowAxis, byzRow.RowNumber, byzRow.Shares, nil
+	case errors.As(err, &byzCol):
+		return ColAxis, byzCol.ColNumber, byzCol.Shares, nil
+	}
+	return 0, 0, nil, fmt.Errorf("no evidence for error: %v", err)
+}
+
+// EvidenceFor returns the smallest bad encoding proof for the axis reported
+// by an ErrByzantineRow or ErrByzantineCol. Since the proof is checked
+// against the committed root of the axis, any k of its shares suffice, where
+// k is the original width; the first k shares the error reports are used.
+// The shares are proven against the orthogonal axes of eds, which must hold
+// them and match the committed roots, such as a complete square imported
+// with ImportExtendedDataSquare. RepairExtendedDataSquare returns no square
+// on failure; see EvidenceFromProofs for its errors.
+func (eds *ExtendedDataSquare) EvidenceFor(err error) (*BadEncodingProof, error) {
+	axis, index, shares, err := byzantineAxis(err)
+	if err != nil {
+		return nil, err
+	}
+	if uint(len(shares)) != eds.width {
+		return nil, fmt.Errorf("%v %d has %d shares, expected %d", axis, index, len(shares), eds.width)
+	}
+
+	var positions []uint
+	for pos, share := range shares {
+		if share == nil {
+			continue
+		}
+		coord := Coordinate{Row: index, Col: uint(pos)}
+		if axis == ColAxis {
+			coord = Coordinate{Row: uint(pos), Col: index}
+		}
+		if !bytes.Equal(eds.getCell(coord.Row, coord.Col), share) {
+			return nil, fmt.Errorf("share (%d, %d) differs from the square", coord.Row, coord.Col)
+		}
+		positions = append(positions, uint(pos))
+		if uint(len(positions)) == eds.originalDataWidth {
+			return NewBadEncodingProof(eds, axis, index, positions)
+		}
+	}
+	return nil, fmt.Errorf("%v %d has %d shares, %d needed", axis, index, len(positions), eds.originalDataWidth)
+}
+
+// EvidenceFromProofs returns the smallest bad encoding proof for the axis
+// reported by an ErrByzantineRow or ErrByzantineCol, such as one returned by
+// RepairExtendedDataSquare, from the proofs that came with the shares given
+// for repair. The first k reported shares with a proof against their
+// orthogonal axis are used, k being the original width; other proofs are
+// ignored. The proofs are not verified here, see BadEncodingProof.Verify.
+func EvidenceFromProofs(err error, proofs []CellProof) (*BadEncodingProof, error) {
+	axis, index, shares, err := byzantineAxis(err)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) == 0 || len(shares)%2 != 0 {
+		return nil, fmt.Errorf("%v %d has %d shares", axis, index, len(shares))
+	}
+
+	proven := make(map[Coordinate]CellProof, len(proofs))
+	for _, proof := range proofs {
+		if proof.Axis == axis.other() {
+			proven[proof.Coord] = proof
+		}
+	}
+	k := len(shares) / 2
+	evidence := &BadEncodingProof{Axis: axis, Index: index}
+	for pos, share := range shares {
+		coord := Coordinate{Row: index, Col: uint(pos)}
+		if axis == ColAxis {
+			coord = Coordinate{Row: uint(pos), Col: index}
+		}
+		proof, ok := proven[coord]
+		if share == nil || !ok || !bytes.Equal(proof.Share(), share) {
+			continue
+		}
+		evidence.Shares = append(evidence.Shares, proof)
+		if len(evidence.Shares) == k {
+			return evidence, nil
+		}
+	}
+	return nil, fmt.Errorf("%v %d has %d proven shares, %d needed", axis, index, len(evidence.Shares), k)
+}
+
+// Verify checks that the proof demonstrates an incorrectly encoded axis: the
+// shares are committed to by the orthogonal roots, but re-encoding them does
+// not reproduce both the shares and the committed root of the axis. If the
+// codec fails to decode the shares, such as with ErrBackendFailure, the
+// codec's error is returned and the proof is not accepted.
+func (p *BadEncodingProof) Verify(rowRoots [][]byte, colRoots [][]byte, codec Codec, treeCreatorFn TreeConstructorFn) error {
+	width := uint(len(rowRoots))
+	if p.Index >= width || uint(len(colRoots)) != width {
+		return fmt.Errorf("%w: %v %d out of range for width %d", ErrInvalidBadEncodingProof, p.Axis, p.Index, width)
+	}
+
+	shares := make([][]byte, width)
+	present := uint(0)
+	for _, s := range p.Shares {
+		pos, index := s.Coord.Col, s.Coord.Row
+		if p.Axis == ColAxis {
+			pos, index = s.Coord.Row, s.Coord.Col
+		}
+		if s.Axis != p.Axis.other() || index != p.Index {
+			return fmt.Errorf("%w: share (%d, %d) is not proven against the orthogonal axis", ErrInvalidBadEncodingProof, s.Coord.Row, s.Coord.Col)
+		}
+		if err := s.Verify(rowRoots, colRoots, treeCreatorFn); err != nil {
+			return fmt.Errorf("%w: share (%d, %d): %v", ErrInvalidBadEncodingProof, s.Coord.Row, s.Coord.Col, err)
+		}
+		if shares[pos] == nil {
+			present++
+		}
+		shares[pos] = s.Share()
+	}
+	if present < width/2 {
+		return fmt.Errorf("%w: %d shares given, %d needed", ErrInvalidBadEncodingProof, present, width/2)
+	}
+
+	// The shares of a correctly encoded axis all have the same size.
+	size := -1
+	for _, share := range shares {
+		if share == nil {
+			continue
+		}
+		if size >= 0 && len(share) != size {
+			return nil
+		}
+		size = len(share)
+	}
+
+	original, err := codec.Decode(shares)
+	if err != nil {
+		// Enough committed shares of the same size always decode, so this is
+		// a failure of the codec, which proves nothing about the axis.
+		return fmt.Errorf("decoding %v %d: %w", p.Axis, p.Index, err)
+	}
+	parity, err := codec.Encode(original)
+	if err != nil {
+		return err
+	}
+	rebuilt := append(original, parity[len(parity)-len(original):]...)
+
+	tree := treeCreatorFn()
+	for i, share := range rebuilt {
+		if shares[i] != nil && !bytes.Equal(shares[i], share) {
+			return nil
+		}
+		tree.Push(share, SquareIndex{Axis: p.Index, Cell: uint(i)})
+	}
+	root := rowRoots[p.Index]
+	if p.Axis == ColAxis {
+		root = colRoots[p.Index]
+	}
+	if bytes.Equal(tree.Root(), root) {
+		return fmt.Errorf("%w: %v %d is correctly encoded", ErrInvalidBadEncodingProof, p.Axis, p.Index)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the proof.
+func (p *BadEncodingProof) MarshalBinary() ([]byte, error) {
+	b := []byte{ProofFormatVersion, badEncodingProofType}
+	b = appendUvarint(b, uint64(p.Axis))
+	b = appendUvarint(b, uint64(p.Index))
+	b = appendUvarint(b, uint64(len(p.Shares)))
+	for i := range p.Shares {
+		b = appendCellProof(b, &p.Shares[i])
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a proof encoded with MarshalBinary.
+func (p *BadEncodingProof) UnmarshalBinary(data []byte) error {
+	d, err := proofDecoder(data, badEncodingProofType)
+	if err != nil {
+		return err
+	}
+	proof := BadEncodingProof{Axis: d.axis(), Index: uint(d.uvarint())}
+	proof.Shares = make([]CellProof, d.count())
+	for i := range proof.Shares {
+		proof.Shares[i] = d.cellProof()
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// MarshalJSON encodes the proof as a JSON object carrying the format version.
+func (p BadEncodingProof) MarshalJSON() ([]byte, error) {
+	type plain BadEncodingProof
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		plain
+	}{ProofFormatVersion, plain(p)})
+}
+
+// UnmarshalJSON decodes a proof encoded with MarshalJSON.
+func (p *BadEncodingProof) UnmarshalJSON(data []byte) error {
+	type plain BadEncodingProof
+	v := struct {
+		Version int `json:"version"`
+		*plain
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return checkProofVersion(v.Version)
+}
+
+func checkProofVersion(version int) error {
+	if version < 0 || !FormatProof.IsCompatible(uint(version)) {
+		return fmt.Errorf("%w: %d", ErrUnsupportedProofVersion, version)
+	}
+	return nil
+}
+
+// proofDecoder checks the header of a binary encoded proof and returns a
+// decoder positioned after it.
+func proofDecoder(data []byte, proofType byte) (*decoder, error) {
+	if len(data) < 2 {
+		return nil, errMalformedEncoding
+	}
+	if err := checkProofVersion(int(data[0])); err != nil {
+		return nil, err
+	}
+	if data[1] != proofType {
+		return nil, fmt.Errorf("%w: unexpected proof type %d", errMalformedEncoding, data[1])
+	}
+	return &decoder{buf: data[2:]}, nil
+}
+
+func appendCellProof(b []byte, p *CellProof) []byte {
+	b = appendUvarint(b, uint64(p.Coord.Row))
+	b = appendUvarint(b, uint64(p.Coord.Col))
+	b = appendUvarint(b, uint64(p.Axis))
+	return appendProof(b, p.Proof)
+}
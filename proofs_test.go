@@ -0,0 +1,302 @@
+package rsmt2d
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofEncodingRoundTrip(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	cellProof, err := eds.ProveCellOnAxis(Coordinate{Row: 2, Col: 5}, ColAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootProof, err := eds.ProveAxisRoot(ColAxis, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bep, err := NewBadEncodingProof(eds, RowAxis, 1, []uint{0, 2, 4, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		proof   encoding.BinaryMarshaler
+		decoded interface {
+			encoding.BinaryUnmarshaler
+			json.Unmarshaler
+		}
+	}{
+		{"cell", &cellProof, &CellProof{}},
+		{"axis root", &rootProof, &AxisRootProof{}},
+		{"bad encoding", bep, &BadEncodingProof{}},
+	}
+	for _, tt := range tests {
+		data, err := tt.proof.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Equal(t, byte(ProofFormatVersion), data[0])
+		assert.NoError(t, tt.decoded.UnmarshalBinary(data), tt.name)
+		assert.Equal(t, tt.proof, tt.decoded, tt.name)
+		assert.Error(t, tt.decoded.UnmarshalBinary(data[:len(data)-1]), tt.name)
+
+		data[0] = ProofFormatVersion + 1
+		assert.True(t, errors.Is(tt.decoded.UnmarshalBinary(data), ErrUnsupportedProofVersion), tt.name)
+
+		data, err = json.Marshal(tt.proof)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(data, tt.decoded), tt.name)
+		assert.Equal(t, tt.proof, tt.decoded, tt.name)
+	}
+
+	data, err := json.Marshal(cellProof)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"version":1,"coord":{"row":2,"col":5},"axis":"column"`)
+}
+
+func TestProofVerification(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+
+	cellProof, err := eds.ProveCellOnAxis(Coordinate{Row: 6, Col: 1}, ColAxis)
+	assert.NoError(t, err)
+	assert.NoError(t, cellProof.Verify(rowRoots, colRoots, NewDefaultTree))
+	assert.Equal(t, eds.getCell(6, 1), cellProof.Share())
+	cellProof.Coord.Col = 2
+	assert.Equal(t, ErrInvalidShareProof, cellProof.Verify(rowRoots, colRoots, NewDefaultTree))
+
+	rootProof, err := eds.ProveAxisRoot(RowAxis, 7)
+	assert.NoError(t, err)
+	assert.NoError(t, rootProof.Verify(eds.DataRoot(), eds.Width()))
+	rootProof.Axis = ColAxis
+	assert.Error(t, rootProof.Verify(eds.DataRoot(), eds.Width()))
+
+	// A correctly encoded row cannot be proven bad.
+	bep, err := NewBadEncodingProof(eds, RowAxis, 1, []uint{0, 2, 4, 6})
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(bep.Verify(rowRoots, colRoots, codec, NewDefaultTree), ErrInvalidBadEncodingProof))
+
+	// Corrupt a parity share of row 1 and commit to the corrupted square.
+	corrupted := eds.flattened()
+	corrupted[1*8+7] = make([]byte, len(corrupted[0]))
+	bad, err := ImportExtendedDataSquare(corrupted, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	bep, err = NewBadEncodingProof(bad, RowAxis, 1, []uint{0, 1, 2, 7})
+	assert.NoError(t, err)
+	assert.NoError(t, bep.Verify(bad.RowRoots(), bad.ColRoots(), codec, NewDefaultTree))
+
+	// A codec failing to decode does not make a proof of a correctly
+	// encoded row valid.
+	honest, err := NewBadEncodingProof(eds, RowAxis, 1, []uint{0, 2, 4, 6})
+	assert.NoError(t, err)
+	err = honest.Verify(rowRoots, colRoots, &failingCodec{Codec: codec}, NewDefaultTree)
+	var backend *ErrBackendFailure
+	assert.True(t, errors.As(err, &backend), "expected ErrBackendFailure, got %v", err)
+
+	bep.Shares = bep.Shares[:3]
+	assert.True(t, errors.Is(bep.Verify(bad.RowRoots(), bad.ColRoots(), codec, NewDefaultTree), ErrInvalidBadEncodingProof))
+}
+
+func TestEvidenceFor(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[2*8+5] = make([]byte, original.chunkSize)
+	bad, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := bad.RowRoots(), bad.ColRoots()
+
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, bad.flattened(), codec, NewDefaultTree)
+	var byzRow *ErrByzantineRow
+	if !assert.True(t, errors.As(err, &byzRow), "expected ErrByzantineRow, got %v", err) {
+		return
+	}
+	proof, err := bad.EvidenceFor(err)
+	if assert.NoError(t, err) {
+		assert.Equal(t, RowAxis, proof.Axis)
+		assert.Equal(t, uint(2), proof.Index)
+		assert.Len(t, proof.Shares, 4)
+		assert.NoError(t, proof.Verify(rowRoots, colRoots, codec, NewDefaultTree))
+	}
+
+	other, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	_, err = other.EvidenceFor(byzRow)
+	assert.Error(t, err)
+	_, err = bad.EvidenceFor(errors.New("other"))
+	assert.Error(t, err)
+}
+
+func TestEvidenceFromProofs(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[2*8+5] = make([]byte, original.chunkSize)
+	bad, err := ImportExtendedDataSquare(flattened, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := bad.RowRoots(), bad.ColRoots()
+
+	// A light node receives most shares with their proofs, and the repair
+	// of row 2 fails.
+	var proofs []CellProof
+	for i := range flattened {
+		coord := Coordinate{Row: uint(i) / 8, Col: uint(i) % 8}
+		if coord == (Coordinate{Row: 2, Col: 7}) || coord == (Coordinate{Row: 6, Col: 5}) {
+			flattened[i] = nil
+			continue
+		}
+		for _, axis := range []Axis{RowAxis, ColAxis} {
+			proof, err := bad.ProveCellOnAxis(coord, axis)
+			if err != nil {
+				panic(err)
+			}
+			proofs = append(proofs, proof)
+		}
+	}
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, flattened, codec, NewDefaultTree)
+	var byzRow *ErrByzantineRow
+	if !assert.True(t, errors.As(err, &byzRow), "expected ErrByzantineRow, got %v", err) {
+		return
+	}
+	proof, err := EvidenceFromProofs(err, proofs)
+	if assert.NoError(t, err) {
+		assert.Equal(t, RowAxis, proof.Axis)
+		assert.Equal(t, uint(2), proof.Index)
+		assert.Len(t, proof.Shares, 4)
+		assert.NoError(t, proof.Verify(rowRoots, colRoots, codec, NewDefaultTree))
+	}
+
+	_, err = EvidenceFromProofs(byzRow, proofs[:10])
+	assert.Error(t, err)
+	_, err = EvidenceFromProofs(errors.New("other"), proofs)
+	assert.Error(t, err)
+}
+
+func TestProveFromNodes(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+
+	for _, axis := range []Axis{RowAxis, ColAxis} {
+		nodes, err := eds.AxisNodes(axis, 3)
+		assert.NoError(t, err)
+		for i := uint(0); i < eds.Width(); i++ {
+			coord := Coordinate{Row: 3, Col: i}
+			if axis == ColAxis {
+				coord = Coordinate{Row: i, Col: 3}
+			}
+			proof, err := ProveFromNodes(nodes, i, eds.getCell(coord.Row, coord.Col), NewDefaultTree)
+			assert.NoError(t, err)
+			want, err := eds.ProveCellOnAxis(coord, axis)
+			assert.NoError(t, err)
+			assert.Equal(t, want.Proof, proof)
+		}
+
+		_, err = ProveFromNodes(nodes, 0, eds.getCell(5, 5), NewDefaultTree)
+		assert.Equal(t, ErrInvalidShareProof, err)
+		_, err = ProveFromNodes(nodes, eds.Width(), eds.getCell(5, 5), NewDefaultTree)
+		assert.Error(t, err)
+	}
+
+	_, err = eds.AxisNodes(RowAxis, eds.Width())
+	assert.Error(t, err)
+}
+
+func TestProveCells(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+	coords := []Coordinate{{Row: 2, Col: 5}, {Row: 7, Col: 0}, {Row: 2, Col: 1}, {Row: 3, Col: 5}, {Row: 2, Col: 5}}
+
+	for _, axis := range []Axis{RowAxis, ColAxis} {
+		proofs, err := eds.ProveCells(coords, axis, 3)
+		assert.NoError(t, err)
+		assert.Len(t, proofs, len(coords))
+		for i, proof := range proofs {
+			assert.Equal(t, coords[i], proof.Coord)
+			assert.Equal(t, axis, proof.Axis)
+			assert.NoError(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+			assert.Equal(t, eds.getCell(coords[i].Row, coords[i].Col), proof.Share())
+		}
+	}
+
+	_, err = eds.ProveCells([]Coordinate{{Row: 8, Col: 0}}, RowAxis, 0)
+	assert.Error(t, err)
+}
+
+func TestProofMetadata(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+	tests := []struct {
+		coord Coordinate
+		axis  Axis
+		want  ProofMetadata
+	}{
+		{Coordinate{Row: 1, Col: 2}, RowAxis, ProofMetadata{Quadrant: 0}},
+		{Coordinate{Row: 1, Col: 6}, RowAxis, ProofMetadata{Quadrant: 1, Parity: true, AxisParity: true}},
+		{Coordinate{Row: 1, Col: 6}, ColAxis, ProofMetadata{Quadrant: 1, Parity: true}},
+		{Coordinate{Row: 5, Col: 2}, ColAxis, ProofMetadata{Quadrant: 2, Parity: true, AxisParity: true}},
+		{Coordinate{Row: 7, Col: 7}, RowAxis, ProofMetadata{Quadrant: 3, Parity: true, AxisParity: true}},
+	}
+	for _, tt := range tests {
+		proof, err := eds.ProveCellOnAxis(tt.coord, tt.axis)
+		assert.NoError(t, err)
+		meta, err := proof.Metadata()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, meta, "%v on %v", tt.coord, tt.axis)
+
+		var got ProofMetadata
+		err = proof.VerifyShare(rowRoots, colRoots, NewDefaultTree, func(share []byte, meta ProofMetadata) error {
+			assert.Equal(t, eds.getCell(tt.coord.Row, tt.coord.Col), share)
+			got = meta
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	errParityNamespace := errors.New("parity share outside the parity namespace")
+	proof, err := eds.ProveCellOnAxis(Coordinate{Row: 6, Col: 0}, RowAxis)
+	assert.NoError(t, err)
+	err = proof.VerifyShare(rowRoots, colRoots, NewDefaultTree, func(share []byte, meta ProofMetadata) error {
+		if meta.Parity {
+			return errParityNamespace
+		}
+		return nil
+	})
+	assert.Equal(t, errParityNamespace, err)
+	assert.NoError(t, proof.VerifyShare(rowRoots, colRoots, NewDefaultTree, nil))
+
+	proof.Proof.NumLeaves = 7
+	_, err = proof.Metadata()
+	assert.Equal(t, ErrInvalidShareProof, err)
+}
@@ -0,0 +1,253 @@
+package rsmt2d
+
+import (
+	"container/list"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ProofServer answers inclusion proof requests for the cells of a complete
+// square, such as the samples of light clients: the server-side counterpart
+// of sampling. The trees of all rows and columns are built once, when the
+// server is created, and proofs are generated from them concurrently. It is
+// safe for concurrent use. The square must not be modified while it is
+// served.
+type ProofServer struct {
+	// Counters, accessed atomically and first for 64-bit alignment.
+	requests  uint64
+	cacheHits uint64
+	failures  uint64
+
+	eds   *ExtendedDataSquare
+	trees [2][]axisTree // indexed by Axis, then by row or column
+	cache *proofCache   // nil if caching is disabled
+}
+
+// axisTree is the tree of a row or column. Trees may cache hashes while
+// proving, so proofs from the same tree are generated one at a time.
+type axisTree struct {
+	mu   sync.Mutex
+	tree ProvableTree
+}
+
+// ProofServerOption configures a ProofServer.
+type ProofServerOption func(*proofServerConfig)
+
+type proofServerConfig struct {
+	cacheBytes  int
+	parallelism int
+}
+
+// WithProofCache keeps recently served proofs, up to a total size of
+// maxBytes, so that hot cells sampled by many clients are proven once.
+func WithProofCache(maxBytes int) ProofServerOption {
+	return func(cfg *proofServerConfig) {
+		cfg.cacheBytes = maxBytes
+	}
+}
+
+// WithProofServerParallelism sets the number of goroutines building the
+// trees of the square. The default is GOMAXPROCS.
+func WithProofServerParallelism(n int) ProofServerOption {
+	return func(cfg *proofServerConfig) {
+		cfg.parallelism = n
+	}
+}
+
+// ProofServerStats are the counters of a ProofServer.
+type ProofServerStats struct {
+	Requests     uint64 // Proofs requested
+	CacheHits    uint64 // Requests answered from the proof cache
+	Failures     uint64 // Requests that failed, such as for out of range cells
+	CachedProofs int    // Proofs in the cache
+	CachedBytes  int    // Total size of the cached proof sets
+}
+
+// NewProofServer returns a server of proofs of the cells of eds, building the
+// trees of all its rows and columns. It fails with ErrTreeNotProvable if the
+// trees of the square cannot produce proofs.
+func NewProofServer(eds *ExtendedDataSquare, opts ...ProofServerOption) (*ProofServer, error) {
+	cfg := proofServerConfig{parallelism: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &ProofServer{eds: eds}
+	s.trees[RowAxis] = make([]axisTree, eds.width)
+	s.trees[ColAxis] = make([]axisTree, eds.width)
+	err := parallelFor(eds.executor, cfg.parallelism, 2*eds.width, func(i uint) error {
+		var tree ProvableTree
+		var err error
+		if i < eds.width {
+			tree, err = eds.provableRowTree(i)
+			s.trees[RowAxis][i].tree = tree
+		} else {
+			tree, err = eds.provableColTree(i - eds.width)
+			s.trees[ColAxis][i-eds.width].tree = tree
+		}
+		if nc, ok := tree.(NodeCachingTree); ok {
+			// Hash the tree now rather than on its first request.
+			nc.Nodes()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.cacheBytes > 0 {
+		s.cache = newProofCache(cfg.cacheBytes)
+	}
+	return s, nil
+}
+
+// Prove returns a proof of the cell against the root of the given axis
+// through it.
+func (s *ProofServer) Prove(coord Coordinate, axis Axis) (CellProof, error) {
+	atomic.AddUint64(&s.requests, 1)
+	proof, err := s.prove(coord, axis)
+	if err != nil {
+		atomic.AddUint64(&s.failures, 1)
+		return CellProof{}, err
+	}
+	return CellProof{Coord: coord, Axis: axis, Proof: proof}, nil
+}
+
+// GetShareWithProof returns the share at (row, col) and a proof of it
+// against its row root, as requested by a sampling light client.
+func (s *ProofServer) GetShareWithProof(row uint, col uint) ([]byte, CellProof, error) {
+	proof, err := s.Prove(Coordinate{Row: row, Col: col}, RowAxis)
+	if err != nil {
+		return nil, CellProof{}, err
+	}
+	return s.eds.getCell(row, col), proof, nil
+}
+
+// Stats returns the counters of the server.
+func (s *ProofServer) Stats() ProofServerStats {
+	stats := ProofServerStats{
+		Requests:  atomic.LoadUint64(&s.requests),
+		CacheHits: atomic.LoadUint64(&s.cacheHits),
+		Failures:  atomic.LoadUint64(&s.failures),
+	}
+	if s.cache != nil {
+		stats.CachedProofs, stats.CachedBytes = s.cache.stats()
+	}
+	return stats
+}
+
+func (s *ProofServer) prove(coord Coordinate, axis Axis) (Proof, error) {
+	if coord.Row >= s.eds.width || coord.Col >= s.eds.width {
+		return Proof{}, fmt.Errorf("cell (%d, %d) out of range for width %d", coord.Row, coord.Col, s.eds.width)
+	}
+	if axis != RowAxis && axis != ColAxis {
+		return Proof{}, fmt.Errorf("invalid axis %d", axis)
+	}
+	key := newProofKey(coord, axis)
+	if s.cache != nil {
+		if proof, ok := s.cache.get(key); ok {
+			atomic.AddUint64(&s.cacheHits, 1)
+			return proof, nil
+		}
+	}
+
+	t := &s.trees[axis][key.index]
+	t.mu.Lock()
+	proof, err := t.tree.Prove(key.cell)
+	t.mu.Unlock()
+	if err != nil {
+		return Proof{}, err
+	}
+	if s.cache != nil {
+		s.cache.add(key, proof)
+	}
+	return proof, nil
+}
+
+// proofKey identifies a cached proof by the axis it is proven against, the
+// index of that row or column, and the position of the cell on it.
+type proofKey struct {
+	axis  Axis
+	index uint
+	cell  uint
+}
+
+func newProofKey(coord Coordinate, axis Axis) proofKey {
+	if axis == RowAxis {
+		return proofKey{axis: axis, index: coord.Row, cell: coord.Col}
+	}
+	return proofKey{axis: axis, index: coord.Col, cell: coord.Row}
+}
+
+// proofCache is a least-recently-used cache of proofs bounded by the total
+// size of their proof sets. Cached proofs are shared and must not be
+// modified.
+type proofCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	lru      *list.List // front is most recently used
+	entries  map[proofKey]*list.Element
+}
+
+type proofCacheEntry struct {
+	key   proofKey
+	proof Proof
+	size  int
+}
+
+func newProofCache(maxBytes int) *proofCache {
+	return &proofCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[proofKey]*list.Element),
+	}
+}
+
+func (c *proofCache) get(key proofKey) (Proof, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return Proof{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*proofCacheEntry).proof, true
+}
+
+// stats returns the number and total size of the cached proofs.
+func (c *proofCache) stats() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len(), c.size
+}
+
+// add inserts a proof and evicts the least recently used proofs until the
+// cache fits. Proofs larger than the whole cache are not stored.
+func (c *proofCache) add(key proofKey, proof Proof) {
+	size := 0
+	for _, node := range proof.Set {
+		size += len(node)
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		// Added concurrently by another request.
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&proofCacheEntry{key: key, proof: proof, size: size})
+	c.size += size
+
+	for c.size > c.maxBytes {
+		oldest := c.lru.Back()
+		entry := oldest.Value.(*proofCacheEntry)
+		c.lru.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+	}
+}
@@ -0,0 +1,102 @@
+package rsmt2d
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofServerCache(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+	first, err := eds.ProveCellOnAxis(Coordinate{Row: 1, Col: 2}, RowAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofSize := 0
+	for _, node := range first.Proof.Set {
+		proofSize += len(node)
+	}
+
+	// All proofs of a square of width 8 have the same size.
+	server, err := NewProofServer(eds, WithProofCache(2*proofSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := []struct {
+		coord Coordinate
+		axis  Axis
+	}{
+		{Coordinate{Row: 1, Col: 2}, RowAxis},
+		{Coordinate{Row: 1, Col: 2}, ColAxis},
+		{Coordinate{Row: 1, Col: 2}, RowAxis},
+		{Coordinate{Row: 6, Col: 7}, RowAxis},
+	}
+	for _, req := range requests {
+		proof, err := server.Prove(req.coord, req.axis)
+		assert.NoError(t, err)
+		assert.Equal(t, req.coord, proof.Coord)
+		assert.Equal(t, req.axis, proof.Axis)
+		assert.NoError(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+	}
+	_, ok := server.cache.get(newProofKey(Coordinate{Row: 1, Col: 2}, ColAxis))
+	assert.False(t, ok, "least recently used proof was not evicted")
+	_, ok = server.cache.get(newProofKey(Coordinate{Row: 1, Col: 2}, RowAxis))
+	assert.True(t, ok)
+
+	_, err = server.Prove(Coordinate{Row: 8, Col: 0}, RowAxis)
+	assert.Error(t, err)
+	assert.Equal(t, ProofServerStats{
+		Requests:     5,
+		CacheHits:    1,
+		Failures:     1,
+		CachedProofs: 2,
+		CachedBytes:  2 * proofSize,
+	}, server.Stats())
+
+	uncached, err := NewProofServer(eds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, uncached.cache)
+	proof, err := uncached.Prove(Coordinate{Row: 1, Col: 2}, RowAxis)
+	assert.NoError(t, err)
+	assert.Equal(t, first, proof)
+}
+
+func TestProofServerConcurrent(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+	server, err := NewProofServer(eds, WithProofCache(1<<12), WithProofServerParallelism(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := uint(0); i < 64; i++ {
+				share, proof, err := server.GetShareWithProof(i/8, i%8)
+				assert.NoError(t, err)
+				assert.Equal(t, eds.getCell(i/8, i%8), share)
+				assert.Equal(t, RowAxis, proof.Axis)
+				assert.NoError(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(4*64), server.Stats().Requests)
+
+	eds.createTreeFn = func() Tree { return randomRootTree{NewDefaultTree()} }
+	_, err = NewProofServer(eds)
+	assert.Equal(t, ErrTreeNotProvable, err)
+}
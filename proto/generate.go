@@ -0,0 +1,6 @@
+// Package proto holds the protobuf definitions of the wire types of rsmt2d
+// and the Go types generated from them, in a separate module so that rsmt2d
+// does not depend on the protobuf runtime.
+package proto
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative rsmt2d/v1/rsmt2d.proto
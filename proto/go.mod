@@ -0,0 +1,15 @@
+module github.com/lazyledger/rsmt2d/proto
+
+go 1.23
+
+require (
+	github.com/lazyledger/rsmt2d v0.0.0-20261015140403-d13c0e9c42bf
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/lazyledger/go-leopard v0.0.0-20200724211609-50ec4b3fab41 // indirect
+	github.com/lazyledger/merkletree v0.0.0-20201214195110-6901c4c3c75f // indirect
+	github.com/vivint/infectious v0.0.0-20200605153912-25a574ae18a3 // indirect
+	golang.org/x/sys v0.0.0-20210403161142-5e06dd20ab57 // indirect
+)
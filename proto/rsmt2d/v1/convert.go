@@ -0,0 +1,272 @@
+package rsmt2dv1
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lazyledger/rsmt2d"
+)
+
+// The From functions convert native rsmt2d types to their protobuf messages,
+// and the ToNative methods convert messages back, validating the fields the
+// native types rely on.
+
+func fromAxis(axis rsmt2d.Axis) Axis {
+	if axis == rsmt2d.ColAxis {
+		return Axis_AXIS_COLUMN
+	}
+	return Axis_AXIS_ROW
+}
+
+func (x Axis) toNative() (rsmt2d.Axis, error) {
+	switch x {
+	case Axis_AXIS_ROW:
+		return rsmt2d.RowAxis, nil
+	case Axis_AXIS_COLUMN:
+		return rsmt2d.ColAxis, nil
+	}
+	return 0, fmt.Errorf("invalid axis %d", x)
+}
+
+// FromCoordinate converts a coordinate.
+func FromCoordinate(coord rsmt2d.Coordinate) *Coordinate {
+	return &Coordinate{Row: uint64(coord.Row), Col: uint64(coord.Col)}
+}
+
+// ToNative converts the coordinate. It fails if it is missing.
+func (x *Coordinate) ToNative() (rsmt2d.Coordinate, error) {
+	if x == nil {
+		return rsmt2d.Coordinate{}, errors.New("missing coordinate")
+	}
+	return rsmt2d.Coordinate{Row: uint(x.Row), Col: uint(x.Col)}, nil
+}
+
+// FromProof converts an inclusion proof.
+func FromProof(proof rsmt2d.Proof) *Proof {
+	return &Proof{Set: proof.Set, Index: proof.Index, NumLeaves: proof.NumLeaves}
+}
+
+// ToNative converts the proof. It fails if it is missing.
+func (x *Proof) ToNative() (rsmt2d.Proof, error) {
+	if x == nil {
+		return rsmt2d.Proof{}, errors.New("missing proof")
+	}
+	return rsmt2d.Proof{Set: x.Set, Index: x.Index, NumLeaves: x.NumLeaves}, nil
+}
+
+// FromExtendedDataSquare converts a complete square.
+func FromExtendedDataSquare(eds *rsmt2d.ExtendedDataSquare) (*ExtendedDataSquare, error) {
+	roots, err := eds.Roots()
+	if err != nil {
+		return nil, err
+	}
+	width := eds.Width()
+	shares := make([][]byte, 0, width*width)
+	for r := uint(0); r < width; r++ {
+		for c := uint(0); c < width; c++ {
+			share, err := eds.GetCell(r, c)
+			if err != nil {
+				return nil, err
+			}
+			shares = append(shares, share)
+		}
+	}
+	return &ExtendedDataSquare{Codec: roots.Codec, Width: uint64(width), Shares: shares}, nil
+}
+
+// ToNative imports the square, checking that it was correctly extended.
+func (x *ExtendedDataSquare) ToNative(treeCreatorFn rsmt2d.TreeConstructorFn) (*rsmt2d.ExtendedDataSquare, error) {
+	codec, err := rsmt2d.CodecByName(x.GetCodec())
+	if err != nil {
+		return nil, err
+	}
+	if x.GetWidth() == 0 || uint64(len(x.GetShares())) != x.GetWidth()*x.GetWidth() {
+		return nil, fmt.Errorf("got %d shares for a square of width %d", len(x.GetShares()), x.GetWidth())
+	}
+	return rsmt2d.ImportExtendedDataSquare(x.GetShares(), codec, treeCreatorFn)
+}
+
+// FromOriginalDataSquare converts the original data of a square.
+func FromOriginalDataSquare(eds *rsmt2d.ExtendedDataSquare) (*OriginalDataSquare, error) {
+	roots, err := eds.Roots()
+	if err != nil {
+		return nil, err
+	}
+	k := eds.Width() / 2
+	shares := make([][]byte, 0, k*k)
+	for r := uint(0); r < k; r++ {
+		for c := uint(0); c < k; c++ {
+			share, err := eds.GetCell(r, c)
+			if err != nil {
+				return nil, err
+			}
+			shares = append(shares, share)
+		}
+	}
+	return &OriginalDataSquare{Codec: roots.Codec, Width: uint64(k), Shares: shares}, nil
+}
+
+// ToNative extends the original data into a square.
+func (x *OriginalDataSquare) ToNative(treeCreatorFn rsmt2d.TreeConstructorFn) (*rsmt2d.ExtendedDataSquare, error) {
+	codec, err := rsmt2d.CodecByName(x.GetCodec())
+	if err != nil {
+		return nil, err
+	}
+	if x.GetWidth() == 0 || uint64(len(x.GetShares())) != x.GetWidth()*x.GetWidth() {
+		return nil, fmt.Errorf("got %d shares for an original square of width %d", len(x.GetShares()), x.GetWidth())
+	}
+	return rsmt2d.ComputeExtendedDataSquare(x.GetShares(), codec, treeCreatorFn)
+}
+
+// FromSquareRoots converts the commitments to a square.
+func FromSquareRoots(roots *rsmt2d.SquareRoots) *SquareRoots {
+	return &SquareRoots{
+		Width:    uint64(roots.Width),
+		Codec:    roots.Codec,
+		RowRoots: roots.RowRoots,
+		ColRoots: roots.ColRoots,
+		DataRoot: roots.DataRoot,
+	}
+}
+
+// ToNative converts the roots. They are not verified; see
+// rsmt2d.SquareRoots.Verify.
+func (x *SquareRoots) ToNative() (*rsmt2d.SquareRoots, error) {
+	if x == nil {
+		return nil, errors.New("missing square roots")
+	}
+	return &rsmt2d.SquareRoots{
+		Width:    uint(x.Width),
+		Codec:    x.Codec,
+		RowRoots: x.RowRoots,
+		ColRoots: x.ColRoots,
+		DataRoot: x.DataRoot,
+	}, nil
+}
+
+// FromCellProof converts a proof of a cell.
+func FromCellProof(proof rsmt2d.CellProof) *CellProof {
+	return &CellProof{
+		Coord: FromCoordinate(proof.Coord),
+		Axis:  fromAxis(proof.Axis),
+		Proof: FromProof(proof.Proof),
+	}
+}
+
+// ToNative converts the proof. It is not verified.
+func (x *CellProof) ToNative() (rsmt2d.CellProof, error) {
+	if x == nil {
+		return rsmt2d.CellProof{}, errors.New("missing cell proof")
+	}
+	coord, err := x.Coord.ToNative()
+	if err != nil {
+		return rsmt2d.CellProof{}, err
+	}
+	axis, err := x.Axis.toNative()
+	if err != nil {
+		return rsmt2d.CellProof{}, err
+	}
+	proof, err := x.Proof.ToNative()
+	if err != nil {
+		return rsmt2d.CellProof{}, err
+	}
+	return rsmt2d.CellProof{Coord: coord, Axis: axis, Proof: proof}, nil
+}
+
+// FromAxisRootProof converts a proof of a row or column root.
+func FromAxisRootProof(proof rsmt2d.AxisRootProof) *AxisRootProof {
+	return &AxisRootProof{
+		Axis:  fromAxis(proof.Axis),
+		Index: uint64(proof.Index),
+		Root:  proof.Root,
+		Proof: FromProof(proof.Proof),
+	}
+}
+
+// ToNative converts the proof. It is not verified.
+func (x *AxisRootProof) ToNative() (rsmt2d.AxisRootProof, error) {
+	if x == nil {
+		return rsmt2d.AxisRootProof{}, errors.New("missing axis root proof")
+	}
+	axis, err := x.Axis.toNative()
+	if err != nil {
+		return rsmt2d.AxisRootProof{}, err
+	}
+	proof, err := x.Proof.ToNative()
+	if err != nil {
+		return rsmt2d.AxisRootProof{}, err
+	}
+	return rsmt2d.AxisRootProof{Axis: axis, Index: uint(x.Index), Root: x.Root, Proof: proof}, nil
+}
+
+// FromBadEncodingProof converts a bad encoding proof.
+func FromBadEncodingProof(proof *rsmt2d.BadEncodingProof) *BadEncodingProof {
+	shares := make([]*CellProof, len(proof.Shares))
+	for i := range proof.Shares {
+		shares[i] = FromCellProof(proof.Shares[i])
+	}
+	return &BadEncodingProof{Axis: fromAxis(proof.Axis), Index: uint64(proof.Index), Shares: shares}
+}
+
+// ToNative converts the proof. It is not verified.
+func (x *BadEncodingProof) ToNative() (*rsmt2d.BadEncodingProof, error) {
+	if x == nil {
+		return nil, errors.New("missing bad encoding proof")
+	}
+	axis, err := x.Axis.toNative()
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]rsmt2d.CellProof, len(x.Shares))
+	for i, share := range x.Shares {
+		if shares[i], err = share.ToNative(); err != nil {
+			return nil, fmt.Errorf("share %d: %v", i, err)
+		}
+	}
+	return &rsmt2d.BadEncodingProof{Axis: axis, Index: uint(x.Index), Shares: shares}, nil
+}
+
+// FromShareRequest converts a request for shares.
+func FromShareRequest(req *rsmt2d.ShareRequest) *ShareRequest {
+	coords := make([]*Coordinate, len(req.Coords))
+	for i, coord := range req.Coords {
+		coords[i] = FromCoordinate(coord)
+	}
+	return &ShareRequest{Coords: coords}
+}
+
+// ToNative converts the request.
+func (x *ShareRequest) ToNative() (*rsmt2d.ShareRequest, error) {
+	if x == nil {
+		return nil, errors.New("missing share request")
+	}
+	coords := make([]rsmt2d.Coordinate, len(x.Coords))
+	for i, coord := range x.Coords {
+		var err error
+		if coords[i], err = coord.ToNative(); err != nil {
+			return nil, fmt.Errorf("coordinate %d: %v", i, err)
+		}
+	}
+	return &rsmt2d.ShareRequest{Coords: coords}, nil
+}
+
+// FromShareResponse converts a response carrying a share.
+func FromShareResponse(resp *rsmt2d.ShareResponse) *ShareResponse {
+	return &ShareResponse{Coord: FromCoordinate(resp.Coord), Share: resp.Share, Proof: FromProof(resp.Proof)}
+}
+
+// ToNative converts the response. It is not verified.
+func (x *ShareResponse) ToNative() (*rsmt2d.ShareResponse, error) {
+	if x == nil {
+		return nil, errors.New("missing share response")
+	}
+	coord, err := x.Coord.ToNative()
+	if err != nil {
+		return nil, err
+	}
+	proof, err := x.Proof.ToNative()
+	if err != nil {
+		return nil, err
+	}
+	return &rsmt2d.ShareResponse{Coord: coord, Share: x.Share, Proof: proof}, nil
+}
@@ -0,0 +1,156 @@
+package rsmt2dv1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"reflect"
+	"testing"
+
+	"github.com/lazyledger/rsmt2d"
+	"google.golang.org/protobuf/proto"
+)
+
+func testSquare(t *testing.T) *rsmt2d.ExtendedDataSquare {
+	ods := make([][]byte, 4)
+	for i := range ods {
+		ods[i] = make([]byte, 64)
+		rand.Read(ods[i])
+	}
+	eds, err := rsmt2d.ComputeExtendedDataSquare(ods, rsmt2d.NewRSGF8Codec(), rsmt2d.NewDefaultTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return eds
+}
+
+// roundTrip encodes msg and decodes it into a new message of the same type.
+func roundTrip(t *testing.T, msg proto.Message) proto.Message {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := msg.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestSquareRoundTrip(t *testing.T) {
+	eds := testSquare(t)
+
+	msg, err := FromExtendedDataSquare(eds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := roundTrip(t, msg).(*ExtendedDataSquare).ToNative(rsmt2d.NewDefaultTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(imported.DataRoot(), eds.DataRoot()) {
+		t.Error("imported square has a different data root")
+	}
+
+	ods, err := FromOriginalDataSquare(eds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extended, err := roundTrip(t, ods).(*OriginalDataSquare).ToNative(rsmt2d.NewDefaultTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(extended.DataRoot(), eds.DataRoot()) {
+		t.Error("extended original square has a different data root")
+	}
+
+	roots, err := eds.Roots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotRoots, err := roundTrip(t, FromSquareRoots(roots)).(*SquareRoots).ToNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotRoots, roots) {
+		t.Errorf("got roots %v, expected %v", gotRoots, roots)
+	}
+
+	msg.Shares = msg.Shares[1:]
+	if _, err := msg.ToNative(rsmt2d.NewDefaultTree); err == nil {
+		t.Error("imported a square with a missing share")
+	}
+}
+
+func TestProofsRoundTrip(t *testing.T) {
+	eds := testSquare(t)
+
+	cellProof, err := eds.ProveCellOnAxis(rsmt2d.Coordinate{Row: 1, Col: 2}, rsmt2d.ColAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotCellProof, err := roundTrip(t, FromCellProof(cellProof)).(*CellProof).ToNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotCellProof, cellProof) {
+		t.Errorf("got cell proof %v, expected %v", gotCellProof, cellProof)
+	}
+
+	rootProof, err := eds.ProveAxisRoot(rsmt2d.RowAxis, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotRootProof, err := roundTrip(t, FromAxisRootProof(rootProof)).(*AxisRootProof).ToNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotRootProof, rootProof) {
+		t.Errorf("got axis root proof %v, expected %v", gotRootProof, rootProof)
+	}
+
+	befp, err := rsmt2d.NewBadEncodingProof(eds, rsmt2d.RowAxis, 0, []uint{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotBefp, err := roundTrip(t, FromBadEncodingProof(befp)).(*BadEncodingProof).ToNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotBefp, befp) {
+		t.Errorf("got bad encoding proof %v, expected %v", gotBefp, befp)
+	}
+
+	invalid := FromCellProof(cellProof)
+	invalid.Axis = 7
+	if _, err := invalid.ToNative(); err == nil {
+		t.Error("converted a cell proof with an invalid axis")
+	}
+	if _, err := (&CellProof{Axis: Axis_AXIS_ROW}).ToNative(); err == nil {
+		t.Error("converted a cell proof without coordinate and proof")
+	}
+}
+
+func TestShareExchangeRoundTrip(t *testing.T) {
+	eds := testSquare(t)
+
+	req := &rsmt2d.ShareRequest{Coords: []rsmt2d.Coordinate{{Row: 0, Col: 1}, {Row: 3, Col: 2}}}
+	gotReq, err := roundTrip(t, FromShareRequest(req)).(*ShareRequest).ToNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotReq, req) {
+		t.Errorf("got request %v, expected %v", gotReq, req)
+	}
+
+	resp, err := rsmt2d.NewShareResponse(eds, rsmt2d.Coordinate{Row: 2, Col: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotResp, err := roundTrip(t, FromShareResponse(resp)).(*ShareResponse).ToNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotResp, resp) {
+		t.Errorf("got response %v, expected %v", gotResp, resp)
+	}
+}
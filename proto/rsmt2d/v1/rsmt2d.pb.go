@@ -0,0 +1,1037 @@
+// Protobuf definitions of the wire types of github.com/lazyledger/rsmt2d.
+//
+// Field semantics follow the native Go types of the same name. Byte strings
+// are shares, hashes or roots; all widths and indices refer to the extended
+// square unless noted otherwise.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: rsmt2d/v1/rsmt2d.proto
+
+package rsmt2dv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Axis identifies whether an index refers to a row or a column.
+type Axis int32
+
+const (
+	Axis_AXIS_ROW    Axis = 0
+	Axis_AXIS_COLUMN Axis = 1
+)
+
+// Enum value maps for Axis.
+var (
+	Axis_name = map[int32]string{
+		0: "AXIS_ROW",
+		1: "AXIS_COLUMN",
+	}
+	Axis_value = map[string]int32{
+		"AXIS_ROW":    0,
+		"AXIS_COLUMN": 1,
+	}
+)
+
+func (x Axis) Enum() *Axis {
+	p := new(Axis)
+	*p = x
+	return p
+}
+
+func (x Axis) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Axis) Descriptor() protoreflect.EnumDescriptor {
+	return file_rsmt2d_v1_rsmt2d_proto_enumTypes[0].Descriptor()
+}
+
+func (Axis) Type() protoreflect.EnumType {
+	return &file_rsmt2d_v1_rsmt2d_proto_enumTypes[0]
+}
+
+func (x Axis) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Axis.Descriptor instead.
+func (Axis) EnumDescriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{0}
+}
+
+// Coordinate identifies a cell of the square.
+type Coordinate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           uint64                 `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Col           uint64                 `protobuf:"varint,2,opt,name=col,proto3" json:"col,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Coordinate) Reset() {
+	*x = Coordinate{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coordinate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coordinate) ProtoMessage() {}
+
+func (x *Coordinate) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coordinate.ProtoReflect.Descriptor instead.
+func (*Coordinate) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Coordinate) GetRow() uint64 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *Coordinate) GetCol() uint64 {
+	if x != nil {
+		return x.Col
+	}
+	return 0
+}
+
+// ExtendedDataSquare is a complete extended data square.
+type ExtendedDataSquare struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the codec the square was extended with, such as "RSGF8".
+	Codec string `protobuf:"bytes,1,opt,name=codec,proto3" json:"codec,omitempty"`
+	// Width of the extended square.
+	Width uint64 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	// Shares of the square in row-major order, width*width of them.
+	Shares        [][]byte `protobuf:"bytes,3,rep,name=shares,proto3" json:"shares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendedDataSquare) Reset() {
+	*x = ExtendedDataSquare{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendedDataSquare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendedDataSquare) ProtoMessage() {}
+
+func (x *ExtendedDataSquare) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendedDataSquare.ProtoReflect.Descriptor instead.
+func (*ExtendedDataSquare) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExtendedDataSquare) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *ExtendedDataSquare) GetWidth() uint64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ExtendedDataSquare) GetShares() [][]byte {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+// OriginalDataSquare is the top-left quadrant of a square, from which the
+// rest can be recomputed. It mirrors the format written by WriteODS.
+type OriginalDataSquare struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Codec string                 `protobuf:"bytes,1,opt,name=codec,proto3" json:"codec,omitempty"`
+	// Width of the original square.
+	Width uint64 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	// Shares of the original square in row-major order.
+	Shares        [][]byte `protobuf:"bytes,3,rep,name=shares,proto3" json:"shares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OriginalDataSquare) Reset() {
+	*x = OriginalDataSquare{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OriginalDataSquare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OriginalDataSquare) ProtoMessage() {}
+
+func (x *OriginalDataSquare) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OriginalDataSquare.ProtoReflect.Descriptor instead.
+func (*OriginalDataSquare) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OriginalDataSquare) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *OriginalDataSquare) GetWidth() uint64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *OriginalDataSquare) GetShares() [][]byte {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+// SquareRoots holds the commitments to a square without its shares.
+type SquareRoots struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Width         uint64                 `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Codec         string                 `protobuf:"bytes,2,opt,name=codec,proto3" json:"codec,omitempty"`
+	RowRoots      [][]byte               `protobuf:"bytes,3,rep,name=row_roots,json=rowRoots,proto3" json:"row_roots,omitempty"`
+	ColRoots      [][]byte               `protobuf:"bytes,4,rep,name=col_roots,json=colRoots,proto3" json:"col_roots,omitempty"`
+	DataRoot      []byte                 `protobuf:"bytes,5,opt,name=data_root,json=dataRoot,proto3" json:"data_root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SquareRoots) Reset() {
+	*x = SquareRoots{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SquareRoots) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SquareRoots) ProtoMessage() {}
+
+func (x *SquareRoots) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SquareRoots.ProtoReflect.Descriptor instead.
+func (*SquareRoots) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SquareRoots) GetWidth() uint64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *SquareRoots) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *SquareRoots) GetRowRoots() [][]byte {
+	if x != nil {
+		return x.RowRoots
+	}
+	return nil
+}
+
+func (x *SquareRoots) GetColRoots() [][]byte {
+	if x != nil {
+		return x.ColRoots
+	}
+	return nil
+}
+
+func (x *SquareRoots) GetDataRoot() []byte {
+	if x != nil {
+		return x.DataRoot
+	}
+	return nil
+}
+
+// Proof is a Merkle inclusion proof of a single share in a row or column.
+type Proof struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Proof set; the first element is the share itself.
+	Set [][]byte `protobuf:"bytes,1,rep,name=set,proto3" json:"set,omitempty"`
+	// Index of the share within the row or column.
+	Index uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Number of shares in the row or column.
+	NumLeaves     uint64 `protobuf:"varint,3,opt,name=num_leaves,json=numLeaves,proto3" json:"num_leaves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Proof) Reset() {
+	*x = Proof{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Proof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Proof) ProtoMessage() {}
+
+func (x *Proof) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Proof.ProtoReflect.Descriptor instead.
+func (*Proof) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Proof) GetSet() [][]byte {
+	if x != nil {
+		return x.Set
+	}
+	return nil
+}
+
+func (x *Proof) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Proof) GetNumLeaves() uint64 {
+	if x != nil {
+		return x.NumLeaves
+	}
+	return 0
+}
+
+// CellProof proves that a share is included at a coordinate of the square,
+// against the root of the row or column containing the cell.
+type CellProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Coord         *Coordinate            `protobuf:"bytes,1,opt,name=coord,proto3" json:"coord,omitempty"`
+	Axis          Axis                   `protobuf:"varint,2,opt,name=axis,proto3,enum=rsmt2d.v1.Axis" json:"axis,omitempty"`
+	Proof         *Proof                 `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CellProof) Reset() {
+	*x = CellProof{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CellProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CellProof) ProtoMessage() {}
+
+func (x *CellProof) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CellProof.ProtoReflect.Descriptor instead.
+func (*CellProof) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CellProof) GetCoord() *Coordinate {
+	if x != nil {
+		return x.Coord
+	}
+	return nil
+}
+
+func (x *CellProof) GetAxis() Axis {
+	if x != nil {
+		return x.Axis
+	}
+	return Axis_AXIS_ROW
+}
+
+func (x *CellProof) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+// AxisRootProof proves that a row or column root is committed to by the
+// data root.
+type AxisRootProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Axis          Axis                   `protobuf:"varint,1,opt,name=axis,proto3,enum=rsmt2d.v1.Axis" json:"axis,omitempty"`
+	Index         uint64                 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Root          []byte                 `protobuf:"bytes,3,opt,name=root,proto3" json:"root,omitempty"`
+	Proof         *Proof                 `protobuf:"bytes,4,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AxisRootProof) Reset() {
+	*x = AxisRootProof{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AxisRootProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AxisRootProof) ProtoMessage() {}
+
+func (x *AxisRootProof) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AxisRootProof.ProtoReflect.Descriptor instead.
+func (*AxisRootProof) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AxisRootProof) GetAxis() Axis {
+	if x != nil {
+		return x.Axis
+	}
+	return Axis_AXIS_ROW
+}
+
+func (x *AxisRootProof) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *AxisRootProof) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *AxisRootProof) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+// BadEncodingProof proves that a row or column was incorrectly extended.
+type BadEncodingProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Axis          Axis                   `protobuf:"varint,1,opt,name=axis,proto3,enum=rsmt2d.v1.Axis" json:"axis,omitempty"`
+	Index         uint64                 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Shares        []*CellProof           `protobuf:"bytes,3,rep,name=shares,proto3" json:"shares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BadEncodingProof) Reset() {
+	*x = BadEncodingProof{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BadEncodingProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BadEncodingProof) ProtoMessage() {}
+
+func (x *BadEncodingProof) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BadEncodingProof.ProtoReflect.Descriptor instead.
+func (*BadEncodingProof) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BadEncodingProof) GetAxis() Axis {
+	if x != nil {
+		return x.Axis
+	}
+	return Axis_AXIS_ROW
+}
+
+func (x *BadEncodingProof) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BadEncodingProof) GetShares() []*CellProof {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+// ShareRequest asks a peer for the shares at the given coordinates.
+type ShareRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Coords        []*Coordinate          `protobuf:"bytes,1,rep,name=coords,proto3" json:"coords,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareRequest) Reset() {
+	*x = ShareRequest{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareRequest) ProtoMessage() {}
+
+func (x *ShareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareRequest.ProtoReflect.Descriptor instead.
+func (*ShareRequest) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ShareRequest) GetCoords() []*Coordinate {
+	if x != nil {
+		return x.Coords
+	}
+	return nil
+}
+
+// ShareResponse carries a share together with its inclusion proof against
+// the root of the share's row.
+type ShareResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Coord         *Coordinate            `protobuf:"bytes,1,opt,name=coord,proto3" json:"coord,omitempty"`
+	Share         []byte                 `protobuf:"bytes,2,opt,name=share,proto3" json:"share,omitempty"`
+	Proof         *Proof                 `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareResponse) Reset() {
+	*x = ShareResponse{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareResponse) ProtoMessage() {}
+
+func (x *ShareResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareResponse.ProtoReflect.Descriptor instead.
+func (*ShareResponse) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ShareResponse) GetCoord() *Coordinate {
+	if x != nil {
+		return x.Coord
+	}
+	return nil
+}
+
+func (x *ShareResponse) GetShare() []byte {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+func (x *ShareResponse) GetProof() *Proof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+// Envelope tags a serialized message with the version of its format, see
+// Format.Negotiate.
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FormatVersion uint32                 `protobuf:"varint,1,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*Envelope_ExtendedDataSquare
+	//	*Envelope_OriginalDataSquare
+	//	*Envelope_SquareRoots
+	//	*Envelope_CellProof
+	//	*Envelope_AxisRootProof
+	//	*Envelope_BadEncodingProof
+	//	*Envelope_ShareRequest
+	//	*Envelope_ShareResponse
+	Message       isEnvelope_Message `protobuf_oneof:"message"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_rsmt2d_v1_rsmt2d_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Envelope) GetFormatVersion() uint32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
+}
+
+func (x *Envelope) GetMessage() isEnvelope_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *Envelope) GetExtendedDataSquare() *ExtendedDataSquare {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_ExtendedDataSquare); ok {
+			return x.ExtendedDataSquare
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetOriginalDataSquare() *OriginalDataSquare {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_OriginalDataSquare); ok {
+			return x.OriginalDataSquare
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSquareRoots() *SquareRoots {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_SquareRoots); ok {
+			return x.SquareRoots
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetCellProof() *CellProof {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_CellProof); ok {
+			return x.CellProof
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetAxisRootProof() *AxisRootProof {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_AxisRootProof); ok {
+			return x.AxisRootProof
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetBadEncodingProof() *BadEncodingProof {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_BadEncodingProof); ok {
+			return x.BadEncodingProof
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetShareRequest() *ShareRequest {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_ShareRequest); ok {
+			return x.ShareRequest
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetShareResponse() *ShareResponse {
+	if x != nil {
+		if x, ok := x.Message.(*Envelope_ShareResponse); ok {
+			return x.ShareResponse
+		}
+	}
+	return nil
+}
+
+type isEnvelope_Message interface {
+	isEnvelope_Message()
+}
+
+type Envelope_ExtendedDataSquare struct {
+	ExtendedDataSquare *ExtendedDataSquare `protobuf:"bytes,2,opt,name=extended_data_square,json=extendedDataSquare,proto3,oneof"`
+}
+
+type Envelope_OriginalDataSquare struct {
+	OriginalDataSquare *OriginalDataSquare `protobuf:"bytes,3,opt,name=original_data_square,json=originalDataSquare,proto3,oneof"`
+}
+
+type Envelope_SquareRoots struct {
+	SquareRoots *SquareRoots `protobuf:"bytes,4,opt,name=square_roots,json=squareRoots,proto3,oneof"`
+}
+
+type Envelope_CellProof struct {
+	CellProof *CellProof `protobuf:"bytes,5,opt,name=cell_proof,json=cellProof,proto3,oneof"`
+}
+
+type Envelope_AxisRootProof struct {
+	AxisRootProof *AxisRootProof `protobuf:"bytes,6,opt,name=axis_root_proof,json=axisRootProof,proto3,oneof"`
+}
+
+type Envelope_BadEncodingProof struct {
+	BadEncodingProof *BadEncodingProof `protobuf:"bytes,7,opt,name=bad_encoding_proof,json=badEncodingProof,proto3,oneof"`
+}
+
+type Envelope_ShareRequest struct {
+	ShareRequest *ShareRequest `protobuf:"bytes,8,opt,name=share_request,json=shareRequest,proto3,oneof"`
+}
+
+type Envelope_ShareResponse struct {
+	ShareResponse *ShareResponse `protobuf:"bytes,9,opt,name=share_response,json=shareResponse,proto3,oneof"`
+}
+
+func (*Envelope_ExtendedDataSquare) isEnvelope_Message() {}
+
+func (*Envelope_OriginalDataSquare) isEnvelope_Message() {}
+
+func (*Envelope_SquareRoots) isEnvelope_Message() {}
+
+func (*Envelope_CellProof) isEnvelope_Message() {}
+
+func (*Envelope_AxisRootProof) isEnvelope_Message() {}
+
+func (*Envelope_BadEncodingProof) isEnvelope_Message() {}
+
+func (*Envelope_ShareRequest) isEnvelope_Message() {}
+
+func (*Envelope_ShareResponse) isEnvelope_Message() {}
+
+var File_rsmt2d_v1_rsmt2d_proto protoreflect.FileDescriptor
+
+const file_rsmt2d_v1_rsmt2d_proto_rawDesc = "" +
+	"\n" +
+	"\x16rsmt2d/v1/rsmt2d.proto\x12\trsmt2d.v1\"0\n" +
+	"\n" +
+	"Coordinate\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x04R\x03row\x12\x10\n" +
+	"\x03col\x18\x02 \x01(\x04R\x03col\"X\n" +
+	"\x12ExtendedDataSquare\x12\x14\n" +
+	"\x05codec\x18\x01 \x01(\tR\x05codec\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x04R\x05width\x12\x16\n" +
+	"\x06shares\x18\x03 \x03(\fR\x06shares\"X\n" +
+	"\x12OriginalDataSquare\x12\x14\n" +
+	"\x05codec\x18\x01 \x01(\tR\x05codec\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x04R\x05width\x12\x16\n" +
+	"\x06shares\x18\x03 \x03(\fR\x06shares\"\x90\x01\n" +
+	"\vSquareRoots\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x04R\x05width\x12\x14\n" +
+	"\x05codec\x18\x02 \x01(\tR\x05codec\x12\x1b\n" +
+	"\trow_roots\x18\x03 \x03(\fR\browRoots\x12\x1b\n" +
+	"\tcol_roots\x18\x04 \x03(\fR\bcolRoots\x12\x1b\n" +
+	"\tdata_root\x18\x05 \x01(\fR\bdataRoot\"N\n" +
+	"\x05Proof\x12\x10\n" +
+	"\x03set\x18\x01 \x03(\fR\x03set\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x04R\x05index\x12\x1d\n" +
+	"\n" +
+	"num_leaves\x18\x03 \x01(\x04R\tnumLeaves\"\x85\x01\n" +
+	"\tCellProof\x12+\n" +
+	"\x05coord\x18\x01 \x01(\v2\x15.rsmt2d.v1.CoordinateR\x05coord\x12#\n" +
+	"\x04axis\x18\x02 \x01(\x0e2\x0f.rsmt2d.v1.AxisR\x04axis\x12&\n" +
+	"\x05proof\x18\x03 \x01(\v2\x10.rsmt2d.v1.ProofR\x05proof\"\x86\x01\n" +
+	"\rAxisRootProof\x12#\n" +
+	"\x04axis\x18\x01 \x01(\x0e2\x0f.rsmt2d.v1.AxisR\x04axis\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x04R\x05index\x12\x12\n" +
+	"\x04root\x18\x03 \x01(\fR\x04root\x12&\n" +
+	"\x05proof\x18\x04 \x01(\v2\x10.rsmt2d.v1.ProofR\x05proof\"{\n" +
+	"\x10BadEncodingProof\x12#\n" +
+	"\x04axis\x18\x01 \x01(\x0e2\x0f.rsmt2d.v1.AxisR\x04axis\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\x04R\x05index\x12,\n" +
+	"\x06shares\x18\x03 \x03(\v2\x14.rsmt2d.v1.CellProofR\x06shares\"=\n" +
+	"\fShareRequest\x12-\n" +
+	"\x06coords\x18\x01 \x03(\v2\x15.rsmt2d.v1.CoordinateR\x06coords\"z\n" +
+	"\rShareResponse\x12+\n" +
+	"\x05coord\x18\x01 \x01(\v2\x15.rsmt2d.v1.CoordinateR\x05coord\x12\x14\n" +
+	"\x05share\x18\x02 \x01(\fR\x05share\x12&\n" +
+	"\x05proof\x18\x03 \x01(\v2\x10.rsmt2d.v1.ProofR\x05proof\"\xea\x04\n" +
+	"\bEnvelope\x12%\n" +
+	"\x0eformat_version\x18\x01 \x01(\rR\rformatVersion\x12Q\n" +
+	"\x14extended_data_square\x18\x02 \x01(\v2\x1d.rsmt2d.v1.ExtendedDataSquareH\x00R\x12extendedDataSquare\x12Q\n" +
+	"\x14original_data_square\x18\x03 \x01(\v2\x1d.rsmt2d.v1.OriginalDataSquareH\x00R\x12originalDataSquare\x12;\n" +
+	"\fsquare_roots\x18\x04 \x01(\v2\x16.rsmt2d.v1.SquareRootsH\x00R\vsquareRoots\x125\n" +
+	"\n" +
+	"cell_proof\x18\x05 \x01(\v2\x14.rsmt2d.v1.CellProofH\x00R\tcellProof\x12B\n" +
+	"\x0faxis_root_proof\x18\x06 \x01(\v2\x18.rsmt2d.v1.AxisRootProofH\x00R\raxisRootProof\x12K\n" +
+	"\x12bad_encoding_proof\x18\a \x01(\v2\x1b.rsmt2d.v1.BadEncodingProofH\x00R\x10badEncodingProof\x12>\n" +
+	"\rshare_request\x18\b \x01(\v2\x17.rsmt2d.v1.ShareRequestH\x00R\fshareRequest\x12A\n" +
+	"\x0eshare_response\x18\t \x01(\v2\x18.rsmt2d.v1.ShareResponseH\x00R\rshareResponseB\t\n" +
+	"\amessage*%\n" +
+	"\x04Axis\x12\f\n" +
+	"\bAXIS_ROW\x10\x00\x12\x0f\n" +
+	"\vAXIS_COLUMN\x10\x01B7Z5github.com/lazyledger/rsmt2d/proto/rsmt2d/v1;rsmt2dv1b\x06proto3"
+
+var (
+	file_rsmt2d_v1_rsmt2d_proto_rawDescOnce sync.Once
+	file_rsmt2d_v1_rsmt2d_proto_rawDescData []byte
+)
+
+func file_rsmt2d_v1_rsmt2d_proto_rawDescGZIP() []byte {
+	file_rsmt2d_v1_rsmt2d_proto_rawDescOnce.Do(func() {
+		file_rsmt2d_v1_rsmt2d_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rsmt2d_v1_rsmt2d_proto_rawDesc), len(file_rsmt2d_v1_rsmt2d_proto_rawDesc)))
+	})
+	return file_rsmt2d_v1_rsmt2d_proto_rawDescData
+}
+
+var file_rsmt2d_v1_rsmt2d_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_rsmt2d_v1_rsmt2d_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_rsmt2d_v1_rsmt2d_proto_goTypes = []any{
+	(Axis)(0),                  // 0: rsmt2d.v1.Axis
+	(*Coordinate)(nil),         // 1: rsmt2d.v1.Coordinate
+	(*ExtendedDataSquare)(nil), // 2: rsmt2d.v1.ExtendedDataSquare
+	(*OriginalDataSquare)(nil), // 3: rsmt2d.v1.OriginalDataSquare
+	(*SquareRoots)(nil),        // 4: rsmt2d.v1.SquareRoots
+	(*Proof)(nil),              // 5: rsmt2d.v1.Proof
+	(*CellProof)(nil),          // 6: rsmt2d.v1.CellProof
+	(*AxisRootProof)(nil),      // 7: rsmt2d.v1.AxisRootProof
+	(*BadEncodingProof)(nil),   // 8: rsmt2d.v1.BadEncodingProof
+	(*ShareRequest)(nil),       // 9: rsmt2d.v1.ShareRequest
+	(*ShareResponse)(nil),      // 10: rsmt2d.v1.ShareResponse
+	(*Envelope)(nil),           // 11: rsmt2d.v1.Envelope
+}
+var file_rsmt2d_v1_rsmt2d_proto_depIdxs = []int32{
+	1,  // 0: rsmt2d.v1.CellProof.coord:type_name -> rsmt2d.v1.Coordinate
+	0,  // 1: rsmt2d.v1.CellProof.axis:type_name -> rsmt2d.v1.Axis
+	5,  // 2: rsmt2d.v1.CellProof.proof:type_name -> rsmt2d.v1.Proof
+	0,  // 3: rsmt2d.v1.AxisRootProof.axis:type_name -> rsmt2d.v1.Axis
+	5,  // 4: rsmt2d.v1.AxisRootProof.proof:type_name -> rsmt2d.v1.Proof
+	0,  // 5: rsmt2d.v1.BadEncodingProof.axis:type_name -> rsmt2d.v1.Axis
+	6,  // 6: rsmt2d.v1.BadEncodingProof.shares:type_name -> rsmt2d.v1.CellProof
+	1,  // 7: rsmt2d.v1.ShareRequest.coords:type_name -> rsmt2d.v1.Coordinate
+	1,  // 8: rsmt2d.v1.ShareResponse.coord:type_name -> rsmt2d.v1.Coordinate
+	5,  // 9: rsmt2d.v1.ShareResponse.proof:type_name -> rsmt2d.v1.Proof
+	2,  // 10: rsmt2d.v1.Envelope.extended_data_square:type_name -> rsmt2d.v1.ExtendedDataSquare
+	3,  // 11: rsmt2d.v1.Envelope.original_data_square:type_name -> rsmt2d.v1.OriginalDataSquare
+	4,  // 12: rsmt2d.v1.Envelope.square_roots:type_name -> rsmt2d.v1.SquareRoots
+	6,  // 13: rsmt2d.v1.Envelope.cell_proof:type_name -> rsmt2d.v1.CellProof
+	7,  // 14: rsmt2d.v1.Envelope.axis_root_proof:type_name -> rsmt2d.v1.AxisRootProof
+	8,  // 15: rsmt2d.v1.Envelope.bad_encoding_proof:type_name -> rsmt2d.v1.BadEncodingProof
+	9,  // 16: rsmt2d.v1.Envelope.share_request:type_name -> rsmt2d.v1.ShareRequest
+	10, // 17: rsmt2d.v1.Envelope.share_response:type_name -> rsmt2d.v1.ShareResponse
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_rsmt2d_v1_rsmt2d_proto_init() }
+func file_rsmt2d_v1_rsmt2d_proto_init() {
+	if File_rsmt2d_v1_rsmt2d_proto != nil {
+		return
+	}
+	file_rsmt2d_v1_rsmt2d_proto_msgTypes[10].OneofWrappers = []any{
+		(*Envelope_ExtendedDataSquare)(nil),
+		(*Envelope_OriginalDataSquare)(nil),
+		(*Envelope_SquareRoots)(nil),
+		(*Envelope_CellProof)(nil),
+		(*Envelope_AxisRootProof)(nil),
+		(*Envelope_BadEncodingProof)(nil),
+		(*Envelope_ShareRequest)(nil),
+		(*Envelope_ShareResponse)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rsmt2d_v1_rsmt2d_proto_rawDesc), len(file_rsmt2d_v1_rsmt2d_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rsmt2d_v1_rsmt2d_proto_goTypes,
+		DependencyIndexes: file_rsmt2d_v1_rsmt2d_proto_depIdxs,
+		EnumInfos:         file_rsmt2d_v1_rsmt2d_proto_enumTypes,
+		MessageInfos:      file_rsmt2d_v1_rsmt2d_proto_msgTypes,
+	}.Build()
+	File_rsmt2d_v1_rsmt2d_proto = out.File
+	file_rsmt2d_v1_rsmt2d_proto_goTypes = nil
+	file_rsmt2d_v1_rsmt2d_proto_depIdxs = nil
+}
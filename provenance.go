@@ -0,0 +1,111 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Provenance describes where the value of a cell came from during repair.
+type Provenance uint8
+
+const (
+	// ProvenanceMissing marks a cell that was not provided and has not been
+	// reconstructed.
+	ProvenanceMissing Provenance = iota
+	// ProvenanceUnverified marks a share provided without a proof, such as
+	// one received from a peer.
+	ProvenanceUnverified
+	// ProvenanceVerified marks a share whose inclusion proof was verified
+	// on ingestion, see RepairWithProofs.
+	ProvenanceVerified
+	// ProvenanceComputed marks a share reconstructed or corrected by repair.
+	ProvenanceComputed
+)
+
+func (p Provenance) String() string {
+	switch p {
+	case ProvenanceMissing:
+		return "missing"
+	case ProvenanceUnverified:
+		return "unverified"
+	case ProvenanceVerified:
+		return "verified"
+	case ProvenanceComputed:
+		return "computed"
+	default:
+		return fmt.Sprintf("Provenance(%d)", uint8(p))
+	}
+}
+
+// WithProvenance makes repair store the provenance of every cell of the
+// repaired square in cells, in row-major order.
+func WithProvenance(cells *[]Provenance) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.provenanceOut = cells
+	}
+}
+
+// initProvenance records the provenance of the shares passed to repair,
+// alongside the presence mask.
+func (cfg *repairConfig) initProvenance(data [][]byte, width uint) {
+	cfg.provenance = make([]Provenance, len(data))
+	for i, d := range data {
+		switch {
+		case cfg.verifiedCells[Coordinate{Row: uint(i) / width, Col: uint(i) % width}]:
+			cfg.provenance[i] = ProvenanceVerified
+		case d != nil:
+			cfg.provenance[i] = ProvenanceUnverified
+		}
+	}
+	cfg.width = width
+}
+
+// provenanceAt returns the provenance of the share at a position of a row or
+// column.
+func (cfg *repairConfig) provenanceAt(axis Axis, index uint, pos uint) Provenance {
+	if axis == RowAxis {
+		return cfg.provenance[index*cfg.width+pos]
+	}
+	return cfg.provenance[pos*cfg.width+index]
+}
+
+// finishProvenance marks the cells filled in by repair as computed and
+// publishes the provenance of all cells.
+func (cfg *repairConfig) finishProvenance() {
+	for i, p := range cfg.provenance {
+		if p == ProvenanceMissing {
+			cfg.provenance[i] = ProvenanceComputed
+		}
+	}
+	if cfg.provenanceOut != nil {
+		*cfg.provenanceOut = cfg.provenance
+	}
+}
+
+// attributeProvenance adds the provenance of the offending shares to err.
+func (cfg *repairConfig) attributeProvenance(err error) error {
+	var (
+		byzRow   *ErrByzantineRow
+		byzCol   *ErrByzantineCol
+		conflict *ErrConflictingShare
+	)
+	switch {
+	case errors.As(err, &byzRow):
+		byzRow.Provenance = make([]Provenance, cfg.width)
+		for c := uint(0); c < cfg.width; c++ {
+			if byzRow.Shares[c] != nil {
+				byzRow.Provenance[c] = cfg.provenanceAt(RowAxis, byzRow.RowNumber, c)
+			}
+		}
+	case errors.As(err, &byzCol):
+		byzCol.Provenance = make([]Provenance, cfg.width)
+		for r := uint(0); r < cfg.width; r++ {
+			if byzCol.Shares[r] != nil {
+				byzCol.Provenance[r] = cfg.provenanceAt(ColAxis, byzCol.ColNumber, r)
+			}
+		}
+	case errors.As(err, &conflict):
+		conflict.Provenance = cfg.provenanceAt(RowAxis, conflict.Coord.Row, conflict.Coord.Col)
+	}
+	return err
+}
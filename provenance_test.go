@@ -0,0 +1,54 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairProvenance(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := original.getRowRoots(), original.getColRoots()
+
+	proof, err := original.ProveCellOnAxis(Coordinate{Row: 0, Col: 1}, RowAxis)
+	if err != nil {
+		panic(err)
+	}
+	data := original.flattened()
+	data[0], data[1] = nil, nil
+
+	var provenance []Provenance
+	_, err = RepairWithProofs(rowRoots, colRoots, data, []CellProof{proof}, codec, NewDefaultTree, WithProvenance(&provenance))
+	if assert.NoError(t, err) {
+		assert.Equal(t, ProvenanceComputed, provenance[0])
+		assert.Equal(t, ProvenanceVerified, provenance[1])
+		assert.Equal(t, ProvenanceUnverified, provenance[2])
+	}
+
+	original, err = ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots = original.getRowRoots(), original.getColRoots()
+
+	// Row 0 is rebuilt from its original shares, so its parity is
+	// re-encoded and the provided parity share at (0, 6) is never hashed.
+	data = original.flattened()
+	data[0*8+5] = nil
+	data[0*8+6] = bytes.Repeat([]byte{1}, int(original.chunkSize))
+	for r := 1; r < 8; r++ {
+		data[r*8+6] = nil
+	}
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree)
+	var conflict *ErrConflictingShare
+	if assert.True(t, errors.As(err, &conflict), "expected ErrConflictingShare, got %v", err) {
+		assert.Equal(t, ProvenanceUnverified, conflict.Provenance)
+	}
+	assert.Equal(t, "verified", ProvenanceVerified.String())
+}
@@ -0,0 +1,68 @@
+package rsmt2d
+
+import "fmt"
+
+// RepairWithProofs repairs a square from shares carrying inclusion proofs,
+// in addition to the unverified shares in data, which is indexed like the
+// data passed to RepairExtendedDataSquare and may be nil. Every proof is
+// verified on ingestion and its share has ProvenanceVerified from then on:
+// error detection never suspects it, and rows or columns whose shares were
+// all proven against their own root are not hashed again.
+func RepairWithProofs(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	data [][]byte,
+	proven []CellProof,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	opts ...RepairOption,
+) (*ExtendedDataSquare, error) {
+	width := uint(len(rowRoots))
+	if data == nil {
+		data = make([][]byte, width*width)
+	} else if uint(len(data)) != width*width {
+		return nil, fmt.Errorf("got %d shares for a square of width %d", len(data), width)
+	}
+	data = append([][]byte(nil), data...)
+
+	type provenCell struct {
+		coord Coordinate
+		axis  Axis
+	}
+	verified := make(map[Coordinate]bool, len(proven))
+	seen := make(map[provenCell]bool, len(proven))
+	var provenCount [2][]uint // Shares proven against each root, indexed by Axis
+	provenCount[RowAxis] = make([]uint, width)
+	provenCount[ColAxis] = make([]uint, width)
+	for i := range proven {
+		p := &proven[i]
+		if err := p.Verify(rowRoots, colRoots, treeCreatorFn); err != nil {
+			return nil, fmt.Errorf("share (%d, %d): %w", p.Coord.Row, p.Coord.Col, err)
+		}
+		verified[p.Coord] = true
+		data[p.Coord.Row*width+p.Coord.Col] = p.Share()
+		if seen[provenCell{p.Coord, p.Axis}] {
+			continue
+		}
+		seen[provenCell{p.Coord, p.Axis}] = true
+		if p.Axis == RowAxis {
+			provenCount[RowAxis][p.Coord.Row]++
+		} else {
+			provenCount[ColAxis][p.Coord.Col]++
+		}
+	}
+
+	opts = append(opts, func(cfg *repairConfig) {
+		cfg.verifiedCells = verified
+	})
+	for _, axis := range []Axis{RowAxis, ColAxis} {
+		var verified []uint
+		for i, n := range provenCount[axis] {
+			if n == width {
+				verified = append(verified, uint(i))
+			}
+		}
+		opts = append(opts, WithVerifiedAxes(axis, verified...))
+	}
+	return RepairExtendedDataSquare(rowRoots, colRoots, data, codec, treeCreatorFn, opts...)
+}
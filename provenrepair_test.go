@@ -0,0 +1,82 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairWithProofs(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := original.getRowRoots(), original.getColRoots()
+
+	// Row 0 arrives with proofs; the other shares of the first half of the
+	// rows arrive unverified.
+	var proven []CellProof
+	for c := uint(0); c < 8; c++ {
+		p, err := original.ProveCellOnAxis(Coordinate{Row: 0, Col: c}, RowAxis)
+		if err != nil {
+			panic(err)
+		}
+		proven = append(proven, p)
+	}
+	data := make([][]byte, 64)
+	copy(data[8:32], original.flattened()[8:32])
+
+	repaired, err := RepairWithProofs(rowRoots, colRoots, data, proven, codec, NewDefaultTree)
+	if assert.NoError(t, err) {
+		assert.Equal(t, original.flattened(), repaired.flattened())
+	}
+
+	proven[3].Proof.Set[0] = make([]byte, original.chunkSize)
+	_, err = RepairWithProofs(rowRoots, colRoots, data, proven, codec, NewDefaultTree)
+	assert.True(t, errors.Is(err, ErrInvalidShareProof), "expected ErrInvalidShareProof, got %v", err)
+}
+
+func TestCorrectAxisSkipsTrusted(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	shares := eds.Row(0)
+	shares[1] = make([]byte, eds.chunkSize)
+
+	cfg := &repairConfig{maxErrors: 1}
+	cfg.initProvenance(eds.flattened(), eds.width)
+	_, corrupted, ok := eds.correctAxis(RowAxis, 0, shares, eds.getRowRoot(0), codec, cfg)
+	assert.True(t, ok)
+	assert.Equal(t, []uint{1}, corrupted)
+
+	cfg.verifiedCells = map[Coordinate]bool{{Row: 0, Col: 1}: true}
+	cfg.initProvenance(eds.flattened(), eds.width)
+	_, _, ok = eds.correctAxis(RowAxis, 0, shares, eds.getRowRoot(0), codec, cfg)
+	assert.False(t, ok)
+}
+
+func TestCorrectAxisWithErrorCorrectingCodec(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	shares := eds.Row(2)
+	shares[0] = make([]byte, eds.chunkSize)
+	shares[5] = make([]byte, eds.chunkSize)
+
+	cfg := &repairConfig{maxErrors: 2}
+	cfg.initProvenance(eds.flattened(), eds.width)
+	rebuilt, corrupted, ok := eds.correctAxis(RowAxis, 2, shares, eds.getRowRoot(2), codec, cfg)
+	assert.True(t, ok)
+	assert.Equal(t, []uint{0, 5}, corrupted)
+	assert.Equal(t, eds.Row(2), rebuilt)
+
+	cfg.maxErrors = 1
+	_, _, ok = eds.correctAxis(RowAxis, 2, shares, eds.getRowRoot(2), codec, cfg)
+	assert.False(t, ok)
+}
@@ -0,0 +1,231 @@
+package rsmt2d
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Reconstructor drives the repair of a square by a full node: it plans which
+// missing shares to fetch, fetches them through a ShareFetcher with a bounded
+// number of requests in flight, verifies them against the row and column
+// roots, and repairs the square once enough shares have arrived.
+type Reconstructor struct {
+	rowRoots      [][]byte
+	colRoots      [][]byte
+	codec         Codec
+	treeCreatorFn TreeConstructorFn
+	fetcher       ShareFetcher
+	cfg           reconstructorConfig
+}
+
+// ReconstructorOption configures a Reconstructor.
+type ReconstructorOption func(*reconstructorConfig)
+
+type reconstructorConfig struct {
+	maxInFlight  int
+	fetchTimeout time.Duration
+	retries      int
+	repairOpts   []RepairOption
+}
+
+// WithMaxInFlight bounds the number of fetches in flight, so that peers and
+// the node are not flooded with requests. The default is 16.
+func WithMaxInFlight(n int) ReconstructorOption {
+	return func(cfg *reconstructorConfig) {
+		cfg.maxInFlight = n
+	}
+}
+
+// WithFetchTimeout bounds the duration of each fetch. By default fetches are
+// only bounded by the context passed to Reconstruct.
+func WithFetchTimeout(timeout time.Duration) ReconstructorOption {
+	return func(cfg *reconstructorConfig) {
+		cfg.fetchTimeout = timeout
+	}
+}
+
+// WithFetchRetries sets how many times a share that fails to fetch or verify
+// is requested again before it is considered unavailable. The default is 1.
+func WithFetchRetries(n int) ReconstructorOption {
+	return func(cfg *reconstructorConfig) {
+		cfg.retries = n
+	}
+}
+
+// WithReconstructorRepairOptions sets the options the square is repaired
+// with once enough shares have been fetched.
+func WithReconstructorRepairOptions(opts ...RepairOption) ReconstructorOption {
+	return func(cfg *reconstructorConfig) {
+		cfg.repairOpts = opts
+	}
+}
+
+// NewReconstructor returns a reconstructor of the square committed to by
+// rowRoots and colRoots.
+func NewReconstructor(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	treeCreatorFn TreeConstructorFn,
+	fetcher ShareFetcher,
+	opts ...ReconstructorOption,
+) (*Reconstructor, error) {
+	if len(rowRoots) == 0 || len(rowRoots)%2 != 0 || len(colRoots) != len(rowRoots) {
+		return nil, fmt.Errorf("got %d row and %d column roots, expected the same even number", len(rowRoots), len(colRoots))
+	}
+	cfg := reconstructorConfig{maxInFlight: 16, retries: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Reconstructor{
+		rowRoots:      rowRoots,
+		colRoots:      colRoots,
+		codec:         codec,
+		treeCreatorFn: treeCreatorFn,
+		fetcher:       fetcher,
+		cfg:           cfg,
+	}, nil
+}
+
+// Reconstruct completes the square from the shares already held, data in
+// row-major order with missing shares nil, and shares fetched as needed. It
+// fails with ErrUnrepairable once the shares that could not be fetched
+// prevent repair, and with the context's error when ctx is done.
+func (r *Reconstructor) Reconstruct(ctx context.Context, data [][]byte) (*ExtendedDataSquare, error) {
+	width := uint(len(r.rowRoots))
+	if uint(len(data)) != width*width {
+		return nil, fmt.Errorf("got %d shares for a square of width %d", len(data), width)
+	}
+	solver, err := NewIncrementalSolver(r.rowRoots, r.colRoots, r.codec, r.treeCreatorFn)
+	if err != nil {
+		return nil, err
+	}
+	for i, share := range data {
+		if share != nil {
+			if err := solver.AddTrustedShare(Coordinate{Row: uint(i) / width, Col: uint(i) % width}, share); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	k := int(width / 2)
+	unavailable := newBitMatrix(int(width))
+	for {
+		plan, complete := planFetches(solver.presence(), unavailable, k)
+		if len(plan) == 0 {
+			if !complete {
+				return nil, classifyErasures(solver.presence(), k)
+			}
+			opts := append([]RepairOption{WithRepairProfilingContext(ctx)}, r.cfg.repairOpts...)
+			return solver.Solve(opts...)
+		}
+
+		var mu sync.Mutex
+		err := parallelFor(nil, r.cfg.maxInFlight, uint(len(plan)), func(i uint) error {
+			coord := plan[i]
+			proof, err := r.fetch(ctx, coord)
+			if err == nil {
+				err = solver.addVerified(proof)
+			}
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				mu.Lock()
+				unavailable.Set(int(coord.Row), int(coord.Col))
+				mu.Unlock()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fetch fetches the share at coord and verifies its proof, retrying failed
+// attempts.
+func (r *Reconstructor) fetch(ctx context.Context, coord Coordinate) (CellProof, error) {
+	var err error
+	for attempt := 0; attempt <= r.cfg.retries; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return CellProof{}, ctxErr
+		}
+		var proof CellProof
+		proof, err = r.fetchOnce(ctx, coord)
+		if err == nil {
+			return proof, nil
+		}
+	}
+	return CellProof{}, err
+}
+
+func (r *Reconstructor) fetchOnce(ctx context.Context, coord Coordinate) (CellProof, error) {
+	if r.cfg.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.fetchTimeout)
+		defer cancel()
+	}
+	sampled, err := r.fetcher.FetchShare(ctx, coord)
+	if err != nil {
+		return CellProof{}, err
+	}
+	if sampled.Proof.Coord != coord {
+		return CellProof{}, ErrInvalidShareProof
+	}
+	if err := sampled.Proof.Verify(r.rowRoots, r.colRoots, r.treeCreatorFn); err != nil {
+		return CellProof{}, err
+	}
+	return sampled.Proof, nil
+}
+
+// planFetches returns missing cells to fetch so that the square becomes
+// repairable by decoding rows and columns, given the present cells and the
+// cells that cannot be fetched. It greedily completes the rows and columns
+// needing the fewest shares, recomputing what can be decoded after each. The
+// returned flag is set if the square is repairable once the planned cells
+// have been fetched.
+func planFetches(present bitMatrix, unavailable bitMatrix, k int) ([]Coordinate, bool) {
+	known := completeCrossword(present, k)
+	width := known.squareSize
+	var plan []Coordinate
+	for !isComplete(known) {
+		var best []Coordinate
+		bestNeed := 0
+		for _, axis := range []Axis{RowAxis, ColAxis} {
+			for i := 0; i < width; i++ {
+				var candidates []Coordinate
+				count := 0
+				for j := 0; j < width; j++ {
+					r, c := i, j
+					if axis == ColAxis {
+						r, c = j, i
+					}
+					if known.Get(r, c) {
+						count++
+					} else if !unavailable.Get(r, c) {
+						candidates = append(candidates, Coordinate{Row: uint(r), Col: uint(c)})
+					}
+				}
+				need := k - count
+				if count == width || len(candidates) < need {
+					continue
+				}
+				if best == nil || need < bestNeed {
+					best, bestNeed = candidates[:need], need
+				}
+			}
+		}
+		if best == nil {
+			return plan, false
+		}
+		for _, coord := range best {
+			known.Set(int(coord.Row), int(coord.Col))
+		}
+		plan = append(plan, best...)
+		known = completeCrossword(known, k)
+	}
+	return plan, true
+}
@@ -0,0 +1,306 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingFetcher counts fetches and the largest number of concurrent ones.
+type countingFetcher struct {
+	ShareFetcher
+	delay time.Duration
+
+	mu          sync.Mutex
+	fetches     int
+	inFlight    int
+	maxInFlight int
+}
+
+func (f *countingFetcher) FetchShare(ctx context.Context, coord Coordinate) (SampledShare, error) {
+	f.mu.Lock()
+	f.fetches++
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return SampledShare{}, ctx.Err()
+	}
+	return f.ShareFetcher.FetchShare(ctx, coord)
+}
+
+func TestReconstructor(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	server, err := NewProofServer(eds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first row is held.
+	data := make([][]byte, 64)
+	copy(data, eds.flattened()[:8])
+	fetcher := &countingFetcher{ShareFetcher: ProofServerFetcher{Server: server}, delay: time.Millisecond}
+	r, err := NewReconstructor(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, fetcher, WithMaxInFlight(3))
+	assert.NoError(t, err)
+	repaired, err := r.Reconstruct(context.Background(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, eds.flattened(), repaired.flattened())
+	// Three more rows make the square repairable through its columns.
+	assert.Equal(t, 3*4, fetcher.fetches)
+	assert.LessOrEqual(t, fetcher.maxInFlight, 3)
+
+	// Withholding the parity quadrants and more leaves too few shares.
+	withholding := &withholdingFetcher{ShareFetcher: ProofServerFetcher{Server: server}, withhold: func(coord Coordinate) bool {
+		return coord.Row >= 3 || coord.Col >= 3
+	}}
+	r, err = NewReconstructor(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, withholding, WithFetchRetries(0))
+	assert.NoError(t, err)
+	_, err = r.Reconstruct(context.Background(), make([][]byte, 64))
+	var unrepairable *ErrUnrepairable
+	assert.True(t, errors.As(err, &unrepairable), "got %v", err)
+
+	// Corrupted shares are rejected and fetched again.
+	corrupting := &withholdingFetcher{ShareFetcher: ProofServerFetcher{Server: server}, corrupt: true}
+	r, err = NewReconstructor(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, corrupting)
+	assert.NoError(t, err)
+	_, err = r.Reconstruct(context.Background(), data)
+	assert.True(t, errors.As(err, &unrepairable), "got %v", err)
+
+	slow := &countingFetcher{ShareFetcher: ProofServerFetcher{Server: server}, delay: time.Second}
+	r, err = NewReconstructor(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, slow,
+		WithFetchTimeout(time.Millisecond), WithFetchRetries(0))
+	assert.NoError(t, err)
+	_, err = r.Reconstruct(context.Background(), data)
+	assert.True(t, errors.As(err, &unrepairable), "got %v", err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	r, err = NewReconstructor(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, slow)
+	assert.NoError(t, err)
+	_, err = r.Reconstruct(ctx, data)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestPlanFetches(t *testing.T) {
+	present := newBitMatrix(4)
+	unavailable := newBitMatrix(4)
+	present.Set(0, 0)
+	present.Set(0, 1)
+
+	// Row 0 is decodable, and each column needs one more share, after
+	// which the other rows are decodable.
+	plan, complete := planFetches(present, unavailable, 2)
+	assert.True(t, complete)
+	assert.Equal(t, []Coordinate{{Row: 1, Col: 0}, {Row: 1, Col: 1}}, plan)
+
+	unavailable.Set(1, 0)
+	plan, complete = planFetches(present, unavailable, 2)
+	assert.True(t, complete)
+	assert.Equal(t, []Coordinate{{Row: 2, Col: 0}, {Row: 1, Col: 1}}, plan)
+
+	for r := 1; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			unavailable.Set(r, c)
+		}
+	}
+	_, complete = planFetches(present, unavailable, 2)
+	assert.False(t, complete)
+
+	plan, complete = planFetches(completeCrossword(present, 1), unavailable, 1)
+	assert.True(t, complete)
+	assert.Empty(t, plan)
+}
+
+// proveCell returns a proof of the cell against its row root.
+func proveCell(t *testing.T, eds *ExtendedDataSquare, row uint, col uint) CellProof {
+	proof, err := eds.ProveCellOnAxis(Coordinate{Row: row, Col: col}, RowAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proof
+}
+
+// forgeCell returns a proof of the cell whose share has been modified.
+func forgeCell(t *testing.T, eds *ExtendedDataSquare, row uint, col uint) CellProof {
+	proof := proveCell(t, eds, row, col)
+	proof.Proof.Set = append([][]byte(nil), proof.Proof.Set...)
+	proof.Proof.Set[0] = append([]byte(nil), proof.Proof.Set[0]...)
+	proof.Proof.Set[0][0] ^= 1
+	return proof
+}
+
+func TestIncrementalSolver(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	s, err := NewIncrementalSolver(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+	for c := uint(0); c < 4; c++ {
+		assert.False(t, s.Repairable())
+		assert.NoError(t, s.AddShare(proveCell(t, eds, 0, c)))
+		assert.True(t, s.IsPresent(Coordinate{Row: 0, Col: c}))
+	}
+	assert.False(t, s.Repairable())
+	assert.Equal(t, ErrInvalidShareProof, s.AddShare(forgeCell(t, eds, 3, 2)))
+	assert.False(t, s.IsPresent(Coordinate{Row: 3, Col: 2}))
+	assert.NoError(t, s.AddShare(proveCell(t, eds, 3, 2)))
+	assert.NoError(t, s.AddTrustedShare(Coordinate{Row: 3, Col: 0}, eds.getCell(3, 0)))
+	assert.True(t, s.Repairable())
+	repaired, err := s.Solve()
+	assert.NoError(t, err)
+	assert.Equal(t, eds.flattened(), repaired.flattened())
+
+	assert.Error(t, s.AddShare(CellProof{Coord: Coordinate{Row: 4, Col: 0}}))
+	assert.Error(t, s.AddTrustedShare(Coordinate{Row: 4, Col: 0}, eds.getCell(0, 0)))
+	assert.True(t, errors.Is(s.AddTrustedShare(Coordinate{Row: 1, Col: 0}, []byte{1}), ErrInvalidChunkSize))
+	_, err = NewIncrementalSolver(eds.RowRoots()[:3], eds.ColRoots()[:3], NewRSGF8Codec(), NewDefaultTree)
+	assert.Error(t, err)
+
+	// The chunk size is fixed by proven shares, not by a malformed trusted
+	// share added first.
+	s, err = NewIncrementalSolver(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+	assert.NoError(t, s.AddTrustedShare(Coordinate{Row: 2, Col: 2}, []byte{1}))
+	assert.NoError(t, s.AddShare(proveCell(t, eds, 0, 0)))
+	assert.False(t, s.IsPresent(Coordinate{Row: 2, Col: 2}))
+	assert.Equal(t, IncrementalStats{Added: 1, Evicted: 1}, s.Stats())
+	assert.True(t, errors.Is(s.AddTrustedShare(Coordinate{Row: 2, Col: 2}, []byte{1}), ErrInvalidChunkSize))
+	assert.NoError(t, s.AddTrustedShare(Coordinate{Row: 2, Col: 2}, eds.getCell(2, 2)))
+}
+
+func TestIncrementalSolverIngestionQueue(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	s, err := NewIncrementalSolver(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, WithIngestionQueue(3))
+	assert.NoError(t, err)
+
+	// A flood of shares is bounded by the queue.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	dropped := 0
+	for c := uint(0); c < 4; c++ {
+		proofs := make([]CellProof, 4)
+		for r := range proofs {
+			proofs[r] = proveCell(t, eds, uint(r), c)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, proof := range proofs {
+				if err := s.TryAddShare(proof); err != nil {
+					assert.Equal(t, ErrIngestionQueueFull, err)
+					mu.Lock()
+					dropped++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, IncrementalStats{Dropped: 13, Queued: 3}, s.Stats())
+	assert.Equal(t, 13, dropped)
+
+	s.Drain()
+	assert.Equal(t, IncrementalStats{Added: 3, Dropped: 13}, s.Stats())
+	// Queued shares are verified when drained.
+	forged := forgeCell(t, eds, 0, 0)
+	assert.NoError(t, s.TryAddShare(forged))
+	assert.Error(t, s.TryAddShare(CellProof{Coord: Coordinate{Row: 0, Col: 4}}))
+	s.Drain()
+	assert.Equal(t, 1, s.Stats().Rejected)
+
+	// Queued shares are added before solving.
+	for r := uint(0); r < 2; r++ {
+		for c := uint(0); c < 2; c++ {
+			if !s.IsPresent(Coordinate{Row: r, Col: c}) {
+				assert.NoError(t, s.TryAddShare(proveCell(t, eds, r, c)))
+				s.Drain()
+			}
+		}
+	}
+	assert.True(t, s.Repairable())
+	repaired, err := s.Solve()
+	assert.NoError(t, err)
+	assert.Equal(t, eds.flattened(), repaired.flattened())
+
+	_, err = NewIncrementalSolver(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree, WithIngestionQueue(0))
+	assert.Error(t, err)
+}
+
+func TestIncrementalSolverDuplicates(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	s, err := NewIncrementalSolver(eds.RowRoots(), eds.ColRoots(), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+
+	// A forged share arriving first does not take the cell.
+	coord := Coordinate{Row: 1, Col: 2}
+	assert.Equal(t, ErrInvalidShareProof, s.AddShare(forgeCell(t, eds, 1, 2)))
+	assert.NoError(t, s.AddShare(proveCell(t, eds, 1, 2)))
+	assert.NoError(t, s.AddShare(proveCell(t, eds, 1, 2)))
+	assert.Equal(t, IncrementalStats{Added: 1, Rejected: 1, Duplicates: 1}, s.Stats())
+	assert.Empty(t, s.Equivocations())
+
+	// A proven share replaces a differing trusted share.
+	wrong := eds.getCell(0, 0)
+	wrong[0] ^= 1
+	assert.NoError(t, s.AddTrustedShare(Coordinate{Row: 0, Col: 0}, wrong))
+	assert.NoError(t, s.AddShare(proveCell(t, eds, 0, 0)))
+	assert.Error(t, s.AddTrustedShare(Coordinate{Row: 0, Col: 0}, wrong))
+	assert.Equal(t, 2, s.Stats().Conflicts)
+	assert.Empty(t, s.Equivocations())
+
+	for r := uint(0); r < 2; r++ {
+		for c := uint(0); c < 2; c++ {
+			assert.NoError(t, s.AddShare(proveCell(t, eds, r, c)))
+		}
+	}
+	repaired, err := s.Solve()
+	assert.NoError(t, err)
+	assert.Equal(t, eds.flattened(), repaired.flattened())
+
+	// Roots committing to different shares for a cell through its row and
+	// its column are proven to equivocate.
+	flattened := eds.flattened()
+	flattened[1*4+2] = append([]byte(nil), flattened[1*4+2]...)
+	flattened[1*4+2][0] ^= 1
+	other, err := ImportExtendedDataSquare(flattened, NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	s, err = NewIncrementalSolver(eds.RowRoots(), other.ColRoots(), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+	assert.NoError(t, s.AddShare(proveCell(t, eds, 1, 2)))
+	colProof, err := other.ProveCellOnAxis(coord, ColAxis)
+	assert.NoError(t, err)
+	err = s.AddShare(colProof)
+	var equivocation *ErrShareEquivocation
+	if assert.True(t, errors.As(err, &equivocation)) {
+		assert.Equal(t, coord, equivocation.Coord)
+		assert.Equal(t, eds.getCell(1, 2), equivocation.First)
+		assert.Equal(t, other.getCell(1, 2), equivocation.Second)
+	}
+	assert.Len(t, s.Equivocations(), 1)
+}
@@ -0,0 +1,101 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Repack returns a new square holding the original data of the square
+// re-chunked into chunks of chunkSize bytes, for migrations between share
+// sizes. The original chunks are concatenated in row-major order and split
+// into chunks of the new size, splitting or joining shares as needed, and the
+// last chunk and any further cells of the smallest square holding them are
+// padded with zeros. The new square is extended with the codec and tree
+// constructor of the square, or a larger codec it is promoted to, see Grow.
+//
+// The square is first checked against dataRoot, failing with
+// ErrDataRootMismatch, so that only data that was committed to is migrated.
+func (eds *ExtendedDataSquare) Repack(chunkSize uint, dataRoot []byte) (*ExtendedDataSquare, error) {
+	if eds.codec == nil {
+		return nil, errors.New("square has no codec")
+	}
+	if chunkSize == 0 {
+		return nil, fmt.Errorf("%w: chunks must not be empty", ErrInvalidChunkSize)
+	}
+	if !bytes.Equal(eds.DataRoot(), dataRoot) {
+		return nil, ErrDataRootMismatch
+	}
+
+	k := eds.originalDataWidth
+	size := k * k * eds.chunkSize
+	chunks := (size + chunkSize - 1) / chunkSize
+	width := uint(1)
+	for width*width < chunks {
+		width++
+	}
+	codec, err := promoteCodec(eds.codec, int(width))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkChunkSize(codec, chunkSize); err != nil {
+		return nil, err
+	}
+
+	stream := make([]byte, 0, width*width*chunkSize)
+	for i := uint(0); i < k; i++ {
+		for _, chunk := range eds.rowSlice(i, 0, k) {
+			stream = append(stream, chunk...)
+		}
+	}
+	stream = stream[:cap(stream)]
+
+	original := make([][]byte, width*width)
+	for i := range original {
+		original[i] = stream[uint(i)*chunkSize : uint(i+1)*chunkSize]
+	}
+	return ComputeExtendedDataSquare(original, codec, eds.createTreeFn)
+}
+
+// Resize returns a new square holding the original data of the square laid
+// out in an original square of the given width, for protocol upgrades that
+// change square dimensions. The original chunks keep their row-major order,
+// so the chunk at (r, c) moves to the cell with the same row-major index in
+// the new square, and the cells after them are padded with zero chunks. The
+// width must leave room for all original chunks. The new square is extended
+// with the codec and tree constructor of the square, or a larger codec it is
+// promoted to, see Grow.
+//
+// The returned mapping holds the new coordinates of the original cells,
+// mapping[r*k+c] being those of (r, c) where k is the current original
+// width.
+func (eds *ExtendedDataSquare) Resize(width uint) (*ExtendedDataSquare, []Coordinate, error) {
+	if eds.codec == nil {
+		return nil, nil, errors.New("square has no codec")
+	}
+	k := eds.originalDataWidth
+	if width*width < k*k {
+		return nil, nil, fmt.Errorf("width %d cannot hold the %d original chunks", width, k*k)
+	}
+	codec, err := promoteCodec(eds.codec, int(width))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	padding := make([]byte, eds.chunkSize)
+	original := make([][]byte, width*width)
+	mapping := make([]Coordinate, k*k)
+	for i := range original {
+		if uint(i) >= k*k {
+			original[i] = padding
+			continue
+		}
+		original[i] = eds.getCell(uint(i)/k, uint(i)%k)
+		mapping[i] = Coordinate{Row: uint(i) / width, Col: uint(i) % width}
+	}
+	resized, err := ComputeExtendedDataSquare(original, codec, eds.createTreeFn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resized, mapping, nil
+}
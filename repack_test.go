@@ -0,0 +1,82 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// odsStream concatenates the original chunks of eds in row-major order.
+func odsStream(eds *ExtendedDataSquare) []byte {
+	var stream []byte
+	for i := uint(0); i < eds.Width()/2; i++ {
+		for _, chunk := range eds.Row(i)[:eds.Width()/2] {
+			stream = append(stream, chunk...)
+		}
+	}
+	return stream
+}
+
+func TestRepack(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	stream := odsStream(eds)
+
+	// Joining pairs of 256-byte shares halves the number of chunks, which
+	// needs a 3x3 square padded with zeros.
+	repacked, err := eds.Repack(512, eds.DataRoot())
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(6), repacked.Width())
+		assert.Equal(t, uint(512), repacked.ChunkSize())
+		got := odsStream(repacked)
+		assert.Equal(t, stream, got[:len(stream)])
+		assert.Equal(t, make([]byte, len(got)-len(stream)), got[len(stream):])
+	}
+
+	// Splitting shares in four quadruples the number of chunks.
+	repacked, err = eds.Repack(64, eds.DataRoot())
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(16), repacked.Width())
+		got := odsStream(repacked)
+		assert.True(t, bytes.Equal(stream, got))
+	}
+
+	_, err = eds.Repack(64, eds.getRowRoot(0))
+	assert.Equal(t, ErrDataRootMismatch, err)
+	_, err = eds.Repack(0, eds.DataRoot())
+	assert.Error(t, err)
+}
+
+func TestResize(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(3), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, width := range []uint{3, 4, 5} {
+		resized, mapping, err := eds.Resize(width)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, 2*width, resized.Width())
+		assert.Len(t, mapping, 9)
+		for i, coord := range mapping {
+			assert.Equal(t, eds.getCell(uint(i)/3, uint(i)%3), resized.getCell(coord.Row, coord.Col))
+		}
+		assert.Equal(t, odsStream(eds), odsStream(resized)[:9*256])
+	}
+
+	_, mapping, err := eds.Resize(4)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Coordinate{Row: 0, Col: 3}, mapping[3])
+		assert.Equal(t, Coordinate{Row: 2, Col: 0}, mapping[8])
+	}
+
+	_, _, err = eds.Resize(2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package rsmt2d
+
+import "bytes"
+
+// WithRepairProofs makes repair append, for every cell it reconstructs, an
+// inclusion proof of the cell against its row root. Each row holding
+// reconstructed cells is checked against its expected root first, so the
+// proofs certify the repaired shares by themselves and can be stored or
+// forwarded without verifying the square again.
+func WithRepairProofs(proofs *[]CellProof) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.repairProofs = proofs
+	}
+}
+
+// proveRepairedCells appends proofs of the cells that were not present to
+// cfg.repairProofs, one row at a time.
+func (eds *ExtendedDataSquare) proveRepairedCells(
+	rowRoots [][]byte,
+	isPresent func(r, c uint) bool,
+	cfg *repairConfig,
+) error {
+	for r := uint(0); r < eds.width; r++ {
+		var cols []uint
+		for c := uint(0); c < eds.width; c++ {
+			if !isPresent(r, c) {
+				cols = append(cols, c)
+			}
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		if !bytes.Equal(eds.getRowRoot(r), rowRoots[r]) {
+			return eds.errByzantine(RowAxis, r)
+		}
+		proofs, err := eds.ProveRowCells(r, cols)
+		if err != nil {
+			return err
+		}
+		for i, c := range cols {
+			*cfg.repairProofs = append(*cfg.repairProofs, CellProof{
+				Coord: Coordinate{Row: r, Col: c},
+				Axis:  RowAxis,
+				Proof: proofs[i],
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairProofs(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := original.getRowRoots(), original.getColRoots()
+
+	flattened := original.flattened()
+	missing := map[Coordinate]bool{}
+	for i := 0; i < len(flattened); i += 3 {
+		flattened[i] = nil
+		missing[Coordinate{Row: uint(i) / 8, Col: uint(i) % 8}] = true
+	}
+	var proofs []CellProof
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, flattened, codec, NewDefaultTree, WithRepairProofs(&proofs))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, proofs, len(missing))
+	for i := range proofs {
+		p := &proofs[i]
+		assert.True(t, missing[p.Coord])
+		assert.Equal(t, original.getCell(p.Coord.Row, p.Coord.Col), p.Share())
+		assert.NoError(t, p.Verify(rowRoots, colRoots, NewDefaultTree))
+	}
+}
@@ -0,0 +1,224 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// SquareRoots holds the commitments to a square without its shares: the row
+// and column roots, the data root computed from them, the width of the
+// extended square and the name of the codec it was extended with. It is all
+// a light client needs to verify proofs against the square.
+type SquareRoots struct {
+	Width    uint     `json:"width"`
+	Codec    string   `json:"codec"`
+	RowRoots [][]byte `json:"row_roots"`
+	ColRoots [][]byte `json:"col_roots"`
+	DataRoot []byte   `json:"data_root"`
+}
+
+// Roots returns the commitments to the square.
+func (eds *ExtendedDataSquare) Roots() (*SquareRoots, error) {
+	if eds.codec == nil {
+		return nil, errors.New("square has no codec")
+	}
+	name, err := codecName(eds.codec)
+	if err != nil {
+		return nil, err
+	}
+	rowRoots, colRoots := eds.getRowRoots(), eds.getColRoots()
+	return &SquareRoots{
+		Width:    eds.width,
+		Codec:    name,
+		RowRoots: rowRoots,
+		ColRoots: colRoots,
+		DataRoot: DataRoot(rowRoots, colRoots),
+	}, nil
+}
+
+// Verify checks that the roots are consistent with each other: they pass
+// VerifyRootsConsistency for the codec, and the data root commits to the row
+// and column roots.
+func (r *SquareRoots) Verify() error {
+	codec, err := CodecByName(r.Codec)
+	if err != nil {
+		return err
+	}
+	if uint(len(r.RowRoots)) != r.Width {
+		return fmt.Errorf("square of width %d has %d row roots", r.Width, len(r.RowRoots))
+	}
+	if err := VerifyRootsConsistency(r.RowRoots, r.ColRoots, codec); err != nil {
+		return err
+	}
+	if !bytes.Equal(DataRoot(r.RowRoots, r.ColRoots), r.DataRoot) {
+		return ErrDataRootMismatch
+	}
+	return nil
+}
+
+// VerifyRootsConsistency checks that row and column roots can belong to a
+// square extended with codec, so that malformed headers are rejected before
+// any share is fetched: there must be as many row as column roots, their
+// number must be an even width supported by the codec, and all roots must
+// have the same, non-zero size.
+//
+// Merkle roots carry no algebraic relation to each other, so roots that pass
+// may still not commit to any correctly extended square. That can only be
+// detected by fetching shares, which BadEncodingProof then proves.
+func VerifyRootsConsistency(rowRoots [][]byte, colRoots [][]byte, codec Codec) error {
+	width := len(rowRoots)
+	if len(colRoots) != width {
+		return fmt.Errorf("%d row roots but %d column roots", width, len(colRoots))
+	}
+	if width%2 != 0 || !IsValidWidth(width/2, codec) {
+		return fmt.Errorf("square width %d is not supported by the codec", width)
+	}
+	size := len(rowRoots[0])
+	if size == 0 {
+		return errors.New("row root 0 is empty")
+	}
+	for _, axis := range []Axis{RowAxis, ColAxis} {
+		roots := rowRoots
+		if axis == ColAxis {
+			roots = colRoots
+		}
+		for i, root := range roots {
+			if len(root) != size {
+				return fmt.Errorf("%v root %d has size %d, expected %d", axis, i, len(root), size)
+			}
+		}
+	}
+	return nil
+}
+
+// DiffRoots returns the indices of the roots that differ between expected and
+// actual, in increasing order. If one has more roots than the other, the
+// indices of the extra roots are included.
+func DiffRoots(expected [][]byte, actual [][]byte) []uint {
+	n := len(expected)
+	if len(actual) > n {
+		n = len(actual)
+	}
+	var diff []uint
+	for i := 0; i < n; i++ {
+		if i >= len(expected) || i >= len(actual) || !bytes.Equal(expected[i], actual[i]) {
+			diff = append(diff, uint(i))
+		}
+	}
+	return diff
+}
+
+// ErrRootsMismatch is returned when the roots of a square differ from the
+// expected ones, listing the rows and columns that diverge. It matches
+// ErrDataRootMismatch with errors.Is.
+type ErrRootsMismatch struct {
+	Rows []uint
+	Cols []uint
+}
+
+func (e *ErrRootsMismatch) Error() string {
+	return fmt.Sprintf("%v: rows %v and columns %v differ", ErrDataRootMismatch, e.Rows, e.Cols)
+}
+
+// Is reports whether target is ErrDataRootMismatch.
+func (e *ErrRootsMismatch) Is(target error) bool {
+	return target == ErrDataRootMismatch
+}
+
+// VerifyRoots checks that the square has the expected row and column roots,
+// returning ErrRootsMismatch listing the divergent axes if not.
+func (eds *ExtendedDataSquare) VerifyRoots(rowRoots [][]byte, colRoots [][]byte) error {
+	if err := eds.validate(); err != nil {
+		return err
+	}
+	rows := DiffRoots(rowRoots, eds.getRowRoots())
+	cols := DiffRoots(colRoots, eds.getColRoots())
+	if rows != nil || cols != nil {
+		return &ErrRootsMismatch{Rows: rows, Cols: cols}
+	}
+	return nil
+}
+
+// ConformsTo checks that the square matches roots, typically taken from a
+// block header: it must have the same width and codec, the same row and
+// column roots (see VerifyRoots), and the data root must commit to them.
+// Every row and column is also re-encoded, using the coding parallelism of
+// the square, to check that its parity is consistent with its data.
+func (eds *ExtendedDataSquare) ConformsTo(roots SquareRoots) error {
+	if err := eds.validate(); err != nil {
+		return err
+	}
+	if roots.Width != eds.width {
+		return fmt.Errorf("square has width %d, expected %d", eds.width, roots.Width)
+	}
+	if eds.codec == nil {
+		return errors.New("square has no codec")
+	}
+	name, err := codecName(eds.codec)
+	if err != nil {
+		return err
+	}
+	if name != roots.Codec {
+		return fmt.Errorf("square was extended with codec %s, expected %s", name, roots.Codec)
+	}
+	if err := eds.VerifyRoots(roots.RowRoots, roots.ColRoots); err != nil {
+		return err
+	}
+	if !bytes.Equal(DataRoot(roots.RowRoots, roots.ColRoots), roots.DataRoot) {
+		return ErrDataRootMismatch
+	}
+	return eds.verifyAllParity()
+}
+
+// VerifyCell checks a cell proof against the roots.
+func (r *SquareRoots) VerifyCell(proof *CellProof, treeCreatorFn TreeConstructorFn) error {
+	return proof.Verify(r.RowRoots, r.ColRoots, treeCreatorFn)
+}
+
+// VerifyAxisRoot checks that a proven row or column root is committed to by
+// the data root.
+func (r *SquareRoots) VerifyAxisRoot(proof *AxisRootProof) error {
+	return proof.Verify(r.DataRoot, r.Width)
+}
+
+// MarshalBinary encodes the roots as a version byte followed by the width,
+// the codec name, the row roots, the column roots and the data root. The
+// width is an unsigned varint, and byte strings and lists are prefixed with
+// their length as an unsigned varint.
+func (r *SquareRoots) MarshalBinary() ([]byte, error) {
+	b := []byte{RootsFormatVersion}
+	b = appendUvarint(b, uint64(r.Width))
+	b = appendBytes(b, []byte(r.Codec))
+	for _, roots := range [][][]byte{r.RowRoots, r.ColRoots} {
+		b = appendUvarint(b, uint64(len(roots)))
+		for _, root := range roots {
+			b = appendBytes(b, root)
+		}
+	}
+	return appendBytes(b, r.DataRoot), nil
+}
+
+// UnmarshalBinary decodes roots encoded with MarshalBinary.
+func (r *SquareRoots) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errMalformedEncoding
+	}
+	if !FormatRoots.IsCompatible(uint(data[0])) {
+		return fmt.Errorf("unsupported roots format version %d", data[0])
+	}
+	d := &decoder{buf: data[1:]}
+	roots := SquareRoots{Width: uint(d.uvarint()), Codec: string(d.bytes())}
+	for _, dst := range []*[][]byte{&roots.RowRoots, &roots.ColRoots} {
+		*dst = make([][]byte, d.count())
+		for i := range *dst {
+			(*dst)[i] = d.bytes()
+		}
+	}
+	roots.DataRoot = d.bytes()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*r = roots
+	return nil
+}
@@ -0,0 +1,135 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSquareRoots(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	roots, err := eds.Roots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint(8), roots.Width)
+	assert.Equal(t, "RSGF8", roots.Codec)
+	assert.Equal(t, eds.DataRoot(), roots.DataRoot)
+	assert.NoError(t, roots.Verify())
+
+	data, err := roots.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded SquareRoots
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, roots, &decoded)
+	assert.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]))
+
+	data, err = json.Marshal(roots)
+	assert.NoError(t, err)
+	decoded = SquareRoots{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, roots, &decoded)
+
+	cellProof, err := eds.ProveCellOnAxis(Coordinate{Row: 3, Col: 6}, RowAxis)
+	assert.NoError(t, err)
+	assert.NoError(t, roots.VerifyCell(&cellProof, NewDefaultTree))
+	rootProof, err := eds.ProveAxisRoot(ColAxis, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, roots.VerifyAxisRoot(&rootProof))
+
+	tampered := *roots
+	tampered.RowRoots = append([][]byte(nil), roots.RowRoots...)
+	tampered.RowRoots[0] = roots.RowRoots[1]
+	assert.Equal(t, ErrDataRootMismatch, tampered.Verify())
+
+	tampered = *roots
+	tampered.ColRoots = roots.ColRoots[:7]
+	assert.Error(t, tampered.Verify())
+
+	tampered = *roots
+	tampered.Codec = "unknown"
+	assert.Error(t, tampered.Verify())
+}
+
+func TestVerifyRootsConsistency(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+	assert.NoError(t, VerifyRootsConsistency(rowRoots, colRoots, codec))
+
+	assert.Error(t, VerifyRootsConsistency(rowRoots, colRoots[:3], codec))
+	assert.Error(t, VerifyRootsConsistency(rowRoots[:3], colRoots[:3], codec))
+	assert.Error(t, VerifyRootsConsistency(nil, nil, codec))
+
+	truncated := append([][]byte(nil), colRoots...)
+	truncated[2] = truncated[2][:len(truncated[2])-1]
+	assert.Error(t, VerifyRootsConsistency(rowRoots, truncated, codec))
+}
+
+func TestDiffRoots(t *testing.T) {
+	a := [][]byte{{1}, {2}, {3}}
+	assert.Nil(t, DiffRoots(a, a))
+	assert.Equal(t, []uint{1}, DiffRoots(a, [][]byte{{1}, {9}, {3}}))
+	assert.Equal(t, []uint{0, 3}, DiffRoots(a, [][]byte{{0}, {2}, {3}, {4}}))
+	assert.Equal(t, []uint{2}, DiffRoots(a, a[:2]))
+
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+	rowRoots := append([][]byte(nil), eds.RowRoots()...)
+	colRoots := eds.ColRoots()
+	assert.NoError(t, eds.VerifyRoots(rowRoots, colRoots))
+
+	rowRoots[3] = colRoots[0]
+	err = eds.VerifyRoots(rowRoots, colRoots)
+	assert.True(t, errors.Is(err, ErrDataRootMismatch))
+	var mismatch *ErrRootsMismatch
+	if assert.True(t, errors.As(err, &mismatch)) {
+		assert.Equal(t, []uint{3}, mismatch.Rows)
+		assert.Nil(t, mismatch.Cols)
+	}
+}
+
+func TestConformsTo(t *testing.T) {
+	codec := NewRSGF8Codec()
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree, WithCodingParallelism(3))
+	assert.NoError(t, err)
+	roots, err := eds.Roots()
+	assert.NoError(t, err)
+	assert.NoError(t, eds.ConformsTo(*roots))
+
+	wrong := *roots
+	wrong.Width = 4
+	assert.Error(t, eds.ConformsTo(wrong))
+
+	wrong = *roots
+	wrong.Codec = "other"
+	assert.Error(t, eds.ConformsTo(wrong))
+
+	wrong = *roots
+	wrong.DataRoot = wrong.RowRoots[0]
+	assert.Equal(t, ErrDataRootMismatch, eds.ConformsTo(wrong))
+
+	wrong = *roots
+	wrong.ColRoots = append([][]byte(nil), roots.ColRoots...)
+	wrong.ColRoots[2] = roots.RowRoots[2]
+	var mismatch *ErrRootsMismatch
+	if assert.True(t, errors.As(eds.ConformsTo(wrong), &mismatch)) {
+		assert.Equal(t, []uint{2}, mismatch.Cols)
+	}
+
+	// A square with bad parity behind consistent roots.
+	eds.setCell(1, 1, bytes.Repeat([]byte{9}, int(eds.chunkSize)))
+	roots, err = eds.Roots()
+	assert.NoError(t, err)
+	var parity *ErrParityInconsistency
+	assert.True(t, errors.As(eds.ConformsTo(*roots), &parity))
+}
@@ -0,0 +1,185 @@
+// Package rsmt2dtest provides generators of random squares, erasure patterns
+// and byzantine mutations, for property-based tests of code built on rsmt2d.
+//
+// Each generated type implements quick.Generator, so that it can be taken as
+// an argument by a property checked with testing/quick:
+//
+//	quick.Check(func(e rsmt2dtest.RepairableErasures) bool {
+//		_, err := rsmt2d.RepairExtendedDataSquare(e.EDS.RowRoots(), e.EDS.ColRoots(),
+//			e.Shares, rsmt2d.NewRSGF8Codec(), rsmt2d.NewDefaultTree)
+//		return err == nil
+//	}, nil)
+//
+// The size passed to Generate bounds the chunk size. Squares are extended with
+// the RSGF8 codec and the default tree.
+package rsmt2dtest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+
+	"github.com/lazyledger/rsmt2d"
+)
+
+// MaxOriginalWidth is the largest width of the original data of generated
+// squares, keeping properties fast to check.
+const MaxOriginalWidth = 8
+
+// Square is a random extended data square with the original data it was
+// extended from.
+type Square struct {
+	Original [][]byte
+	EDS      *rsmt2d.ExtendedDataSquare
+}
+
+// NewSquare returns a random square of original width k with chunks of
+// chunkSize bytes.
+func NewSquare(r *rand.Rand, k int, chunkSize int) Square {
+	original := make([][]byte, k*k)
+	for i := range original {
+		original[i] = make([]byte, chunkSize)
+		r.Read(original[i])
+	}
+	eds, err := rsmt2d.ComputeExtendedDataSquare(original, rsmt2d.NewRSGF8Codec(), rsmt2d.NewDefaultTree)
+	if err != nil {
+		panic(fmt.Sprintf("extending a random square failed: %v", err))
+	}
+	return Square{Original: original, EDS: eds}
+}
+
+// Generate returns a random square of original width up to
+// MaxOriginalWidth, with chunks of up to size bytes.
+func (Square) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randomSquare(r, size))
+}
+
+func randomSquare(r *rand.Rand, size int) Square {
+	if size < 1 {
+		size = 1
+	}
+	return NewSquare(r, 1+r.Intn(MaxOriginalWidth), 1+r.Intn(size))
+}
+
+// Cells returns copies of the shares of the square in row-major order.
+func (s Square) Cells() [][]byte {
+	width := s.EDS.Width()
+	shares := make([][]byte, 0, width*width)
+	for row := uint(0); row < width; row++ {
+		for col := uint(0); col < width; col++ {
+			share, err := s.EDS.GetCell(row, col)
+			if err != nil {
+				panic(fmt.Sprintf("reading a generated square failed: %v", err))
+			}
+			shares = append(shares, share)
+		}
+	}
+	return shares
+}
+
+// RepairableErasures is a random square with random shares erased, below the
+// repair threshold: RepairExtendedDataSquare restores the square from Shares
+// and the roots of EDS.
+type RepairableErasures struct {
+	Square
+	// Shares are the shares of the square in row-major order, erased
+	// shares being nil.
+	Shares [][]byte
+}
+
+// NewRepairableErasures erases random shares of s, keeping it repairable.
+func NewRepairableErasures(r *rand.Rand, s Square) RepairableErasures {
+	k := int(s.EDS.Width() / 2)
+	// Keeping the intersection of k rows and k columns is enough for those
+	// rows, and then every column, to be decoded. Every other share is kept
+	// at random, as more shares never prevent repair.
+	rows, cols := pickAxes(r, 2*k, k), pickAxes(r, 2*k, k)
+	shares := s.Cells()
+	for i := range shares {
+		if !(rows[i/(2*k)] && cols[i%(2*k)]) && r.Intn(2) == 0 {
+			shares[i] = nil
+		}
+	}
+	return RepairableErasures{Square: s, Shares: shares}
+}
+
+// Generate returns random erasures of a random square.
+func (RepairableErasures) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(NewRepairableErasures(r, randomSquare(r, size)))
+}
+
+// UnrepairableErasures is a random square with random shares erased, above
+// the repair threshold: RepairExtendedDataSquare fails with
+// ErrUnrepairableDataSquare given Shares and the roots of EDS.
+type UnrepairableErasures struct {
+	Square
+	// Shares are the shares of the square in row-major order, erased
+	// shares being nil.
+	Shares [][]byte
+}
+
+// NewUnrepairableErasures erases random shares of s, making it unrepairable.
+func NewUnrepairableErasures(r *rand.Rand, s Square) UnrepairableErasures {
+	k := int(s.EDS.Width() / 2)
+	// Erasing the intersection of k+1 rows and k+1 columns leaves each of
+	// them with fewer than k shares, however the rest is decoded. Every
+	// other share is erased at random.
+	rows, cols := pickAxes(r, 2*k, k+1), pickAxes(r, 2*k, k+1)
+	shares := s.Cells()
+	for i := range shares {
+		if (rows[i/(2*k)] && cols[i%(2*k)]) || r.Intn(2) == 0 {
+			shares[i] = nil
+		}
+	}
+	return UnrepairableErasures{Square: s, Shares: shares}
+}
+
+// Generate returns random erasures of a random square.
+func (UnrepairableErasures) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(NewUnrepairableErasures(r, randomSquare(r, size)))
+}
+
+// ByzantineSquare is a random square with one share mutated: repairing
+// Shares against the roots of EDS fails, however the square is repaired.
+type ByzantineSquare struct {
+	Square
+	// Shares are the shares of the square in row-major order, including
+	// the mutated share.
+	Shares [][]byte
+	// Mutated is the coordinate of the mutated share.
+	Mutated rsmt2d.Coordinate
+}
+
+// NewByzantineSquare mutates a random share of s, flipping random bits of it.
+func NewByzantineSquare(r *rand.Rand, s Square) ByzantineSquare {
+	width := int(s.EDS.Width())
+	shares := s.Cells()
+	cell := r.Intn(width * width)
+	share := shares[cell]
+	flipped := r.Intn(len(share))
+	share[flipped] ^= byte(1 + r.Intn(255))
+	for i := range share {
+		if i != flipped && r.Intn(4) == 0 {
+			share[i] ^= byte(r.Intn(256))
+		}
+	}
+	return ByzantineSquare{
+		Square:  s,
+		Shares:  shares,
+		Mutated: rsmt2d.Coordinate{Row: uint(cell / width), Col: uint(cell % width)},
+	}
+}
+
+// Generate returns a random square with a random share mutated.
+func (ByzantineSquare) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(NewByzantineSquare(r, randomSquare(r, size)))
+}
+
+// pickAxes returns a set of n random indices out of width.
+func pickAxes(r *rand.Rand, width int, n int) []bool {
+	picked := make([]bool, width)
+	for _, i := range r.Perm(width)[:n] {
+		picked[i] = true
+	}
+	return picked
+}
@@ -0,0 +1,57 @@
+package rsmt2dtest
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/lazyledger/rsmt2d"
+)
+
+func quickConfig() *quick.Config {
+	return &quick.Config{MaxCount: 50, Rand: rand.New(rand.NewSource(1))}
+}
+
+func repair(s Square, shares [][]byte) (*rsmt2d.ExtendedDataSquare, error) {
+	return rsmt2d.RepairExtendedDataSquare(s.EDS.RowRoots(), s.EDS.ColRoots(), shares, rsmt2d.NewRSGF8Codec(), rsmt2d.NewDefaultTree)
+}
+
+func TestRepairableErasures(t *testing.T) {
+	property := func(e RepairableErasures) bool {
+		eds, err := repair(e.Square, e.Shares)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		return bytes.Equal(eds.DataRoot(), e.EDS.DataRoot())
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnrepairableErasures(t *testing.T) {
+	property := func(e UnrepairableErasures) bool {
+		_, err := repair(e.Square, e.Shares)
+		return errors.Is(err, rsmt2d.ErrUnrepairableDataSquare)
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestByzantineSquare(t *testing.T) {
+	property := func(b ByzantineSquare) bool {
+		original, err := b.EDS.GetCell(b.Mutated.Row, b.Mutated.Col)
+		if err != nil || bytes.Equal(original, b.Shares[b.Mutated.Row*b.EDS.Width()+b.Mutated.Col]) {
+			return false
+		}
+		_, err = repair(b.Square, b.Shares)
+		return err != nil
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,301 @@
+package rsmt2d
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// ErrInvalidSample is returned for a fetched share whose proofs do not
+// verify against the data root.
+var ErrInvalidSample = errors.New("sampled share does not verify against the data root")
+
+// SampledShare is a share fetched from the network together with the proofs
+// linking it to the data root: a proof of the share against the root of its
+// row or column, and a proof of that root against the data root.
+type SampledShare struct {
+	Proof     CellProof
+	RootProof AxisRootProof
+}
+
+// Share returns the sampled share.
+func (s *SampledShare) Share() []byte {
+	return s.Proof.Share()
+}
+
+// Verify checks that the share at coord is committed to by dataRoot, for a
+// square of the given width.
+func (s *SampledShare) Verify(coord Coordinate, dataRoot []byte, width uint, treeCreatorFn TreeConstructorFn) error {
+	if s.Proof.Coord != coord || coord.Row >= width || coord.Col >= width {
+		return ErrInvalidSample
+	}
+	index := coord.Row
+	if s.Proof.Axis == ColAxis {
+		index = coord.Col
+	}
+	if s.RootProof.Axis != s.Proof.Axis || s.RootProof.Index != index {
+		return ErrInvalidSample
+	}
+	if err := s.RootProof.Verify(dataRoot, width); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSample, err)
+	}
+	if err := s.Proof.verifyAgainstRoot(s.RootProof.Root, width, treeCreatorFn); err != nil {
+		if err == ErrTreeNotProvable {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidSample, err)
+	}
+	return nil
+}
+
+// ShareFetcher fetches shares with their proofs from the network. It must be
+// safe for concurrent use.
+type ShareFetcher interface {
+	// FetchShare returns the share at coord. Errors are treated as the
+	// share being unavailable from this attempt.
+	FetchShare(ctx context.Context, coord Coordinate) (SampledShare, error)
+}
+
+// ProofServerFetcher serves FetchShare requests from a ProofServer, for
+// tests and for nodes sampling squares they hold themselves.
+type ProofServerFetcher struct {
+	Server *ProofServer
+}
+
+// FetchShare returns the share at coord with its row proof.
+func (f ProofServerFetcher) FetchShare(ctx context.Context, coord Coordinate) (SampledShare, error) {
+	proof, err := f.Server.Prove(coord, RowAxis)
+	if err != nil {
+		return SampledShare{}, err
+	}
+	rootProof, err := f.Server.eds.ProveAxisRoot(RowAxis, coord.Row)
+	if err != nil {
+		return SampledShare{}, err
+	}
+	return SampledShare{Proof: proof, RootProof: rootProof}, nil
+}
+
+// SamplingClient runs data availability sampling of a square known by its
+// data root and width, as a light client does: it fetches random shares,
+// verifies each against the data root, and concludes that the square is
+// available if all samples are. It is safe for concurrent use.
+type SamplingClient struct {
+	dataRoot []byte
+	width    uint
+	fetcher  ShareFetcher
+	cfg      samplingConfig
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// SamplingOption configures a SamplingClient.
+type SamplingOption func(*samplingConfig)
+
+type samplingConfig struct {
+	retries       int
+	parallelism   int
+	treeCreatorFn TreeConstructorFn
+	validator     ShareValidator
+	source        rand.Source
+}
+
+// WithSampleRetries sets how many times a share that fails to fetch or verify
+// is requested again before the sample counts as unavailable. The default is
+// 2.
+func WithSampleRetries(n int) SamplingOption {
+	return func(cfg *samplingConfig) {
+		cfg.retries = n
+	}
+}
+
+// WithSamplingParallelism sets the number of samples fetched concurrently.
+// The default is 8.
+func WithSamplingParallelism(n int) SamplingOption {
+	return func(cfg *samplingConfig) {
+		cfg.parallelism = n
+	}
+}
+
+// WithSamplingTree sets the tree the axis roots of the square are computed
+// with. The default is NewDefaultTree.
+func WithSamplingTree(treeCreatorFn TreeConstructorFn) SamplingOption {
+	return func(cfg *samplingConfig) {
+		cfg.treeCreatorFn = treeCreatorFn
+	}
+}
+
+// WithSamplingValidator makes the client check every sampled share with
+// validate, after its proofs, counting shares it rejects as invalid.
+func WithSamplingValidator(validate ShareValidator) SamplingOption {
+	return func(cfg *samplingConfig) {
+		cfg.validator = validate
+	}
+}
+
+// WithSamplingSeed makes the sampled coordinates deterministic, for tests.
+// By default they are seeded from crypto/rand, so that servers cannot
+// predict them.
+func WithSamplingSeed(seed int64) SamplingOption {
+	return WithSamplingSource(rand.NewSource(seed))
+}
+
+// WithSamplingSource draws the sampled coordinates from src, for simulations
+// sharing one source of randomness. src is only used by the client, under its
+// lock, so it need not be safe for concurrent use.
+func WithSamplingSource(src rand.Source) SamplingOption {
+	return func(cfg *samplingConfig) {
+		cfg.source = src
+	}
+}
+
+// NewSamplingClient returns a client sampling the square with the given data
+// root and width, the width of the extended square, through fetcher.
+func NewSamplingClient(dataRoot []byte, width uint, fetcher ShareFetcher, opts ...SamplingOption) (*SamplingClient, error) {
+	if width == 0 || width%2 != 0 {
+		return nil, fmt.Errorf("width of an extended square must be even and non-zero, got %d", width)
+	}
+	cfg := samplingConfig{retries: 2, parallelism: 8, treeCreatorFn: NewDefaultTree}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.source == nil {
+		var b [8]byte
+		if _, err := crand.Read(b[:]); err != nil {
+			return nil, err
+		}
+		cfg.source = rand.NewSource(int64(binary.LittleEndian.Uint64(b[:])))
+	}
+	return &SamplingClient{
+		dataRoot: dataRoot,
+		width:    width,
+		fetcher:  fetcher,
+		cfg:      cfg,
+		rnd:      rand.New(cfg.source),
+	}, nil
+}
+
+// SamplingReport is the outcome of SamplingClient.Sample.
+type SamplingReport struct {
+	// Available is set if every sample was fetched and verified.
+	Available bool
+	// Confidence is the probability that sampling would have detected a
+	// square withholding the fewest shares that prevent its repair.
+	Confidence float64
+	// Samples lists the sampled cells and whether each was available, in
+	// the format taken by DetectWithholding.
+	Samples []Sample
+	// Attempts counts fetches, including retries.
+	Attempts int
+	// InvalidShares counts fetched shares whose proofs did not verify.
+	InvalidShares int
+}
+
+// Sample fetches and verifies n distinct random shares, at most all shares of
+// the square. It only fails if n is negative, ctx is done or the tree cannot
+// verify proofs; unavailable shares are reported in the SamplingReport.
+func (c *SamplingClient) Sample(ctx context.Context, n int) (*SamplingReport, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("number of samples must not be negative, got %d", n)
+	}
+	coords := c.pick(n)
+	report := &SamplingReport{Samples: make([]Sample, len(coords))}
+	var mu sync.Mutex
+	err := parallelFor(nil, c.cfg.parallelism, uint(len(coords)), func(i uint) error {
+		available, attempts, invalid, err := c.sample(ctx, coords[i])
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		report.Samples[i] = Sample{Coord: coords[i], Available: available}
+		report.Attempts += attempts
+		report.InvalidShares += invalid
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Available = true
+	for _, s := range report.Samples {
+		report.Available = report.Available && s.Available
+	}
+	report.Confidence = samplingConfidence(c.width, len(coords))
+	return report, nil
+}
+
+// sample fetches the share at coord until it verifies or the retries are
+// exhausted.
+func (c *SamplingClient) sample(ctx context.Context, coord Coordinate) (available bool, attempts int, invalid int, err error) {
+	for attempt := 0; attempt <= c.cfg.retries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return false, attempts, invalid, err
+		}
+		attempts++
+		share, err := c.fetcher.FetchShare(ctx, coord)
+		if err != nil {
+			continue
+		}
+		err = share.Verify(coord, c.dataRoot, c.width, c.cfg.treeCreatorFn)
+		if err == ErrTreeNotProvable {
+			return false, attempts, invalid, err
+		}
+		if err == nil && c.cfg.validator != nil {
+			var meta ProofMetadata
+			if meta, err = share.Proof.Metadata(); err == nil {
+				err = c.cfg.validator(share.Share(), meta)
+			}
+		}
+		if err != nil {
+			invalid++
+			continue
+		}
+		return true, attempts, invalid, nil
+	}
+	return false, attempts, invalid, ctx.Err()
+}
+
+// pick returns n distinct random cells of the square. It uses Floyd's
+// algorithm, so that it takes O(n) time and memory however large the square.
+func (c *SamplingClient) pick(n int) []Coordinate {
+	total := int(c.width * c.width)
+	if n > total {
+		n = total
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	picked := make(map[int]bool, n)
+	coords := make([]Coordinate, 0, n)
+	for j := total - n; j < total; j++ {
+		cell := c.rnd.Intn(j + 1)
+		if picked[cell] {
+			cell = j
+		}
+		picked[cell] = true
+		coords = append(coords, Coordinate{Row: uint(cell) / c.width, Col: uint(cell) % c.width})
+	}
+	return coords
+}
+
+// samplingConfidence returns the probability that n distinct samples of a
+// square of the given width hit at least one of the (k+1)^2 shares an
+// adversary must withhold to prevent repair, k being the original width.
+func samplingConfidence(width uint, n int) float64 {
+	k := float64(width / 2)
+	total := float64(width * width)
+	withheld := (k + 1) * (k + 1)
+	miss := 1.0
+	for i := 0; i < n; i++ {
+		if total-withheld-float64(i) <= 0 {
+			return 1
+		}
+		miss *= (total - withheld - float64(i)) / (total - float64(i))
+	}
+	return 1 - math.Max(miss, 0)
+}
@@ -0,0 +1,161 @@
+package rsmt2d
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withholdingFetcher withholds the cells for which withhold returns true, and
+// fails the first fetch of every cell if flaky is set.
+type withholdingFetcher struct {
+	ShareFetcher
+	withhold func(coord Coordinate) bool
+	corrupt  bool
+	flaky    bool
+
+	mu      sync.Mutex
+	fetched map[Coordinate]bool
+}
+
+func (f *withholdingFetcher) FetchShare(ctx context.Context, coord Coordinate) (SampledShare, error) {
+	if f.withhold != nil && f.withhold(coord) {
+		return SampledShare{}, errors.New("withheld")
+	}
+	if f.flaky {
+		f.mu.Lock()
+		first := !f.fetched[coord]
+		f.fetched[coord] = true
+		f.mu.Unlock()
+		if first {
+			return SampledShare{}, errors.New("timeout")
+		}
+	}
+	share, err := f.ShareFetcher.FetchShare(ctx, coord)
+	if err == nil && f.corrupt {
+		share.Proof.Proof.Set[0] = append([]byte(nil), share.Proof.Proof.Set[0]...)
+		share.Proof.Proof.Set[0][0] ^= 1
+	}
+	return share, err
+}
+
+func TestSamplingClient(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	server, err := NewProofServer(eds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	honest := ProofServerFetcher{Server: server}
+
+	client, err := NewSamplingClient(eds.DataRoot(), eds.Width(), honest, WithSamplingSeed(1))
+	assert.NoError(t, err)
+	report, err := client.Sample(context.Background(), 16)
+	assert.NoError(t, err)
+	assert.True(t, report.Available)
+	assert.Len(t, report.Samples, 16)
+	assert.Equal(t, 16, report.Attempts)
+	assert.Greater(t, report.Confidence, 0.99)
+	seen := make(map[Coordinate]bool)
+	for _, s := range report.Samples {
+		assert.False(t, seen[s.Coord], "cell sampled twice")
+		seen[s.Coord] = true
+	}
+
+	report, err = client.Sample(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Len(t, report.Samples, 64)
+	assert.Equal(t, 1.0, report.Confidence)
+
+	_, err = client.Sample(context.Background(), -1)
+	assert.Error(t, err)
+
+	// Clients drawing from equal sources sample the same cells.
+	a, err := NewSamplingClient(eds.DataRoot(), eds.Width(), honest, WithSamplingSource(rand.NewSource(2)))
+	assert.NoError(t, err)
+	b, err := NewSamplingClient(eds.DataRoot(), eds.Width(), honest, WithSamplingSeed(2))
+	assert.NoError(t, err)
+	assert.Equal(t, a.pick(8), b.pick(8))
+
+	flaky := &withholdingFetcher{ShareFetcher: honest, flaky: true, fetched: make(map[Coordinate]bool)}
+	client, err = NewSamplingClient(eds.DataRoot(), eds.Width(), flaky, WithSamplingSeed(1), WithSampleRetries(1))
+	assert.NoError(t, err)
+	report, err = client.Sample(context.Background(), 8)
+	assert.NoError(t, err)
+	assert.True(t, report.Available)
+	assert.Equal(t, 16, report.Attempts)
+
+	// Withholding a quadrant is detected by the samples that hit it.
+	withholding := &withholdingFetcher{ShareFetcher: honest, withhold: func(coord Coordinate) bool {
+		return coord.Row >= 4 && coord.Col >= 4
+	}}
+	client, err = NewSamplingClient(eds.DataRoot(), eds.Width(), withholding, WithSamplingSeed(1))
+	assert.NoError(t, err)
+	report, err = client.Sample(context.Background(), 16)
+	assert.NoError(t, err)
+	assert.False(t, report.Available)
+	for _, s := range report.Samples {
+		assert.Equal(t, s.Coord.Row < 4 || s.Coord.Col < 4, s.Available)
+	}
+
+	corrupting := &withholdingFetcher{ShareFetcher: honest, corrupt: true}
+	client, err = NewSamplingClient(eds.DataRoot(), eds.Width(), corrupting, WithSamplingSeed(1), WithSampleRetries(0))
+	assert.NoError(t, err)
+	report, err = client.Sample(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.False(t, report.Available)
+	assert.Equal(t, 4, report.InvalidShares)
+
+	// Shares rejected by the validator are invalid.
+	client, err = NewSamplingClient(eds.DataRoot(), eds.Width(), honest, WithSamplingSeed(1), WithSampleRetries(0),
+		WithSamplingValidator(func(share []byte, meta ProofMetadata) error {
+			if meta.Parity {
+				return errors.New("parity share")
+			}
+			return nil
+		}))
+	assert.NoError(t, err)
+	report, err = client.Sample(context.Background(), 64)
+	assert.NoError(t, err)
+	assert.False(t, report.Available)
+	assert.Equal(t, 48, report.InvalidShares)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Sample(ctx, 4)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = NewSamplingClient(eds.DataRoot(), 7, honest)
+	assert.Error(t, err)
+}
+
+func TestSampledShareVerify(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	coord := Coordinate{Row: 3, Col: 1}
+	proof, err := eds.ProveCellOnAxis(coord, ColAxis)
+	assert.NoError(t, err)
+	rootProof, err := eds.ProveAxisRoot(ColAxis, 1)
+	assert.NoError(t, err)
+	share := SampledShare{Proof: proof, RootProof: rootProof}
+	assert.NoError(t, share.Verify(coord, eds.DataRoot(), 4, NewDefaultTree))
+	assert.Equal(t, eds.getCell(3, 1), share.Share())
+
+	assert.True(t, errors.Is(share.Verify(Coordinate{Row: 2, Col: 1}, eds.DataRoot(), 4, NewDefaultTree), ErrInvalidSample))
+	other, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	assert.True(t, errors.Is(share.Verify(coord, other.DataRoot(), 4, NewDefaultTree), ErrInvalidSample))
+	share.RootProof, err = eds.ProveAxisRoot(RowAxis, 3)
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(share.Verify(coord, eds.DataRoot(), 4, NewDefaultTree), ErrInvalidSample))
+}
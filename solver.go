@@ -0,0 +1,52 @@
+package rsmt2d
+
+import "errors"
+
+// Solver reconstructs the missing cells of an incomplete extended data
+// square, allowing alternative reconstruction strategies to be compared on
+// the same inputs.
+type Solver interface {
+	// Solve repairs eds in place. isPresent reports whether a cell was
+	// provided; until they are set, missing cells are reported by
+	// eds.IsMissing and read as nil through Row and Col. Rebuilt rows and
+	// columns must be verified against rowRoots and colRoots, returning
+	// ErrByzantineRow or ErrByzantineCol on mismatch, and an error matching
+	// ErrUnrepairableDataSquare if the square cannot be completed.
+	Solve(
+		eds *ExtendedDataSquare,
+		rowRoots [][]byte,
+		colRoots [][]byte,
+		codec Codec,
+		isPresent func(row, col uint) bool,
+	) error
+}
+
+// crosswordSolver is the default Solver. It iteratively decodes every
+// incomplete row and column until the square is complete or no further
+// progress can be made.
+type crosswordSolver struct {
+	cfg *repairConfig
+}
+
+func (s *crosswordSolver) Solve(
+	eds *ExtendedDataSquare,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	isPresent func(row, col uint) bool,
+) error {
+	bitMask := newBitMatrix(int(eds.width))
+	for r := uint(0); r < eds.width; r++ {
+		for c := uint(0); c < eds.width; c++ {
+			if isPresent(r, c) {
+				bitMask.Set(int(r), int(c))
+			}
+		}
+	}
+
+	err := eds.solveCrossword(rowRoots, colRoots, bitMask, codec, s.cfg)
+	if errors.Is(err, ErrUnrepairableDataSquare) && s.cfg.globalDecoding {
+		return eds.solveGlobal(rowRoots, colRoots, bitMask, codec)
+	}
+	return err
+}
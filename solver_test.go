@@ -0,0 +1,182 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rowsOnlySolver is a Solver that only decodes rows, for testing pluggability.
+type rowsOnlySolver struct {
+	calls int
+}
+
+func (s *rowsOnlySolver) Solve(
+	eds *ExtendedDataSquare,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	isPresent func(row, col uint) bool,
+) error {
+	s.calls++
+	for r := uint(0); r < eds.width; r++ {
+		shares := make([][]byte, eds.width)
+		for c := uint(0); c < eds.width; c++ {
+			if isPresent(r, c) {
+				shares[c] = eds.squareRow[r][c]
+			}
+		}
+		rebuilt, err := codec.Decode(shares)
+		if err != nil {
+			return ErrUnrepairableDataSquare
+		}
+		parity, err := codec.Encode(rebuilt[:eds.originalDataWidth])
+		if err != nil {
+			return err
+		}
+		rebuilt = append(rebuilt[:eds.originalDataWidth], parity...)
+		for c, share := range rebuilt {
+			eds.setCell(r, uint(c), share)
+		}
+	}
+	return eds.verifyAllRoots(rowRoots, colRoots)
+}
+
+func TestWithSolver(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	flattened := original.flattened()
+	for r := 0; r < 8; r++ {
+		flattened[r*8+r] = nil
+	}
+
+	solver := &rowsOnlySolver{}
+	var repaired []RepairedCell
+	result, err := RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithSolver(solver),
+		WithRepairedCells(&repaired),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, solver.calls)
+	assert.Equal(t, original.flattened(), result.flattened())
+	assert.Len(t, repaired, 8)
+
+	// Row 0 has more than half of its cells missing, so the rows-only solver
+	// fails while the default solver recovers it through the columns.
+	flattened = original.flattened()
+	for r := 0; r < 5; r++ {
+		flattened[r*8] = nil
+	}
+	flattened[1], flattened[2], flattened[3], flattened[4] = nil, nil, nil, nil
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree, WithSolver(solver),
+	)
+	assert.ErrorIs(t, err, ErrUnrepairableDataSquare)
+	_, err = RepairExtendedDataSquare(
+		original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree,
+	)
+	assert.NoError(t, err)
+}
+
+// observingSolver records what a solver sees of the missing cells before
+// delegating to rowsOnlySolver.
+type observingSolver struct {
+	missing []Coordinate
+	row     [][]byte
+}
+
+func (s *observingSolver) Solve(
+	eds *ExtendedDataSquare,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	isPresent func(row, col uint) bool,
+) error {
+	for r := uint(0); r < eds.Width(); r++ {
+		for c := uint(0); c < eds.Width(); c++ {
+			if eds.IsMissing(r, c) {
+				s.missing = append(s.missing, Coordinate{Row: r, Col: c})
+			}
+		}
+	}
+	s.row = eds.Row(1)
+	return (&rowsOnlySolver{}).Solve(eds, rowRoots, colRoots, codec, isPresent)
+}
+
+func TestSolverSeesMissingCells(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[1], flattened[6] = nil, nil
+
+	solver := &observingSolver{}
+	result, err := RepairExtendedDataSquare(
+		original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree, WithSolver(solver),
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Coordinate{{Row: 0, Col: 1}, {Row: 1, Col: 2}}, solver.missing)
+		assert.Equal(t, [][]byte{flattened[4], flattened[5], nil, flattened[7]}, solver.row)
+		assert.False(t, result.IsMissing(1, 2))
+		assert.Equal(t, original.Row(1), result.Row(1))
+	}
+}
+
+// getCellSolver records the result of GetCell on a missing and a present
+// cell before delegating to rowsOnlySolver.
+type getCellSolver struct {
+	missingErr error
+	present    []byte
+}
+
+func (s *getCellSolver) Solve(
+	eds *ExtendedDataSquare,
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	codec Codec,
+	isPresent func(row, col uint) bool,
+) error {
+	_, s.missingErr = eds.GetCell(0, 1)
+	s.present, _ = eds.GetCell(0, 0)
+	return (&rowsOnlySolver{}).Solve(eds, rowRoots, colRoots, codec, isPresent)
+}
+
+func TestGetCellMissing(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	flattened := original.flattened()
+	flattened[1] = nil
+
+	solver := &getCellSolver{}
+	result, err := RepairExtendedDataSquare(
+		original.getRowRoots(), original.getColRoots(), flattened, codec, NewDefaultTree, WithSolver(solver),
+	)
+	if assert.NoError(t, err) {
+		var missing *ErrCellMissing
+		if assert.True(t, errors.As(solver.missingErr, &missing)) {
+			assert.Equal(t, Coordinate{Row: 0, Col: 1}, missing.Coord)
+		}
+		assert.Equal(t, flattened[0], solver.present)
+
+		cell, err := result.GetCell(0, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, original.getCell(0, 1), cell)
+	}
+	_, err = original.GetCell(4, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,258 @@
+package rsmt2d
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// spillError wraps an I/O error of the spill store. Accessors of the square
+// cannot return errors, so spill failures panic with a spillError that is
+// recovered at the repair boundary.
+type spillError struct {
+	error
+}
+
+// spillStore keeps a bounded number of the quadrants of an EDS in memory and
+// writes the least recently used ones to a temporary file. The chunks of
+// resident quadrants are held in buffers of the store rather than shared with
+// the slices the square was built from, so that evicting a quadrant frees
+// them. It is not safe for concurrent use.
+type spillStore struct {
+	file      *os.File
+	half      uint // width of a quadrant
+	chunkSize uint
+	capacity  int // maximum number of resident quadrants
+	resident  [4]bool
+	lastUse   [4]uint64
+	clock     uint64
+	evictions int
+}
+
+// enableSpill limits the memory held by the chunks of the square to about
+// maxBytes, keeping at least two quadrants resident so that any row or column
+// is fully available. Chunks set while a quadrant is resident, such as
+// repaired shares, count towards the limit until it is evicted.
+func (ds *dataSquare) enableSpill(maxBytes int) error {
+	file, err := ioutil.TempFile("", "rsmt2d-spill-")
+	if err != nil {
+		return err
+	}
+
+	half := ds.width / 2
+	capacity := maxBytes / int(half*half*ds.chunkSize)
+	if capacity < 2 {
+		capacity = 2
+	}
+	ds.spill = &spillStore{
+		file:      file,
+		half:      half,
+		chunkSize: ds.chunkSize,
+		capacity:  capacity,
+		resident:  [4]bool{true, true, true, true},
+	}
+
+	// Rows are sliced out of the data the square was built from, and
+	// columns may be shared with snapshots: both get their own slices, so
+	// that only the store references the chunks.
+	for i := uint(0); i < ds.width; i++ {
+		ds.squareRow[i] = append([][]byte(nil), ds.squareRow[i]...)
+		ds.squareCol[i] = append([][]byte(nil), ds.squareCol[i]...)
+	}
+	ds.spill.evictExcess(ds, [4]bool{})
+	for q := 0; q < 4; q++ {
+		if ds.spill.resident[q] {
+			ds.spill.own(ds, q)
+		}
+	}
+	return nil
+}
+
+// disableSpill loads all quadrants back into memory and removes the file.
+func (ds *dataSquare) disableSpill() (err error) {
+	s := ds.spill
+	defer func() {
+		if r := recover(); r != nil {
+			spillErr, ok := r.(spillError)
+			if !ok {
+				panic(r)
+			}
+			err = spillErr.error
+		}
+		s.file.Close()
+		os.Remove(s.file.Name())
+		ds.spill = nil
+	}()
+
+	for q := 0; q < 4; q++ {
+		if !s.resident[q] {
+			s.load(ds, q)
+		}
+	}
+	return nil
+}
+
+// withMemoryLimit runs fn with the chunks of the square capped at maxBytes,
+// converting spill failures into errors. A non-positive limit disables it.
+func (ds *dataSquare) withMemoryLimit(maxBytes int, fn func() error) (err error) {
+	if maxBytes <= 0 {
+		return fn()
+	}
+	if err := ds.enableSpill(maxBytes); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			spillErr, ok := r.(spillError)
+			if !ok {
+				panic(r)
+			}
+			err = spillErr.error
+		}
+		if disableErr := ds.disableSpill(); err == nil {
+			err = disableErr
+		}
+	}()
+
+	return fn()
+}
+
+// access makes the cells in rows [r0, r1) and columns [c0, c1) resident.
+func (s *spillStore) access(ds *dataSquare, r0, c0, r1, c1 uint) {
+	var needed [4]bool
+	for q := 0; q < 4; q++ {
+		qr, qc := uint(q/2)*s.half, uint(q%2)*s.half
+		if r0 < qr+s.half && qr < r1 && c0 < qc+s.half && qc < c1 {
+			needed[q] = true
+			s.clock++
+			s.lastUse[q] = s.clock
+			if !s.resident[q] {
+				s.load(ds, q)
+			}
+		}
+	}
+	s.evictExcess(ds, needed)
+}
+
+// evictExcess spills least recently used quadrants, other than the needed
+// ones, until at most capacity quadrants are resident.
+func (s *spillStore) evictExcess(ds *dataSquare, needed [4]bool) {
+	for {
+		count, victim := 0, -1
+		for q := 0; q < 4; q++ {
+			if !s.resident[q] {
+				continue
+			}
+			count++
+			if !needed[q] && (victim == -1 || s.lastUse[q] < s.lastUse[victim]) {
+				victim = q
+			}
+		}
+		if count <= s.capacity || victim == -1 {
+			return
+		}
+		s.evict(ds, victim)
+	}
+}
+
+func (s *spillStore) quadrantBytes() int64 {
+	return int64(s.half * s.half * s.chunkSize)
+}
+
+// evict writes quadrant q to the file and drops it from memory. Rows and
+// columns touching the quadrant get fresh slices, so that slices previously
+// returned by accessors remain intact.
+func (s *spillStore) evict(ds *dataSquare, q int) {
+	qr, qc := uint(q/2)*s.half, uint(q%2)*s.half
+	buf := make([]byte, 0, s.quadrantBytes())
+	for r := qr; r < qr+s.half; r++ {
+		for c := qc; c < qc+s.half; c++ {
+			buf = append(buf, ds.squareRow[r][c]...)
+		}
+	}
+	if _, err := s.file.WriteAt(buf, int64(q)*s.quadrantBytes()); err != nil {
+		panic(spillError{err})
+	}
+
+	for r := qr; r < qr+s.half; r++ {
+		row := append([][]byte(nil), ds.squareRow[r]...)
+		for c := qc; c < qc+s.half; c++ {
+			row[c] = nil
+		}
+		ds.squareRow[r] = row
+	}
+	for c := qc; c < qc+s.half; c++ {
+		col := append([][]byte(nil), ds.squareCol[c]...)
+		for r := qr; r < qr+s.half; r++ {
+			col[r] = nil
+		}
+		ds.squareCol[c] = col
+	}
+	s.resident[q] = false
+	s.evictions++
+}
+
+// streamAxis pushes the shares of a row or column into tree, streaming the
+// shares of spilled quadrants from the file instead of loading them. It
+// reports false, pushing nothing, if tree does not implement StreamingTree.
+func (s *spillStore) streamAxis(ds *dataSquare, tree Tree, axis Axis, index uint) bool {
+	st, ok := tree.(StreamingTree)
+	if !ok {
+		return false
+	}
+	for i := uint(0); i < ds.width; i++ {
+		r, c := index, i
+		if axis == ColAxis {
+			r, c = i, index
+		}
+		idx := SquareIndex{Axis: index, Cell: i}
+		q := int(r/s.half*2 + c/s.half)
+		if s.resident[q] {
+			st.Push(ds.squareRow[r][c], idx)
+			continue
+		}
+		offset := int64(q)*s.quadrantBytes() + int64(((r%s.half)*s.half+c%s.half)*s.chunkSize)
+		if err := st.PushReader(io.NewSectionReader(s.file, offset, int64(s.chunkSize)), idx); err != nil {
+			panic(spillError{err})
+		}
+	}
+	return true
+}
+
+// own copies the chunks of resident quadrant q into a buffer of the store.
+func (s *spillStore) own(ds *dataSquare, q int) {
+	qr, qc := uint(q/2)*s.half, uint(q%2)*s.half
+	buf := make([]byte, 0, s.quadrantBytes())
+	for r := qr; r < qr+s.half; r++ {
+		for c := qc; c < qc+s.half; c++ {
+			buf = append(buf, ds.squareRow[r][c]...)
+		}
+	}
+	s.place(ds, q, buf)
+}
+
+// load reads quadrant q back from the file. The quadrant gets a new buffer,
+// since chunks returned by accessors before it was evicted may still be in
+// use.
+func (s *spillStore) load(ds *dataSquare, q int) {
+	buf := make([]byte, s.quadrantBytes())
+	if _, err := s.file.ReadAt(buf, int64(q)*s.quadrantBytes()); err != nil {
+		panic(spillError{err})
+	}
+	s.place(ds, q, buf)
+	s.resident[q] = true
+}
+
+// place makes the chunks of quadrant q point into buf, which holds them in
+// row-major order.
+func (s *spillStore) place(ds *dataSquare, q int, buf []byte) {
+	qr, qc := uint(q/2)*s.half, uint(q%2)*s.half
+	for r := qr; r < qr+s.half; r++ {
+		for c := qc; c < qc+s.half; c++ {
+			chunk := buf[:s.chunkSize:s.chunkSize]
+			buf = buf[s.chunkSize:]
+			ds.squareRow[r][c] = chunk
+			ds.squareCol[c][r] = chunk
+		}
+	}
+}
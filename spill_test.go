@@ -0,0 +1,63 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairWithMemoryLimit(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(4), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	flattened := original.flattened()
+	for i := 0; i < len(flattened); i += 3 {
+		flattened[i] = nil
+	}
+	result, err := RepairExtendedDataSquare(
+		original.getRowRoots(),
+		original.getColRoots(),
+		flattened,
+		codec,
+		NewDefaultTree,
+		WithMemoryLimit(1),
+	)
+	if assert.NoError(t, err) {
+		assert.Nil(t, result.spill)
+		assert.Equal(t, original.flattened(), result.flattened())
+	}
+}
+
+func TestSpillStore(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	want := eds.flattened()
+
+	if err := eds.enableSpill(0); err != nil {
+		t.Fatal(err)
+	}
+	s := eds.spill
+	assert.Equal(t, 2, s.evictions)
+	// Resident quadrants hold copies of the chunks.
+	assert.Equal(t, want[15], eds.getCell(3, 3))
+	assert.NotSame(t, &want[15][0], &eds.squareRow[3][3][0])
+
+	row := eds.row(0)
+	assert.Equal(t, want[:4], row)
+	assert.Equal(t, want[5], eds.getCell(1, 1))
+
+	// Reading column 3 evicts the left quadrants but not slices handed out.
+	assert.Equal(t, [][]byte{want[3], want[7], want[11], want[15]}, eds.col(3))
+	assert.Equal(t, want[:4], row)
+	assert.False(t, s.resident[0])
+
+	eds.setCell(0, 0, want[15])
+	assert.NoError(t, eds.disableSpill())
+	want[0] = want[15]
+	assert.Equal(t, want, eds.flattened())
+}
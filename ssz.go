@@ -0,0 +1,603 @@
+package rsmt2d
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// SSZ encodings of roots and proofs, so that they can be embedded in SSZ
+// containers and merkleized consistently with them. The schemas are:
+//
+//	class Coordinate(Container):
+//	    row: uint64
+//	    col: uint64
+//
+//	class Proof(Container):
+//	    set: List[ByteList[SSZ_MAX_SHARE_SIZE], SSZ_MAX_PROOF_SET]
+//	    index: uint64
+//	    num_leaves: uint64
+//
+//	class CellProof(Container):
+//	    coord: Coordinate
+//	    axis: uint8
+//	    proof: Proof
+//
+//	class AxisRootProof(Container):
+//	    axis: uint8
+//	    index: uint64
+//	    root: Bytes32
+//	    proof: Proof
+//
+//	class BadEncodingProof(Container):
+//	    axis: uint8
+//	    index: uint64
+//	    shares: List[CellProof, SSZ_MAX_WIDTH]
+//
+//	class SquareRoots(Container):
+//	    width: uint64
+//	    codec: ByteList[64]
+//	    row_roots: List[Bytes32, SSZ_MAX_WIDTH]
+//	    col_roots: List[Bytes32, SSZ_MAX_WIDTH]
+//	    data_root: Bytes32
+//
+// Axes are 0 for rows and 1 for columns. Roots must be 32 bytes long, as
+// produced by the default tree.
+const (
+	// SSZMaxWidth is the largest extended square width, and the largest
+	// number of shares of a bad encoding proof, that SSZ encodings hold.
+	SSZMaxWidth = 65536
+	// SSZMaxShareSize is the largest share size SSZ encodings hold.
+	SSZMaxShareSize = 1 << 24
+	// SSZMaxProofSet is the largest number of elements of an SSZ encoded
+	// proof set.
+	SSZMaxProofSet = 64
+)
+
+const sszRootSize = 32
+
+// sszZeroHashes[i] is the root of a tree of height i with zero leaves.
+var sszZeroHashes = func() [][]byte {
+	hashes := [][]byte{make([]byte, 32)}
+	for i := 1; i < 64; i++ {
+		hashes = append(hashes, sszHash(hashes[i-1], hashes[i-1]))
+	}
+	return hashes
+}()
+
+func sszHash(a []byte, b []byte) []byte {
+	h := sha256.New()
+	h.Write(a)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// sszMerkleize returns the root of chunks padded with zero chunks to the
+// next power of two of limit.
+func sszMerkleize(chunks [][]byte, limit int) []byte {
+	depth := 0
+	for 1<<uint(depth) < limit {
+		depth++
+	}
+	layer := chunks
+	for d := 0; d < depth; d++ {
+		if len(layer) == 0 {
+			return sszZeroHashes[depth]
+		}
+		if len(layer)%2 == 1 {
+			layer = append(layer[:len(layer):len(layer)], sszZeroHashes[d])
+		}
+		next := make([][]byte, len(layer)/2)
+		for i := range next {
+			next[i] = sszHash(layer[2*i], layer[2*i+1])
+		}
+		layer = next
+	}
+	if len(layer) == 0 {
+		return sszZeroHashes[0]
+	}
+	return layer[0]
+}
+
+func sszMixInLength(root []byte, n int) []byte {
+	length := make([]byte, 32)
+	binary.LittleEndian.PutUint64(length, uint64(n))
+	return sszHash(root, length)
+}
+
+func sszUintChunk(v uint64) []byte {
+	chunk := make([]byte, 32)
+	binary.LittleEndian.PutUint64(chunk, v)
+	return chunk
+}
+
+// sszByteListRoot returns the hash tree root of a ByteList[limit].
+func sszByteListRoot(data []byte, limit int) []byte {
+	var chunks [][]byte
+	for i := 0; i < len(data); i += 32 {
+		chunk := make([]byte, 32)
+		copy(chunk, data[i:])
+		chunks = append(chunks, chunk)
+	}
+	return sszMixInLength(sszMerkleize(chunks, (limit+31)/32), len(data))
+}
+
+// sszField is a field of a container being encoded.
+type sszField struct {
+	data     []byte
+	variable bool
+}
+
+// sszContainer encodes a container: fixed-size fields are inlined and
+// variable-size fields replaced by their offset and appended.
+func sszContainer(fields ...sszField) []byte {
+	fixedLen := 0
+	for _, f := range fields {
+		if f.variable {
+			fixedLen += 4
+		} else {
+			fixedLen += len(f.data)
+		}
+	}
+	b := make([]byte, 0, fixedLen)
+	offset := fixedLen
+	for _, f := range fields {
+		if f.variable {
+			b = appendSSZOffset(b, offset)
+			offset += len(f.data)
+		} else {
+			b = append(b, f.data...)
+		}
+	}
+	for _, f := range fields {
+		if f.variable {
+			b = append(b, f.data...)
+		}
+	}
+	return b
+}
+
+// sszVariableList encodes a list of variable-size elements.
+func sszVariableList(items [][]byte) []byte {
+	var b []byte
+	offset := 4 * len(items)
+	for _, item := range items {
+		b = appendSSZOffset(b, offset)
+		offset += len(item)
+	}
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+func appendSSZOffset(b []byte, offset int) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(offset))
+	return append(b, buf[:]...)
+}
+
+func sszUint64(v uint64) sszField {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return sszField{data: buf[:]}
+}
+
+func sszAxis(axis Axis) sszField {
+	return sszField{data: []byte{byte(axis)}}
+}
+
+// sszSplit splits an encoded container into its fields, given the sizes of
+// its fixed-size fields and -1 for variable-size ones.
+func sszSplit(data []byte, sizes ...int) ([][]byte, error) {
+	fixedLen := 0
+	for _, size := range sizes {
+		if size < 0 {
+			fixedLen += 4
+		} else {
+			fixedLen += size
+		}
+	}
+	if len(data) < fixedLen {
+		return nil, errMalformedEncoding
+	}
+
+	fields := make([][]byte, len(sizes))
+	var variable []int
+	var offsets []int
+	pos := 0
+	for i, size := range sizes {
+		if size < 0 {
+			variable = append(variable, i)
+			offsets = append(offsets, int(binary.LittleEndian.Uint32(data[pos:])))
+			pos += 4
+		} else {
+			fields[i] = data[pos : pos+size]
+			pos += size
+		}
+	}
+	if len(offsets) == 0 {
+		if len(data) != fixedLen {
+			return nil, errMalformedEncoding
+		}
+		return fields, nil
+	}
+	if offsets[0] != fixedLen {
+		return nil, errMalformedEncoding
+	}
+	offsets = append(offsets, len(data))
+	for j, i := range variable {
+		if offsets[j+1] < offsets[j] || offsets[j+1] > len(data) {
+			return nil, errMalformedEncoding
+		}
+		fields[i] = data[offsets[j]:offsets[j+1]]
+	}
+	return fields, nil
+}
+
+// sszSplitList splits an encoded list of at most max variable-size elements.
+func sszSplitList(data []byte, max int) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if len(data) < 4 {
+		return nil, errMalformedEncoding
+	}
+	first := int(binary.LittleEndian.Uint32(data))
+	if first%4 != 0 || first == 0 || first > len(data) || first/4 > max {
+		return nil, errMalformedEncoding
+	}
+	n := first / 4
+	offsets := make([]int, n+1)
+	for i := 0; i < n; i++ {
+		offsets[i] = int(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	offsets[n] = len(data)
+	items := make([][]byte, n)
+	for i := range items {
+		if offsets[i+1] < offsets[i] || offsets[i+1] > len(data) {
+			return nil, errMalformedEncoding
+		}
+		items[i] = data[offsets[i]:offsets[i+1]]
+	}
+	return items, nil
+}
+
+// sszSplitRoots splits an encoded list of at most max 32-byte roots.
+func sszSplitRoots(data []byte, max int) ([][]byte, error) {
+	if len(data)%sszRootSize != 0 || len(data)/sszRootSize > max {
+		return nil, errMalformedEncoding
+	}
+	roots := make([][]byte, len(data)/sszRootSize)
+	for i := range roots {
+		roots[i] = append([]byte(nil), data[i*sszRootSize:(i+1)*sszRootSize]...)
+	}
+	return roots, nil
+}
+
+func sszDecodeAxis(data []byte) (Axis, error) {
+	switch Axis(data[0]) {
+	case RowAxis, ColAxis:
+		return Axis(data[0]), nil
+	default:
+		return RowAxis, errMalformedEncoding
+	}
+}
+
+func sszCheckRoots(roots [][]byte) error {
+	if len(roots) > SSZMaxWidth {
+		return fmt.Errorf("%d roots exceed the SSZ limit of %d", len(roots), SSZMaxWidth)
+	}
+	for _, root := range roots {
+		if len(root) != sszRootSize {
+			return fmt.Errorf("SSZ encoding requires %d-byte roots, got %d bytes", sszRootSize, len(root))
+		}
+	}
+	return nil
+}
+
+func sszRootsRoot(roots [][]byte) []byte {
+	return sszMixInLength(sszMerkleize(roots, SSZMaxWidth), len(roots))
+}
+
+func sszProof(p Proof) ([]byte, error) {
+	if len(p.Set) > SSZMaxProofSet {
+		return nil, fmt.Errorf("proof set of %d elements exceeds the SSZ limit of %d", len(p.Set), SSZMaxProofSet)
+	}
+	for _, s := range p.Set {
+		if len(s) > SSZMaxShareSize {
+			return nil, fmt.Errorf("proof element of %d bytes exceeds the SSZ limit of %d", len(s), SSZMaxShareSize)
+		}
+	}
+	return sszContainer(
+		sszField{data: sszVariableList(p.Set), variable: true},
+		sszUint64(p.Index),
+		sszUint64(p.NumLeaves),
+	), nil
+}
+
+func sszDecodeProof(data []byte) (Proof, error) {
+	fields, err := sszSplit(data, -1, 8, 8)
+	if err != nil {
+		return Proof{}, err
+	}
+	items, err := sszSplitList(fields[0], SSZMaxProofSet)
+	if err != nil {
+		return Proof{}, err
+	}
+	set := make([][]byte, len(items))
+	for i, item := range items {
+		if len(item) > SSZMaxShareSize {
+			return Proof{}, errMalformedEncoding
+		}
+		set[i] = append([]byte(nil), item...)
+	}
+	return Proof{
+		Set:       set,
+		Index:     binary.LittleEndian.Uint64(fields[1]),
+		NumLeaves: binary.LittleEndian.Uint64(fields[2]),
+	}, nil
+}
+
+func sszProofRoot(p Proof) []byte {
+	roots := make([][]byte, len(p.Set))
+	for i, s := range p.Set {
+		roots[i] = sszByteListRoot(s, SSZMaxShareSize)
+	}
+	return sszMerkleize([][]byte{
+		sszMixInLength(sszMerkleize(roots, SSZMaxProofSet), len(roots)),
+		sszUintChunk(p.Index),
+		sszUintChunk(p.NumLeaves),
+	}, 3)
+}
+
+func sszCellProof(p *CellProof) ([]byte, error) {
+	proof, err := sszProof(p.Proof)
+	if err != nil {
+		return nil, err
+	}
+	coord := sszContainer(sszUint64(uint64(p.Coord.Row)), sszUint64(uint64(p.Coord.Col)))
+	return sszContainer(
+		sszField{data: coord},
+		sszAxis(p.Axis),
+		sszField{data: proof, variable: true},
+	), nil
+}
+
+func sszDecodeCellProof(data []byte) (CellProof, error) {
+	fields, err := sszSplit(data, 16, 1, -1)
+	if err != nil {
+		return CellProof{}, err
+	}
+	axis, err := sszDecodeAxis(fields[1])
+	if err != nil {
+		return CellProof{}, err
+	}
+	proof, err := sszDecodeProof(fields[2])
+	if err != nil {
+		return CellProof{}, err
+	}
+	return CellProof{
+		Coord: Coordinate{
+			Row: uint(binary.LittleEndian.Uint64(fields[0])),
+			Col: uint(binary.LittleEndian.Uint64(fields[0][8:])),
+		},
+		Axis:  axis,
+		Proof: proof,
+	}, nil
+}
+
+func sszCellProofRoot(p *CellProof) []byte {
+	coord := sszMerkleize([][]byte{sszUintChunk(uint64(p.Coord.Row)), sszUintChunk(uint64(p.Coord.Col))}, 2)
+	return sszMerkleize([][]byte{coord, sszUintChunk(uint64(p.Axis)), sszProofRoot(p.Proof)}, 3)
+}
+
+// MarshalSSZ encodes the proof in SSZ.
+func (p *CellProof) MarshalSSZ() ([]byte, error) {
+	return sszCellProof(p)
+}
+
+// UnmarshalSSZ decodes a proof encoded with MarshalSSZ.
+func (p *CellProof) UnmarshalSSZ(data []byte) error {
+	proof, err := sszDecodeCellProof(data)
+	if err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the proof.
+func (p *CellProof) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	copy(root[:], sszCellProofRoot(p))
+	return root, nil
+}
+
+// MarshalSSZ encodes the proof in SSZ.
+func (p *AxisRootProof) MarshalSSZ() ([]byte, error) {
+	if err := sszCheckRoots([][]byte{p.Root}); err != nil {
+		return nil, err
+	}
+	proof, err := sszProof(p.Proof)
+	if err != nil {
+		return nil, err
+	}
+	return sszContainer(
+		sszAxis(p.Axis),
+		sszUint64(uint64(p.Index)),
+		sszField{data: p.Root},
+		sszField{data: proof, variable: true},
+	), nil
+}
+
+// UnmarshalSSZ decodes a proof encoded with MarshalSSZ.
+func (p *AxisRootProof) UnmarshalSSZ(data []byte) error {
+	fields, err := sszSplit(data, 1, 8, sszRootSize, -1)
+	if err != nil {
+		return err
+	}
+	axis, err := sszDecodeAxis(fields[0])
+	if err != nil {
+		return err
+	}
+	proof, err := sszDecodeProof(fields[3])
+	if err != nil {
+		return err
+	}
+	*p = AxisRootProof{
+		Axis:  axis,
+		Index: uint(binary.LittleEndian.Uint64(fields[1])),
+		Root:  append([]byte(nil), fields[2]...),
+		Proof: proof,
+	}
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the proof.
+func (p *AxisRootProof) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	if err := sszCheckRoots([][]byte{p.Root}); err != nil {
+		return root, err
+	}
+	copy(root[:], sszMerkleize([][]byte{
+		sszUintChunk(uint64(p.Axis)),
+		sszUintChunk(uint64(p.Index)),
+		p.Root,
+		sszProofRoot(p.Proof),
+	}, 4))
+	return root, nil
+}
+
+// MarshalSSZ encodes the proof in SSZ.
+func (p *BadEncodingProof) MarshalSSZ() ([]byte, error) {
+	if len(p.Shares) > SSZMaxWidth {
+		return nil, fmt.Errorf("%d shares exceed the SSZ limit of %d", len(p.Shares), SSZMaxWidth)
+	}
+	shares := make([][]byte, len(p.Shares))
+	for i := range p.Shares {
+		var err error
+		if shares[i], err = sszCellProof(&p.Shares[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sszContainer(
+		sszAxis(p.Axis),
+		sszUint64(uint64(p.Index)),
+		sszField{data: sszVariableList(shares), variable: true},
+	), nil
+}
+
+// UnmarshalSSZ decodes a proof encoded with MarshalSSZ.
+func (p *BadEncodingProof) UnmarshalSSZ(data []byte) error {
+	fields, err := sszSplit(data, 1, 8, -1)
+	if err != nil {
+		return err
+	}
+	axis, err := sszDecodeAxis(fields[0])
+	if err != nil {
+		return err
+	}
+	items, err := sszSplitList(fields[2], SSZMaxWidth)
+	if err != nil {
+		return err
+	}
+	proof := BadEncodingProof{Axis: axis, Index: uint(binary.LittleEndian.Uint64(fields[1]))}
+	proof.Shares = make([]CellProof, len(items))
+	for i, item := range items {
+		if proof.Shares[i], err = sszDecodeCellProof(item); err != nil {
+			return err
+		}
+	}
+	*p = proof
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the proof.
+func (p *BadEncodingProof) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	if len(p.Shares) > SSZMaxWidth {
+		return root, errors.New("too many shares for SSZ")
+	}
+	shares := make([][]byte, len(p.Shares))
+	for i := range p.Shares {
+		shares[i] = sszCellProofRoot(&p.Shares[i])
+	}
+	copy(root[:], sszMerkleize([][]byte{
+		sszUintChunk(uint64(p.Axis)),
+		sszUintChunk(uint64(p.Index)),
+		sszMixInLength(sszMerkleize(shares, SSZMaxWidth), len(shares)),
+	}, 3))
+	return root, nil
+}
+
+// sszCheck checks that the roots fit the SSZ schema.
+func (r *SquareRoots) sszCheck() error {
+	if len(r.Codec) > maxCodecNameLength {
+		return fmt.Errorf("codec name of %d bytes exceeds the SSZ limit of %d", len(r.Codec), maxCodecNameLength)
+	}
+	for _, roots := range [][][]byte{r.RowRoots, r.ColRoots, {r.DataRoot}} {
+		if err := sszCheckRoots(roots); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalSSZ encodes the roots in SSZ.
+func (r *SquareRoots) MarshalSSZ() ([]byte, error) {
+	if err := r.sszCheck(); err != nil {
+		return nil, err
+	}
+	return sszContainer(
+		sszUint64(uint64(r.Width)),
+		sszField{data: []byte(r.Codec), variable: true},
+		sszField{data: flattenChunks(r.RowRoots), variable: true},
+		sszField{data: flattenChunks(r.ColRoots), variable: true},
+		sszField{data: r.DataRoot},
+	), nil
+}
+
+// UnmarshalSSZ decodes roots encoded with MarshalSSZ.
+func (r *SquareRoots) UnmarshalSSZ(data []byte) error {
+	fields, err := sszSplit(data, 8, -1, -1, -1, sszRootSize)
+	if err != nil {
+		return err
+	}
+	if len(fields[1]) > maxCodecNameLength {
+		return errMalformedEncoding
+	}
+	rowRoots, err := sszSplitRoots(fields[2], SSZMaxWidth)
+	if err != nil {
+		return err
+	}
+	colRoots, err := sszSplitRoots(fields[3], SSZMaxWidth)
+	if err != nil {
+		return err
+	}
+	*r = SquareRoots{
+		Width:    uint(binary.LittleEndian.Uint64(fields[0])),
+		Codec:    string(fields[1]),
+		RowRoots: rowRoots,
+		ColRoots: colRoots,
+		DataRoot: append([]byte(nil), fields[4]...),
+	}
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the roots.
+func (r *SquareRoots) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	if err := r.sszCheck(); err != nil {
+		return root, err
+	}
+	copy(root[:], sszMerkleize([][]byte{
+		sszUintChunk(uint64(r.Width)),
+		sszByteListRoot([]byte(r.Codec), maxCodecNameLength),
+		sszRootsRoot(r.RowRoots),
+		sszRootsRoot(r.ColRoots),
+		r.DataRoot,
+	}, 5))
+	return root, nil
+}
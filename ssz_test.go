@@ -0,0 +1,108 @@
+package rsmt2d
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sszCodec interface {
+	MarshalSSZ() ([]byte, error)
+	UnmarshalSSZ(data []byte) error
+	HashTreeRoot() ([32]byte, error)
+}
+
+func TestSSZRoundTrip(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	cellProof, err := eds.ProveCellOnAxis(Coordinate{Row: 2, Col: 5}, ColAxis)
+	assert.NoError(t, err)
+	rootProof, err := eds.ProveAxisRoot(ColAxis, 3)
+	assert.NoError(t, err)
+	bep, err := NewBadEncodingProof(eds, RowAxis, 1, []uint{0, 2, 4, 6})
+	assert.NoError(t, err)
+	roots, err := eds.Roots()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		value   sszCodec
+		decoded sszCodec
+	}{
+		{"cell", &cellProof, &CellProof{}},
+		{"axis root", &rootProof, &AxisRootProof{}},
+		{"bad encoding", bep, &BadEncodingProof{}},
+		{"roots", roots, &SquareRoots{}},
+	}
+	for _, tt := range tests {
+		data, err := tt.value.MarshalSSZ()
+		assert.NoError(t, err)
+		assert.NoError(t, tt.decoded.UnmarshalSSZ(data), tt.name)
+		assert.Equal(t, tt.value, tt.decoded, tt.name)
+		assert.Error(t, tt.decoded.UnmarshalSSZ(data[:3]), tt.name)
+
+		want, err := tt.value.HashTreeRoot()
+		assert.NoError(t, err)
+		got, err := tt.decoded.HashTreeRoot()
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, tt.name)
+	}
+
+	short := *roots
+	short.DataRoot = short.DataRoot[:31]
+	_, err = short.MarshalSSZ()
+	assert.Error(t, err)
+}
+
+func TestSSZHashTreeRoot(t *testing.T) {
+	chunk := func(b ...byte) []byte {
+		c := make([]byte, 32)
+		copy(c, b)
+		return c
+	}
+	hash := func(a []byte, b []byte) []byte {
+		sum := sha256.Sum256(append(append([]byte(nil), a...), b...))
+		return sum[:]
+	}
+
+	// A proof set of a single one-byte share, in a tree of 2^19 chunks.
+	share := chunk(0xab)
+	for i := 0; i < 19; i++ {
+		share = hash(share, sszZeroHashes[i])
+	}
+	share = hash(share, chunk(1))
+	set := hash(share, sszZeroHashes[0])
+	for i := 1; i < 6; i++ {
+		set = hash(set, sszZeroHashes[i])
+	}
+	set = hash(set, chunk(1))
+	proof := hash(hash(set, chunk(1)), hash(chunk(4), sszZeroHashes[0]))
+
+	coord := hash(chunk(1), chunk(0x2c, 0x01))
+	want := hash(hash(coord, chunk(1)), hash(proof, sszZeroHashes[0]))
+
+	p := CellProof{
+		Coord: Coordinate{Row: 1, Col: 300},
+		Axis:  ColAxis,
+		Proof: Proof{Set: [][]byte{{0xab}}, Index: 1, NumLeaves: 4},
+	}
+	got, err := p.HashTreeRoot()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got[:])
+
+	data, err := p.MarshalSSZ()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		1, 0, 0, 0, 0, 0, 0, 0, 0x2c, 1, 0, 0, 0, 0, 0, 0, // coord
+		1,           // axis
+		21, 0, 0, 0, // offset of proof
+		20, 0, 0, 0, // offset of set
+		1, 0, 0, 0, 0, 0, 0, 0, // index
+		4, 0, 0, 0, 0, 0, 0, 0, // num_leaves
+		4, 0, 0, 0, // offset of share
+		0xab,
+	}, data)
+}
@@ -0,0 +1,181 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// stripedCodec encodes and decodes shares in stripes of a fixed number of
+// bytes. Reed-Solomon codes operate on each byte position (or, for Leopard,
+// each 64-byte block) independently, so the result is identical to coding
+// whole shares, but the codec only works on one stripe of every share at a
+// time.
+type stripedCodec struct {
+	codec      Codec
+	stripeSize int
+}
+
+var _ BufferedCodec = &stripedCodec{}
+var _ ChunkSizeLimits = &stripedCodec{}
+
+// NewStripedCodec wraps codec so that shares are processed in stripes of
+// stripeSize bytes, bounding the working set of the codec when shares are
+// megabytes in size. Leopard codecs require stripeSize to be a multiple of
+// 64 bytes.
+func NewStripedCodec(codec Codec, stripeSize int) (Codec, error) {
+	if stripeSize <= 0 {
+		return nil, errors.New("stripe size must be positive")
+	}
+	if err := checkChunkSize(codec, uint(stripeSize)); err != nil {
+		return nil, fmt.Errorf("stripe size: %w", err)
+	}
+	return &stripedCodec{codec: codec, stripeSize: stripeSize}, nil
+}
+
+// MaxChunkSize returns 0, since chunks of any size are split into stripes.
+func (c *stripedCodec) MaxChunkSize() int {
+	return 0
+}
+
+// ChunkSizeMultiple returns the chunk size multiple of the wrapped codec.
+func (c *stripedCodec) ChunkSizeMultiple() int {
+	if limits, ok := c.codec.(ChunkSizeLimits); ok {
+		return limits.ChunkSizeMultiple()
+	}
+	return 1
+}
+
+// Unwrap returns the wrapped codec.
+func (c *stripedCodec) Unwrap() Codec {
+	return c.codec
+}
+
+func (c *stripedCodec) Encode(data [][]byte) ([][]byte, error) {
+	dst := make([][]byte, len(data))
+	for i := range dst {
+		dst[i] = make([]byte, len(data[0]))
+	}
+	if err := c.EncodeInto(dst, data); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// EncodeInto writes the parity shares of data into dst, one stripe at a time.
+func (c *stripedCodec) EncodeInto(dst [][]byte, data [][]byte) error {
+	if len(dst) != len(data) {
+		return fmt.Errorf("destination holds %d chunks, expected %d", len(dst), len(data))
+	}
+	buffered, isBuffered := c.codec.(BufferedCodec)
+	return c.forEachStripe(len(data[0]), func(from, to int) error {
+		dataStripe := stripe(data, from, to)
+		dstStripe := stripe(dst, from, to)
+		if isBuffered {
+			return buffered.EncodeInto(dstStripe, dataStripe)
+		}
+		parity, err := c.codec.Encode(dataStripe)
+		if err != nil {
+			return err
+		}
+		for i, p := range parity[len(parity)-len(dst):] {
+			copy(dstStripe[i], p)
+		}
+		return nil
+	})
+}
+
+func (c *stripedCodec) Decode(data [][]byte) ([][]byte, error) {
+	var chunkSize int
+	for _, d := range data {
+		if d != nil {
+			chunkSize = len(d)
+			break
+		}
+	}
+	dst := make([][]byte, len(data)/2)
+	for i := range dst {
+		dst[i] = make([]byte, chunkSize)
+	}
+	if err := c.DecodeInto(dst, data); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// DecodeInto rebuilds the original shares into dst, one stripe at a time.
+func (c *stripedCodec) DecodeInto(dst [][]byte, data [][]byte) error {
+	if len(dst) != len(data)/2 {
+		return fmt.Errorf("destination holds %d chunks, expected %d", len(dst), len(data)/2)
+	}
+	if len(dst) == 0 {
+		return errors.New("no shares to decode")
+	}
+	present := 0
+	for j, d := range data {
+		if d == nil {
+			continue
+		}
+		if len(d) != len(dst[0]) {
+			return fmt.Errorf("%w: share %d has size %d, expected %d", ErrShardSizeMismatch, j, len(d), len(dst[0]))
+		}
+		present++
+	}
+	if present < len(dst) {
+		return ErrTooFewShards
+	}
+	buffered, isBuffered := c.codec.(BufferedCodec)
+	return c.forEachStripe(len(dst[0]), func(from, to int) error {
+		dataStripe := stripe(data, from, to)
+		dstStripe := stripe(dst, from, to)
+		if isBuffered {
+			return buffered.DecodeInto(dstStripe, dataStripe)
+		}
+		original, err := c.codec.Decode(dataStripe)
+		if err != nil {
+			return err
+		}
+		for i := range dstStripe {
+			copy(dstStripe[i], original[i])
+		}
+		return nil
+	})
+}
+
+// HealthCheck checks the wrapped codec, then the striping.
+func (c *stripedCodec) HealthCheck() error {
+	if err := c.codec.HealthCheck(); err != nil {
+		return err
+	}
+	return healthCheck(c)
+}
+
+func (c *stripedCodec) maxChunks() int {
+	return c.codec.maxChunks()
+}
+
+// forEachStripe calls f with the bounds of every stripe of a share of the
+// given size.
+func (c *stripedCodec) forEachStripe(size int, f func(from, to int) error) error {
+	for from := 0; from < size; from += c.stripeSize {
+		to := from + c.stripeSize
+		if to > size {
+			to = size
+		}
+		if err := f(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripe returns the [from, to) byte range of every share, keeping missing
+// shares nil.
+func stripe(shares [][]byte, from, to int) [][]byte {
+	s := make([][]byte, len(shares))
+	for i, share := range shares {
+		if share != nil {
+			s[i] = share[from:to:to]
+		}
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package rsmt2d
+
+import "fmt"
+
+// SquareView is a read-only view of a rectangular region of a square.
+type SquareView struct {
+	origin Coordinate
+	rows   [][][]byte
+	cols   uint
+}
+
+// SubSquare returns a view of the cells (r, c) of the square with r0 <= r < r1
+// and c0 <= c < c1. Unless deepCopy is set, the view shares its chunks with
+// the square, like Row and Col, and they must not be modified. Cells not
+// recovered yet by repair are nil.
+func (eds *ExtendedDataSquare) SubSquare(r0, c0, r1, c1 uint, deepCopy bool) (*SquareView, error) {
+	if r0 >= r1 || c0 >= c1 || r1 > eds.width || c1 > eds.width {
+		return nil, fmt.Errorf("region [%d, %d) x [%d, %d) is empty or out of range for width %d", r0, r1, c0, c1, eds.width)
+	}
+	rows := make([][][]byte, r1-r0)
+	for i := range rows {
+		rows[i] = append([][]byte(nil), eds.rowSlice(r0+uint(i), c0, c1-c0)...)
+		eds.hideMissing(rows[i], RowAxis, r0+uint(i), c0)
+		if deepCopy {
+			rows[i] = cloneChunks(rows[i])
+		}
+	}
+	return &SquareView{origin: Coordinate{Row: r0, Col: c0}, rows: rows, cols: c1 - c0}, nil
+}
+
+// Origin returns the coordinates in the square of the top-left cell of the
+// view.
+func (v *SquareView) Origin() Coordinate {
+	return v.origin
+}
+
+// Rows returns the number of rows of the view.
+func (v *SquareView) Rows() uint {
+	return uint(len(v.rows))
+}
+
+// Cols returns the number of columns of the view.
+func (v *SquareView) Cols() uint {
+	return v.cols
+}
+
+// Cell returns the chunk at row r and column c of the view, or nil if out of
+// range. Coordinates are relative to the origin of the view.
+func (v *SquareView) Cell(r uint, c uint) []byte {
+	if r >= v.Rows() || c >= v.cols {
+		return nil
+	}
+	return v.rows[r][c]
+}
+
+// Row returns row r of the view, or nil if out of range.
+func (v *SquareView) Row(r uint) [][]byte {
+	if r >= v.Rows() {
+		return nil
+	}
+	return append([][]byte(nil), v.rows[r]...)
+}
+
+// Flattened returns the chunks of the view in row-major order.
+func (v *SquareView) Flattened() [][]byte {
+	flattened := make([][]byte, 0, v.Rows()*v.cols)
+	for _, row := range v.rows {
+		flattened = append(flattened, row...)
+	}
+	return flattened
+}
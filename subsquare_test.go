@@ -0,0 +1,42 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubSquare(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	view, err := eds.SubSquare(1, 2, 3, 4, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Coordinate{Row: 1, Col: 2}, view.Origin())
+	assert.Equal(t, uint(2), view.Rows())
+	assert.Equal(t, uint(2), view.Cols())
+	assert.Equal(t, eds.getCell(2, 3), view.Cell(1, 1))
+	assert.Nil(t, view.Cell(2, 0))
+	assert.Equal(t, eds.Row(1)[2:], view.Row(0))
+	assert.Equal(t, [][]byte{eds.getCell(1, 2), eds.getCell(1, 3), eds.getCell(2, 2), eds.getCell(2, 3)}, view.Flattened())
+
+	// A deep copy does not alias the chunks of the square.
+	copied, err := eds.SubSquare(0, 0, 1, 1, true)
+	if assert.NoError(t, err) {
+		copied.Cell(0, 0)[0]++
+		assert.NotEqual(t, eds.getCell(0, 0), copied.Cell(0, 0))
+	}
+	shared, err := eds.SubSquare(0, 0, 1, 1, false)
+	if assert.NoError(t, err) {
+		assert.Same(t, &eds.row(0)[0][0], &shared.Cell(0, 0)[0])
+	}
+
+	for _, region := range [][4]uint{{0, 0, 0, 1}, {1, 1, 1, 2}, {0, 0, 5, 1}, {0, 3, 1, 5}} {
+		_, err := eds.SubSquare(region[0], region[1], region[2], region[3], false)
+		assert.Error(t, err, "%v", region)
+	}
+}
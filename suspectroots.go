@@ -0,0 +1,82 @@
+package rsmt2d
+
+import "bytes"
+
+// SuspectRoot is a row or column root that did not match the shares provided
+// for the axis, and was attributed to a malformed header rather than to the
+// shares by WithSuspectRoots.
+type SuspectRoot struct {
+	Axis     Axis
+	Index    uint
+	Expected []byte // Root given to repair
+	Computed []byte // Root of the provided shares
+}
+
+// WithSuspectRoots makes repair tolerate up to max complete rows or columns
+// whose provided shares do not match their root, blaming the root instead of
+// the shares. A row is only tolerated if every column root matches the
+// shares of its column, as the shares of the row are then committed to by
+// the column roots, and conversely for columns; if both rows and columns
+// mismatch, or more than max do, repair fails as without this option. The
+// tolerated roots are appended to suspects, and the repaired square has the
+// roots of its shares. Suspect roots are resolved before error detection
+// with WithErrorDetection. Incomplete axes still need a matching root to be
+// rebuilt.
+func WithSuspectRoots(max int, suspects *[]SuspectRoot) RepairOption {
+	return func(cfg *repairConfig) {
+		cfg.maxSuspectRoots = max
+		cfg.suspectRoots = suspects
+	}
+}
+
+// resolveSuspectRoots replaces the roots of complete axes not matching their
+// shares with the computed ones, if the orthogonal roots vouch for the
+// shares. rowRoots and colRoots must be private copies.
+func (eds *ExtendedDataSquare) resolveSuspectRoots(
+	rowRoots [][]byte,
+	colRoots [][]byte,
+	bitMask bitMatrix,
+	cfg *repairConfig,
+) {
+	var suspects [2][]SuspectRoot
+	for _, axis := range []Axis{RowAxis, ColAxis} {
+		roots := rowRoots
+		if axis == ColAxis {
+			roots = colRoots
+		}
+		for i := uint(0); i < eds.width; i++ {
+			if cfg.verifiedAxes[axis][i] {
+				continue
+			}
+			var computed []byte
+			if axis == RowAxis && bitMask.RowIsOne(int(i)) {
+				computed = eds.getRowRoot(i)
+			} else if axis == ColAxis && bitMask.ColIsOne(int(i)) {
+				computed = eds.getColRoot(i)
+			}
+			if computed != nil && !bytes.Equal(roots[i], computed) {
+				suspects[axis] = append(suspects[axis], SuspectRoot{Axis: axis, Index: i, Expected: roots[i], Computed: computed})
+			}
+		}
+	}
+
+	// Shares of a suspect row are only committed to by the column roots if
+	// none of them is suspect, and vice versa.
+	if len(suspects[RowAxis]) > 0 && len(suspects[ColAxis]) > 0 {
+		return
+	}
+	all := append(suspects[RowAxis], suspects[ColAxis]...)
+	if len(all) > cfg.maxSuspectRoots {
+		return
+	}
+	for _, s := range all {
+		if s.Axis == RowAxis {
+			rowRoots[s.Index] = s.Computed
+		} else {
+			colRoots[s.Index] = s.Computed
+		}
+	}
+	if cfg.suspectRoots != nil {
+		*cfg.suspectRoots = append(*cfg.suspectRoots, all...)
+	}
+}
@@ -0,0 +1,48 @@
+package rsmt2d
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairSuspectRoots(t *testing.T) {
+	codec := NewRSGF8Codec()
+	original, err := ComputeExtendedDataSquare(genRandDS(2), codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots := append([][]byte(nil), original.getRowRoots()...)
+	colRoots := append([][]byte(nil), original.getColRoots()...)
+	badRoot := colRoots[0]
+	rowRoots[1] = badRoot
+
+	// Only row 0 is incomplete; every column but 0 is complete.
+	data := original.flattened()
+	data[0] = nil
+
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree)
+	var mismatch *ErrRootMismatch
+	assert.True(t, errors.As(err, &mismatch), "expected ErrRootMismatch, got %v", err)
+
+	var suspects []SuspectRoot
+	eds, err := RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree, WithSuspectRoots(1, &suspects))
+	if assert.NoError(t, err) {
+		assert.Equal(t, original.flattened(), eds.flattened())
+		assert.Equal(t, []SuspectRoot{{Axis: RowAxis, Index: 1, Expected: badRoot, Computed: original.getRowRoot(1)}}, suspects)
+	}
+
+	// More suspect roots than tolerated.
+	rowRoots[2] = badRoot
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree, WithSuspectRoots(1, nil))
+	assert.True(t, errors.As(err, &mismatch), "expected ErrRootMismatch, got %v", err)
+
+	// Mismatching rows and columns cannot vouch for each other.
+	rowRoots[2] = original.getRowRoot(2)
+	colRoots[3] = badRoot
+	suspects = nil
+	_, err = RepairExtendedDataSquare(rowRoots, colRoots, data, codec, NewDefaultTree, WithSuspectRoots(2, &suspects))
+	assert.True(t, errors.As(err, &mismatch), "expected ErrRootMismatch, got %v", err)
+	assert.Empty(t, suspects)
+}
@@ -2,6 +2,9 @@ package rsmt2d
 
 import (
 	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 
 	"github.com/lazyledger/merkletree"
 )
@@ -15,17 +18,102 @@ type SquareIndex struct {
 	Axis, Cell uint
 }
 
+// LeafOrder names the canonical order in which the shares of a row or column
+// are pushed into its tree, which implementations computing the same roots
+// must follow:
+//
+//   - the tree of row i holds cells (i, 0), (i, 1), ..., (i, w-1), and
+//   - the tree of column j holds cells (0, j), (1, j), ..., (w-1, j),
+//
+// where w is the width of the extended square. The share at position p of
+// the row or column is pushed p-th, with SquareIndex{Axis: i or j, Cell: p},
+// so for both axes the original shares come first, followed by the parity
+// shares. Every tree of the package, whether computing roots, proofs or
+// cached nodes, follows this order.
+const LeafOrder = "ascending-position"
+
+// CanonicalLeafOrder returns the cells of the given row or column of the
+// square in the order they are pushed into its tree, see LeafOrder.
+func (eds *ExtendedDataSquare) CanonicalLeafOrder(axis Axis, index uint) ([]Coordinate, error) {
+	if index >= eds.width {
+		return nil, fmt.Errorf("%v %d out of range for width %d", axis, index, eds.width)
+	}
+	return axisCoordinates(axis, index, eds.width), nil
+}
+
 // Tree wraps Merkle tree implementations to work with rsmt2d
 type Tree interface {
 	Push(data []byte, idx SquareIndex)
 	Root() []byte
 }
 
-var _ Tree = &DefaultTree{}
+// ErrTreeNotProvable is returned when proofs are requested from a tree that
+// does not implement ProvableTree.
+var ErrTreeNotProvable = errors.New("tree does not support inclusion proofs")
+
+// ErrTreeNotCaching is returned when nodes are requested from or restored into
+// a tree that does not implement NodeCachingTree.
+var ErrTreeNotCaching = errors.New("tree does not support node caching")
+
+// Proof is a Merkle inclusion proof of a single share in a row or column.
+type Proof struct {
+	Set       [][]byte `json:"set"`        // Proof set; the first element is the share itself
+	Index     uint64   `json:"index"`      // Index of the share within the row or column
+	NumLeaves uint64   `json:"num_leaves"` // Number of shares in the row or column
+}
+
+// ProvableTree is implemented by trees that can produce and verify inclusion
+// proofs of their leaves.
+type ProvableTree interface {
+	Tree
+	Prove(idx uint) (Proof, error)
+	VerifyProof(root []byte, proof Proof) bool
+}
+
+// StreamingTree is implemented by trees that can hash a leaf incrementally
+// from a reader instead of requiring the whole leaf as a byte slice. The roots
+// of a square repaired with WithMemoryLimit are computed by streaming the
+// shares of spilled quadrants from disk into such trees, rather than loading
+// the quadrants back into memory.
+type StreamingTree interface {
+	Tree
+	PushReader(r io.Reader, idx SquareIndex) error
+}
+
+// NodeCachingTree is implemented by trees whose internal nodes can be
+// exported and later restored, so that a tree does not need to be rebuilt
+// from its leaves to generate proofs.
+type NodeCachingTree interface {
+	ProvableTree
+	// Nodes returns the hashes of the nodes of the tree, level by level
+	// starting with the leaf hashes. The result must not be modified.
+	Nodes() [][][]byte
+	// FromNodes restores the tree from nodes returned by Nodes and its
+	// leaves. Leaves may be nil, or hold nil entries for leaves whose data
+	// is not available; such leaves cannot be proven.
+	FromNodes(nodes [][][]byte, leaves [][]byte) error
+}
+
+var _ ProvableTree = &DefaultTree{}
+var _ NodeCachingTree = &DefaultTree{}
+var _ StreamingTree = &DefaultTree{}
+
+// leafHashPrefix and nodeHashPrefix are prepended to leaves and inner nodes
+// by the default tree hasher.
+var (
+	leafHashPrefix = []byte{0}
+	nodeHashPrefix = []byte{1}
+)
 
 type DefaultTree struct {
 	*merkletree.Tree
 	leaves [][]byte
+	// leafHashes holds the hashes of leaves pushed with PushReader, keyed by
+	// leaf index. Their entries in leaves are nil.
+	leafHashes map[int][]byte
+	// levels caches the hashes of all complete, aligned subtrees, indexed by
+	// height, so that proofs of several leaves share the hashing work.
+	levels [][][]byte
 	root   []byte
 }
 
@@ -39,14 +127,189 @@ func NewDefaultTree() Tree {
 func (d *DefaultTree) Push(data []byte, _idx SquareIndex) {
 	// ignore the idx, as this implementation doesn't need that info
 	d.leaves = append(d.leaves, data)
+	d.levels = nil
+}
+
+// PushReader hashes a leaf read from r without retaining it. Leaves pushed
+// this way cannot be proven.
+func (d *DefaultTree) PushReader(r io.Reader, _idx SquareIndex) error {
+	h := sha256.New()
+	h.Write(leafHashPrefix)
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	if d.leafHashes == nil {
+		d.leafHashes = make(map[int][]byte)
+	}
+	d.leafHashes[len(d.leaves)] = h.Sum(nil)
+	d.leaves = append(d.leaves, nil)
+	d.levels = nil
+	return nil
 }
 
 func (d *DefaultTree) Root() []byte {
+	if d.root == nil && d.levels != nil {
+		d.root = d.rootFromLevels()
+	}
 	if d.root == nil {
-		for _, l := range d.leaves {
-			d.Tree.Push(l)
-		}
+		// Pushing leaf hashes into a tree without a proof index cannot fail.
+		_ = d.pushLeaves(d.Tree)
 		d.root = d.Tree.Root()
 	}
 	return d.root
 }
+
+// rootFromLevels computes the root from the cached subtree hashes, pushing
+// the largest aligned subtrees that fit from left to right.
+func (d *DefaultTree) rootFromLevels() []byte {
+	tree := merkletree.New(sha256.New())
+	n := uint(len(d.levels[0]))
+	for i := uint(0); i < n; {
+		height := 0
+		for height+1 < len(d.levels) {
+			size := uint(1) << uint(height+1)
+			if i%size != 0 || i+size > n {
+				break
+			}
+			height++
+		}
+		// Subtrees are pushed in non-increasing size, which cannot fail.
+		_ = tree.PushSubTree(height, d.levels[height][i>>uint(height)])
+		i += 1 << uint(height)
+	}
+	return tree.Root()
+}
+
+// Nodes returns the hashes of all complete, aligned subtrees of the tree,
+// indexed by height.
+func (d *DefaultTree) Nodes() [][][]byte {
+	return d.subtreeHashes()
+}
+
+// FromNodes restores the tree from nodes returned by Nodes. Leaves whose
+// data is nil cannot be proven.
+func (d *DefaultTree) FromNodes(nodes [][][]byte, leaves [][]byte) error {
+	if len(nodes) == 0 {
+		return errors.New("no tree nodes")
+	}
+	n := len(nodes[0])
+	for height := 1; height < len(nodes); height++ {
+		if len(nodes[height]) != n>>uint(height) {
+			return fmt.Errorf("level %d has %d nodes, expected %d", height, len(nodes[height]), n>>uint(height))
+		}
+	}
+	if n > 1 && len(nodes[len(nodes)-1]) > 1 {
+		return errors.New("tree nodes are missing levels")
+	}
+	if leaves == nil {
+		leaves = make([][]byte, n)
+	} else if len(leaves) != n {
+		return fmt.Errorf("got %d leaves for %d leaf hashes", len(leaves), n)
+	}
+
+	d.Tree = merkletree.New(sha256.New())
+	d.leaves = leaves
+	d.leafHashes = make(map[int][]byte)
+	for i, l := range leaves {
+		if l == nil {
+			d.leafHashes[i] = nodes[0][i]
+		}
+	}
+	d.levels = nodes
+	d.root = nil
+	return nil
+}
+
+// pushLeaves pushes all leaves into tree, using the precomputed hashes of
+// streamed leaves.
+func (d *DefaultTree) pushLeaves(tree *merkletree.Tree) error {
+	for i, l := range d.leaves {
+		if h, ok := d.leafHashes[i]; ok {
+			if err := tree.PushSubTree(0, h); err != nil {
+				return err
+			}
+			continue
+		}
+		tree.Push(l)
+	}
+	return nil
+}
+
+// Prove returns an inclusion proof of the leaf at idx. Subtree hashes are
+// cached, so proving several leaves of the same tree hashes each leaf once.
+func (d *DefaultTree) Prove(idx uint) (Proof, error) {
+	if idx >= uint(len(d.leaves)) {
+		return Proof{}, fmt.Errorf("leaf index %d out of range for %d leaves", idx, len(d.leaves))
+	}
+	if _, ok := d.leafHashes[int(idx)]; ok {
+		return Proof{}, fmt.Errorf("data of leaf %d is not available and cannot be proven", idx)
+	}
+
+	tree := merkletree.New(sha256.New())
+	if err := tree.SetIndex(uint64(idx)); err != nil {
+		return Proof{}, err
+	}
+	levels := d.subtreeHashes()
+	for i := uint(0); i < uint(len(d.leaves)); {
+		if i == idx {
+			tree.Push(d.leaves[i])
+			i++
+			continue
+		}
+		// Push the largest cached subtree starting at i that does not
+		// contain the proven leaf.
+		height := 0
+		for height+1 < len(levels) {
+			size := uint(1) << uint(height+1)
+			if i%size != 0 || i+size > uint(len(d.leaves)) || (i <= idx && idx < i+size) {
+				break
+			}
+			height++
+		}
+		if err := tree.PushSubTree(height, levels[height][i>>uint(height)]); err != nil {
+			return Proof{}, err
+		}
+		i += 1 << uint(height)
+	}
+	_, set, index, numLeaves := tree.Prove()
+	return Proof{Set: set, Index: index, NumLeaves: numLeaves}, nil
+}
+
+// subtreeHashes returns the hashes of all complete, aligned subtrees of the
+// tree, computing them on first use.
+func (d *DefaultTree) subtreeHashes() [][][]byte {
+	if d.levels != nil {
+		return d.levels
+	}
+
+	h := sha256.New()
+	level := make([][]byte, len(d.leaves))
+	for i, l := range d.leaves {
+		if sum, ok := d.leafHashes[i]; ok {
+			level[i] = sum
+			continue
+		}
+		h.Reset()
+		h.Write(leafHashPrefix)
+		h.Write(l)
+		level[i] = h.Sum(nil)
+	}
+	d.levels = [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			h.Reset()
+			h.Write(nodeHashPrefix)
+			h.Write(level[2*i])
+			h.Write(level[2*i+1])
+			next[i] = h.Sum(nil)
+		}
+		d.levels = append(d.levels, next)
+		level = next
+	}
+	return d.levels
+}
+
+func (d *DefaultTree) VerifyProof(root []byte, proof Proof) bool {
+	return merkletree.VerifyProof(sha256.New(), root, proof.Set, proof.Index, proof.NumLeaves)
+}
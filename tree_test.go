@@ -0,0 +1,228 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/lazyledger/merkletree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTreePushReader(t *testing.T) {
+	leaves := genRandDS(3)
+
+	pushed := NewDefaultTree()
+	streamed := NewDefaultTree().(StreamingTree)
+	for i, l := range leaves {
+		pushed.Push(l, SquareIndex{Cell: uint(i)})
+		if i%2 == 0 {
+			assert.NoError(t, streamed.PushReader(bytes.NewReader(l), SquareIndex{Cell: uint(i)}))
+		} else {
+			streamed.Push(l, SquareIndex{Cell: uint(i)})
+		}
+	}
+	assert.Equal(t, pushed.Root(), streamed.Root())
+
+	proof, err := streamed.(ProvableTree).Prove(1)
+	assert.NoError(t, err)
+	assert.True(t, streamed.(ProvableTree).VerifyProof(pushed.Root(), proof))
+
+	_, err = streamed.(ProvableTree).Prove(2)
+	assert.Error(t, err)
+}
+
+func TestDefaultTreeProve(t *testing.T) {
+	tree := NewDefaultTree().(ProvableTree)
+	leaves := genRandDS(2)
+	for i, l := range leaves {
+		tree.Push(l, SquareIndex{Cell: uint(i)})
+	}
+
+	for i, l := range leaves {
+		proof, err := tree.Prove(uint(i))
+		assert.NoError(t, err)
+		assert.Equal(t, l, proof.Set[0])
+		assert.True(t, tree.VerifyProof(tree.Root(), proof))
+	}
+
+	_, err := tree.Prove(uint(len(leaves)))
+	assert.Error(t, err)
+}
+
+func TestSpilledRootsAreStreamed(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+	rowRoots, colRoots := eds.getRowRoots(), eds.getColRoots()
+
+	if err := eds.enableSpill(0); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, eds.spill.resident[0])
+	for i := uint(0); i < eds.width; i++ {
+		assert.Equal(t, rowRoots[i], eds.computeRowRoot(i))
+		assert.Equal(t, colRoots[i], eds.computeColRoot(i))
+	}
+	// Spilled quadrants were read from disk without being loaded.
+	assert.False(t, eds.spill.resident[0])
+	assert.NoError(t, eds.disableSpill())
+}
+
+func TestDefaultTreeProveMatchesMerkletree(t *testing.T) {
+	for n := 1; n <= 20; n++ {
+		tree := NewDefaultTree().(ProvableTree)
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			leaves[i] = []byte{byte(i)}
+			tree.Push(leaves[i], SquareIndex{Cell: uint(i)})
+		}
+
+		for i := 0; i < n; i++ {
+			want := merkletree.New(sha256.New())
+			if err := want.SetIndex(uint64(i)); err != nil {
+				t.Fatal(err)
+			}
+			for _, l := range leaves {
+				want.Push(l)
+			}
+			_, set, _, _ := want.Prove()
+
+			proof, err := tree.Prove(uint(i))
+			assert.NoError(t, err)
+			assert.Equal(t, set, proof.Set, "n=%d, i=%d", n, i)
+		}
+	}
+}
+
+func TestDefaultTreeFromNodes(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		built := NewDefaultTree().(NodeCachingTree)
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			leaves[i] = []byte{byte(i)}
+			built.Push(leaves[i], SquareIndex{Cell: uint(i)})
+		}
+		nodes := built.Nodes()
+
+		restored := NewDefaultTree().(NodeCachingTree)
+		assert.NoError(t, restored.FromNodes(nodes, leaves))
+		assert.Equal(t, built.Root(), restored.Root(), "n=%d", n)
+		for i := 0; i < n; i++ {
+			want, err := built.Prove(uint(i))
+			assert.NoError(t, err)
+			got, err := restored.Prove(uint(i))
+			assert.NoError(t, err)
+			assert.Equal(t, want, got, "n=%d, i=%d", n, i)
+		}
+
+		hashesOnly := NewDefaultTree().(NodeCachingTree)
+		assert.NoError(t, hashesOnly.FromNodes(nodes, nil))
+		assert.Equal(t, built.Root(), hashesOnly.Root(), "n=%d", n)
+		_, err := hashesOnly.Prove(0)
+		assert.Error(t, err)
+	}
+
+	assert.Error(t, NewDefaultTree().(NodeCachingTree).FromNodes(nil, nil))
+	assert.Error(t, NewDefaultTree().(NodeCachingTree).FromNodes([][][]byte{{{1}, {2}}}, nil))
+}
+
+func TestTreeCaching(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), NewDefaultTree)
+	assert.NoError(t, err)
+	rowRoots, colRoots := eds.RowRoots(), eds.ColRoots()
+
+	eds.SetTreeCaching(true)
+	assert.Equal(t, rowRoots, eds.RowRoots())
+	assert.Equal(t, colRoots, eds.ColRoots())
+	assert.NotNil(t, eds.nodeCache.rows[3])
+	assert.NotNil(t, eds.nodeCache.cols[5])
+
+	for _, axis := range []Axis{RowAxis, ColAxis} {
+		proof, err := eds.ProveCellOnAxis(Coordinate{Row: 2, Col: 5}, axis)
+		assert.NoError(t, err)
+		assert.NoError(t, proof.Verify(rowRoots, colRoots, NewDefaultTree))
+	}
+
+	unchanged := eds.nodeCache.rows[2]
+	assert.NoError(t, eds.UpdateCell(1, 1, bytes.Repeat([]byte{7}, int(eds.ChunkSize()))))
+	assert.Equal(t, unchanged, eds.nodeCache.rows[2])
+	proof, err := eds.ProveCellOnAxis(Coordinate{Row: 1, Col: 6}, RowAxis)
+	assert.NoError(t, err)
+	assert.NoError(t, proof.Verify(eds.RowRoots(), eds.ColRoots(), NewDefaultTree))
+
+	eds.SetTreeCaching(false)
+	assert.Nil(t, eds.nodeCache)
+}
+
+// recordingTree records the leaves pushed into it.
+type recordingTree struct {
+	*DefaultTree
+	indexes []SquareIndex
+	leaves  [][]byte
+}
+
+func (r *recordingTree) Push(data []byte, idx SquareIndex) {
+	r.indexes = append(r.indexes, idx)
+	r.leaves = append(r.leaves, data)
+	r.DefaultTree.Push(data, idx)
+}
+
+func TestCanonicalLeafOrder(t *testing.T) {
+	var mu sync.Mutex
+	var trees []*recordingTree
+	treeFn := func() Tree {
+		tree := &recordingTree{DefaultTree: NewDefaultTree().(*DefaultTree)}
+		mu.Lock()
+		trees = append(trees, tree)
+		mu.Unlock()
+		return tree
+	}
+	eds, err := ComputeExtendedDataSquare(genRandDS(4), NewRSGF8Codec(), treeFn)
+	if err != nil {
+		panic(err)
+	}
+	eds.RowRoots()
+	eds.ColRoots()
+	if _, err := eds.ProveRowCells(3, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eds.ProveColumnCells(6, []uint{2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every tree holds a row or column in canonical order.
+	matched := make(map[Axis]map[uint]bool)
+	for _, tree := range trees {
+		assert.Len(t, tree.indexes, 8)
+		index := tree.indexes[0].Axis
+		for p, idx := range tree.indexes {
+			assert.Equal(t, SquareIndex{Axis: index, Cell: uint(p)}, idx)
+		}
+		for _, axis := range []Axis{RowAxis, ColAxis} {
+			order, err := eds.CanonicalLeafOrder(axis, index)
+			assert.NoError(t, err)
+			equal := true
+			for p, coord := range order {
+				equal = equal && bytes.Equal(eds.getCell(coord.Row, coord.Col), tree.leaves[p])
+			}
+			if equal {
+				if matched[axis] == nil {
+					matched[axis] = make(map[uint]bool)
+				}
+				matched[axis][index] = true
+			}
+		}
+	}
+	assert.Len(t, matched[RowAxis], 8)
+	assert.Len(t, matched[ColAxis], 8)
+
+	order, err := eds.CanonicalLeafOrder(ColAxis, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, Coordinate{Row: 0, Col: 2}, order[0])
+	assert.Equal(t, Coordinate{Row: 7, Col: 2}, order[7])
+	_, err = eds.CanonicalLeafOrder(RowAxis, 8)
+	assert.Error(t, err)
+}
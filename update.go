@@ -0,0 +1,82 @@
+package rsmt2d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UpdateCell replaces a chunk of the original data and updates the parity
+// and roots that depend on it. The row and column of the cell, the parity
+// rows and the parity columns are re-encoded. If the roots were already
+// computed, the roots of the affected axes are recomputed from scratch: the
+// row and column of the cell and all parity rows and parity columns, 2k+2 of
+// the 4k axes of the square. The roots of the other axes are kept.
+func (eds *ExtendedDataSquare) UpdateCell(row uint, col uint, chunk []byte) error {
+	k := eds.originalDataWidth
+	if row >= k || col >= k {
+		return fmt.Errorf("cell (%d, %d) is outside the original data of width %d", row, col, k)
+	}
+	if uint(len(chunk)) != eds.chunkSize {
+		return fmt.Errorf("%w: chunk has size %d, expected %d", ErrInvalidChunkSize, len(chunk), eds.chunkSize)
+	}
+	if eds.codec == nil {
+		return errors.New("square has no codec")
+	}
+
+	eds.waitRoots()
+	var rowRoots, colRoots [][]byte
+	if eds.rowRoots != nil {
+		rowRoots = append([][]byte(nil), eds.rowRoots...)
+		colRoots = append([][]byte(nil), eds.colRoots...)
+	}
+	cache := eds.nodeCache
+
+	encode := func(data [][]byte) ([][]byte, error) {
+		shares, err := eds.codec.Encode(data)
+		if err != nil {
+			return nil, err
+		}
+		return shares[len(shares)-len(data):], nil
+	}
+	eds.setCell(row, col, chunk)
+	parity, err := encode(eds.rowSlice(row, 0, k))
+	if err != nil {
+		return err
+	}
+	if err := eds.setRowSlice(row, k, parity); err != nil {
+		return err
+	}
+	parity, err = encode(eds.colSlice(0, col, k))
+	if err != nil {
+		return err
+	}
+	if err := eds.setColSlice(k, col, parity); err != nil {
+		return err
+	}
+	// The parity rows changed in column col, so the last quadrant changes
+	// entirely.
+	for i := k; i < eds.width; i++ {
+		parity, err := encode(eds.rowSlice(i, 0, k))
+		if err != nil {
+			return err
+		}
+		if err := eds.setRowSlice(i, k, parity); err != nil {
+			return err
+		}
+	}
+
+	if rowRoots != nil {
+		if cache != nil {
+			// Keep the cached nodes of the axes that did not change.
+			eds.nodeCache = cache
+		}
+		rowRoots[row] = eds.computeRowRoot(row)
+		colRoots[col] = eds.computeColRoot(col)
+		for i := k; i < eds.width; i++ {
+			rowRoots[i] = eds.computeRowRoot(i)
+			colRoots[i] = eds.computeColRoot(i)
+		}
+		eds.rowRoots, eds.colRoots = rowRoots, colRoots
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateCell(t *testing.T) {
+	codec := NewRSGF8Codec()
+	data := genRandDS(4)
+	eds, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, withRoots := range []bool{true, false} {
+		if withRoots {
+			eds.RowRoots()
+		}
+		chunk := genRandDS(1)[0]
+		data[1*4+2] = chunk
+		assert.NoError(t, eds.UpdateCell(1, 2, chunk))
+
+		want, err := ComputeExtendedDataSquare(data, codec, NewDefaultTree)
+		if err != nil {
+			panic(err)
+		}
+		assert.Equal(t, want.flattened(), eds.flattened())
+		assert.Equal(t, want.RowRoots(), eds.RowRoots())
+		assert.Equal(t, want.ColRoots(), eds.ColRoots())
+		assert.Equal(t, want.DataRoot(), eds.DataRoot())
+	}
+
+	assert.Error(t, eds.UpdateCell(4, 0, data[0]))
+	assert.Error(t, eds.UpdateCell(0, 0, data[0][1:]))
+}
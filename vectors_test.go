@@ -0,0 +1,124 @@
+package rsmt2d
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//go:generate go test -run TestVectors -update-vectors
+
+var updateVectors = flag.Bool("update-vectors", false, "regenerate the test vectors in testdata/vectors")
+
+// vectorWidths and vectorChunkSize define the squares covered by the test
+// vectors. 64-byte chunks are accepted by every codec.
+var vectorWidths = []int{1, 2, 4, 8}
+
+const vectorChunkSize = 64
+
+// vectorChunks deterministically derives the original chunks of a square:
+// chunk i is the concatenation of SHA-256(width || i || j) for j = 0, 1, ...,
+// with width, i and j encoded as big-endian uint32, truncated to chunkSize.
+func vectorChunks(width int, chunkSize int) [][]byte {
+	chunks := make([][]byte, width*width)
+	for i := range chunks {
+		var chunk []byte
+		for j := 0; len(chunk) < chunkSize; j++ {
+			var seed [12]byte
+			binary.BigEndian.PutUint32(seed[0:], uint32(width))
+			binary.BigEndian.PutUint32(seed[4:], uint32(i))
+			binary.BigEndian.PutUint32(seed[8:], uint32(j))
+			sum := sha256.Sum256(seed[:])
+			chunk = append(chunk, sum[:]...)
+		}
+		chunks[i] = chunk[:chunkSize]
+	}
+	return chunks
+}
+
+func hexAll(data [][]byte) []string {
+	s := make([]string, len(data))
+	for i, d := range data {
+		s[i] = hex.EncodeToString(d)
+	}
+	return s
+}
+
+func generateVectors(codecName string, codec Codec) ([]Fixture, error) {
+	var vectors []Fixture
+	for _, width := range vectorWidths {
+		if !IsValidWidth(width, codec) {
+			continue
+		}
+		original := vectorChunks(width, vectorChunkSize)
+		eds, err := ComputeExtendedDataSquare(original, codec, NewDefaultTree)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, Fixture{
+			Codec:         codecName,
+			Tree:          "DefaultTree",
+			OriginalWidth: width,
+			ChunkSize:     vectorChunkSize,
+			Original:      hexAll(original),
+			Extended:      hexAll(eds.flattened()),
+			RowRoots:      hexAll(eds.RowRoots()),
+			ColRoots:      hexAll(eds.ColRoots()),
+			DataRoot:      hex.EncodeToString(eds.DataRoot()),
+		})
+	}
+	return vectors, nil
+}
+
+// TestVectors checks the committed test vectors of every codec available in
+// this build against the implementation. Run with -update-vectors to
+// regenerate them.
+func TestVectors(t *testing.T) {
+	for codecName, codec := range codecs {
+		path := filepath.Join("testdata", "vectors", codecName+".json")
+		vectors, err := generateVectors(codecName, codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if *updateVectors {
+			data, err := json.MarshalIndent(vectors, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			t.Logf("no test vectors for %s", codecName)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		committed, err := LoadFixtures(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range committed {
+			assert.NoError(t, committed[i].Check(nil), "vector %d of %s", i, codecName)
+		}
+		assert.Equal(t, committed, vectors, "test vectors of %s are out of date", codecName)
+	}
+}
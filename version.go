@@ -0,0 +1,83 @@
+package rsmt2d
+
+import "fmt"
+
+// Versions of the formats squares, roots and proofs are serialized in. Each
+// encoding starts with its version, which is bumped on incompatible changes.
+const (
+	// ODSFormatVersion is the version of the format written by WriteODS.
+	// Version 2 added flags to the header, for share checksums.
+	ODSFormatVersion = 2
+	// RootsFormatVersion is the version of the binary encoding of
+	// SquareRoots.
+	RootsFormatVersion = 1
+)
+
+// Format identifies one of the serialization formats of the package, so that
+// nodes running different versions can negotiate which version to exchange
+// before sending squares, roots or proofs.
+type Format uint8
+
+const (
+	// FormatODS is the format written by WriteODS.
+	FormatODS Format = iota
+	// FormatRoots is the binary encoding of SquareRoots.
+	FormatRoots
+	// FormatProof is the binary and JSON encoding of proofs.
+	FormatProof
+)
+
+// minFormatVersions holds the oldest version of each format that can still
+// be decoded. Versions up to the current one are supported.
+var minFormatVersions = map[Format]uint{
+	FormatODS:   1,
+	FormatRoots: RootsFormatVersion,
+	FormatProof: ProofFormatVersion,
+}
+
+func (f Format) String() string {
+	switch f {
+	case FormatODS:
+		return "ODS"
+	case FormatRoots:
+		return "roots"
+	case FormatProof:
+		return "proof"
+	default:
+		return fmt.Sprintf("Format(%d)", uint8(f))
+	}
+}
+
+// Version returns the version the format is written in, or 0 for an unknown
+// format.
+func (f Format) Version() uint {
+	switch f {
+	case FormatODS:
+		return ODSFormatVersion
+	case FormatRoots:
+		return RootsFormatVersion
+	case FormatProof:
+		return ProofFormatVersion
+	default:
+		return 0
+	}
+}
+
+// IsCompatible reports whether encodings of the format in version v can be
+// decoded.
+func (f Format) IsCompatible(v uint) bool {
+	min, ok := minFormatVersions[f]
+	return ok && v >= min && v <= f.Version()
+}
+
+// Negotiate returns the highest version of the format supported both locally
+// and by a peer supporting the given versions, or false if there is none.
+func (f Format) Negotiate(peerVersions []uint) (uint, bool) {
+	var best uint
+	for _, v := range peerVersions {
+		if f.IsCompatible(v) && v > best {
+			best = v
+		}
+	}
+	return best, best != 0
+}
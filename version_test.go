@@ -0,0 +1,26 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCompatibility(t *testing.T) {
+	for _, f := range []Format{FormatODS, FormatRoots, FormatProof} {
+		assert.True(t, f.IsCompatible(f.Version()), "%v", f)
+		assert.False(t, f.IsCompatible(f.Version()+1), "%v", f)
+		assert.False(t, f.IsCompatible(0), "%v", f)
+
+		v, ok := f.Negotiate([]uint{0, f.Version(), f.Version() + 1})
+		assert.True(t, ok)
+		assert.Equal(t, f.Version(), v)
+		_, ok = f.Negotiate([]uint{f.Version() + 1})
+		assert.False(t, ok)
+	}
+
+	unknown := Format(9)
+	assert.False(t, unknown.IsCompatible(1))
+	assert.Equal(t, "Format(9)", unknown.String())
+	assert.Equal(t, "roots", FormatRoots.String())
+}
@@ -0,0 +1,96 @@
+package rsmt2d
+
+import "fmt"
+
+// withholdingMinSamples is the number of samples an axis needs before its
+// failure rate is considered meaningful.
+const withholdingMinSamples = 3
+
+// Sample records the outcome of requesting a single share from the network.
+type Sample struct {
+	Coord     Coordinate
+	Available bool
+}
+
+// AxisAvailability summarises the availability of a single row or column.
+type AxisAvailability struct {
+	Index     uint
+	Present   int // Cells currently held
+	Requested int // Samples that hit the axis
+	Failed    int // Samples that hit the axis and were unavailable
+}
+
+// WithholdingReport describes whether the available shares and sampling
+// history of a square are consistent with selective withholding.
+type WithholdingReport struct {
+	// Suspicious is set if any axis was flagged or the erasure pattern is
+	// characteristic of withholding.
+	Suspicious bool
+	// Pattern classifies the erasures of the current shares.
+	Pattern ErasurePattern
+	// Rows and Cols list the axes whose samples failed systematically.
+	Rows []AxisAvailability
+	Cols []AxisAvailability
+}
+
+// DetectWithholding analyses the shares currently held (flattened, missing
+// shares nil) together with the history of sampling requests. An axis is
+// flagged when at least withholdingMinSamples samples hit it and more than
+// half of them failed.
+func DetectWithholding(data [][]byte, history []Sample) (*WithholdingReport, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptySquare
+	}
+	width, err := squareWidth(len(data))
+	if err != nil {
+		return nil, err
+	}
+	if width%2 != 0 {
+		return nil, fmt.Errorf("extended square width must be even, got %d", width)
+	}
+
+	bitMask := newBitMatrix(width)
+	for i, d := range data {
+		if d != nil {
+			bitMask.SetFlat(i)
+		}
+	}
+
+	rows := make([]AxisAvailability, width)
+	cols := make([]AxisAvailability, width)
+	for i := 0; i < width; i++ {
+		rows[i] = AxisAvailability{Index: uint(i), Present: bitMask.NumOnesInRow(i)}
+		cols[i] = AxisAvailability{Index: uint(i), Present: bitMask.NumOnesInCol(i)}
+	}
+	for _, s := range history {
+		if s.Coord.Row >= uint(width) || s.Coord.Col >= uint(width) {
+			return nil, fmt.Errorf("sample coordinate (%d, %d) out of range for width %d", s.Coord.Row, s.Coord.Col, width)
+		}
+		rows[s.Coord.Row].Requested++
+		cols[s.Coord.Col].Requested++
+		if !s.Available {
+			rows[s.Coord.Row].Failed++
+			cols[s.Coord.Col].Failed++
+		}
+	}
+
+	report := &WithholdingReport{
+		Pattern: classifyErasures(bitMask, width/2).Pattern,
+		Rows:    flaggedAxes(rows),
+		Cols:    flaggedAxes(cols),
+	}
+	report.Suspicious = len(report.Rows) > 0 || len(report.Cols) > 0 ||
+		report.Pattern == PatternWithholding || report.Pattern == PatternQuadrantMissing
+	return report, nil
+}
+
+// flaggedAxes returns the axes whose samples failed systematically.
+func flaggedAxes(axes []AxisAvailability) []AxisAvailability {
+	var flagged []AxisAvailability
+	for _, a := range axes {
+		if a.Requested >= withholdingMinSamples && 2*a.Failed > a.Requested {
+			flagged = append(flagged, a)
+		}
+	}
+	return flagged
+}
@@ -0,0 +1,51 @@
+package rsmt2d
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectWithholding(t *testing.T) {
+	eds, err := ComputeExtendedDataSquare(genRandDS(2), NewRSGF8Codec(), NewDefaultTree)
+	if err != nil {
+		panic(err)
+	}
+
+	report, err := DetectWithholding(eds.flattened(), nil)
+	assert.NoError(t, err)
+	assert.False(t, report.Suspicious)
+
+	// Row 2 is systematically unavailable while other samples succeed.
+	flattened := eds.flattened()
+	var history []Sample
+	for c := uint(0); c < 4; c++ {
+		flattened[8+c] = nil
+		history = append(history, Sample{Coord: Coordinate{Row: 2, Col: c}})
+		history = append(history, Sample{Coord: Coordinate{Row: 0, Col: c}, Available: true})
+	}
+	report, err = DetectWithholding(flattened, history)
+	assert.NoError(t, err)
+	assert.True(t, report.Suspicious)
+	if assert.Len(t, report.Rows, 1) {
+		assert.Equal(t, AxisAvailability{Index: 2, Present: 0, Requested: 4, Failed: 4}, report.Rows[0])
+	}
+	assert.Empty(t, report.Cols)
+
+	// Withholding a (k+1)x(k+1) block is flagged from the presence mask alone.
+	flattened = eds.flattened()
+	for _, i := range []int{0, 1, 2, 4, 5, 6, 8, 9, 10} {
+		flattened[i] = nil
+	}
+	report, err = DetectWithholding(flattened, nil)
+	assert.NoError(t, err)
+	assert.True(t, report.Suspicious)
+
+	_, err = DetectWithholding(flattened[:15], nil)
+	var invalidLen *ErrInvalidSquareLength
+	assert.ErrorAs(t, err, &invalidLen)
+	_, err = DetectWithholding(flattened[:9], nil)
+	assert.Error(t, err)
+	_, err = DetectWithholding(nil, nil)
+	assert.ErrorIs(t, err, ErrEmptySquare)
+}